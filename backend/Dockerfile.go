@@ -15,8 +15,9 @@ RUN go mod download
 COPY cmd/ ./cmd/
 COPY internal/ ./internal/
 
-# Build the Go binary
-RUN CGO_ENABLED=0 GOOS=linux go build -o server ./cmd/server
+# Build the Go binaries: API server and remote Python worker
+RUN CGO_ENABLED=0 GOOS=linux go build -o server ./cmd/server && \
+    CGO_ENABLED=0 GOOS=linux go build -o worker ./cmd/worker
 
 # Stage 2: Runtime with Python for ML CLI
 FROM python:3.12-slim-bookworm
@@ -38,6 +39,7 @@ ENV ORT_LOGGING_LEVEL=ERROR
 
 # Copy Go binary from builder
 COPY --from=builder /build/server /app/server
+COPY --from=builder /build/worker /app/worker
 
 # Copy Python project files
 COPY pyproject.toml uv.lock ./
@@ -52,8 +54,8 @@ RUN uv pip install --system -r backend/requirements.txt
 # Create required directories
 RUN mkdir -p outputs logs
 
-# Expose the API port
-EXPOSE 8000
+# Expose the API port (and the worker port when run as /app/worker)
+EXPOSE 8000 8001
 
 # Run the Go server
 CMD ["/app/server"]
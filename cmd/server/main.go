@@ -71,6 +71,11 @@ func main() {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
 
+	// Stop Python workers
+	if err := server.Close(); err != nil {
+		log.Printf("Error stopping Python workers: %v", err)
+	}
+
 	// Close Redis connection if available
 	if redis != nil {
 		if err := redis.Close(); err != nil {
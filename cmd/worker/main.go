@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
+)
+
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	log.Println("Starting Stock Agent Ops Python worker...")
+
+	// Load configuration; without a shared token anyone who can reach the
+	// port could run jobs
+	cfg := config.Load()
+	if cfg.WorkerToken == "" {
+		log.Fatal("WORKER_TOKEN must be set to the token the API sends")
+	}
+
+	// Run jobs locally: warm worker pool, or one process per call
+	var runner python.RunnerInterface
+	if cfg.PythonPool {
+		pool := python.NewPool(cfg)
+		pool.Start()
+		defer pool.Close()
+		runner = pool
+	} else {
+		runner = python.NewRunner(cfg)
+	}
+
+	// No write timeout: training responses stream for as long as the job runs
+	addr := fmt.Sprintf(":%s", cfg.WorkerPort)
+	httpServer := &http.Server{
+		Addr:        addr,
+		Handler:     python.NewWorkerServer(cfg, runner),
+		ReadTimeout: 15 * time.Second,
+		IdleTimeout: 60 * time.Second,
+	}
+
+	// Start server
+	go func() {
+		log.Printf("Worker listening on %s", addr)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start worker: %v", err)
+		}
+	}()
+
+	// Wait for interrupt signal
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Shutting down worker...")
+
+	// Graceful shutdown with timeout; in-flight jobs are cancelled after it
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.GracefulTimeout)*time.Second)
+	defer cancel()
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		log.Printf("Worker forced to shutdown: %v", err)
+		httpServer.Close()
+	}
+
+	log.Println("Worker stopped")
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config holds all configuration for the application
@@ -19,29 +20,109 @@ type Config struct {
 	// Python
 	PythonPath string
 	ScriptPath string
+	PythonPool bool // keep warm `ml_cli.py serve` workers instead of one process per call
+
+	// Execution: "local" runs ml_cli.py here, "remote" sends jobs to
+	// cmd/worker, "synthetic" fakes results without Python
+	ExecutionMode   string
+	WorkerEndpoints []string
+	WorkerPort      string
+	WorkerToken     string // shared secret the API presents to cmd/worker
+
+	// Synthetic runner
+	SyntheticLatencyMs   int
+	SyntheticFailureRate float64
 
 	// Paths
-	OutputsDir     string
-	LogsDir        string
-	ParentDir      string
-	ParentTicker   string
+	OutputsDir      string
+	LogsDir         string
+	ParentDir       string
+	ParentTicker    string
 	FeatureStoreDir string
 
 	// External Services
-	MLflowURI      string
-	QdrantHost     string
-	QdrantPort     string
-	LlamaCppURL    string
+	MLflowURI        string
+	QdrantHost       string
+	QdrantPort       string
+	LlamaCppURL      string
 	LlamaCppEmbedURL string
-	FinnhubKey     string
-	LLMModel       string
+	FinnhubKey       string
+	LLMModel         string
 
 	// Timeouts (seconds)
-	PythonTimeout  int
+	PythonTimeout   int
 	TrainingTimeout int
+	KillGrace       int // SIGTERM to SIGKILL on timeout or cancel
+
+	// Per-subcommand execution policy overrides (JSON), see python.LoadPolicies
+	PythonPolicies string
+
+	// Resource limits for Python processes (0 = unlimited)
+	PythonMemoryLimitMB     int
+	PythonCPULimitSeconds   int
+	PythonNice              int
+	TrainingMemoryLimitMB   int
+	TrainingCPULimitSeconds int
+	TrainingNice            int
 
 	// Workers
 	MaxWorkers int
+
+	// Execution archive: number of Python runs kept under LogsDir/executions
+	ExecutionLogMax int
+
+	// Training run history retention in Redis
+	TaskHistoryDays int
+
+	// Orphaned task reconciliation: InstanceID owns the tasks this process
+	// runs; OrphanAction is "leave" or "requeue"
+	InstanceID        string
+	OrphanAction      string
+	ReconcileInterval int
+
+	// Lease on a running task in Redis, renewed every third of its TTL
+	TaskLockTTL int
+
+	// Task statuses without Redis: kept as files under TaskStateDir so they
+	// survive a restart, or in memory when it is empty
+	TaskStateDir string
+
+	// Failed training: attempts before a task is dead-lettered, and the
+	// wait (seconds) before the second attempt, doubled for each further one
+	TaskMaxAttempts  int
+	TaskRetryBackoff int
+
+	// Run recurring schedules on this instance
+	SchedulerEnabled bool
+
+	// In-process prediction cache in front of Redis: entries kept and how
+	// long (seconds) each is served before Redis is read again; 0 disables it
+	CacheLocalMaxEntries int
+	CacheLocalTTL        int
+
+	// How long (seconds) predictions are kept past their expiry, to be served
+	// stale while refreshed in the background (0 disables it), and per-endpoint
+	// windows within that (JSON seconds), see cache.LoadStaleWindows
+	CacheStaleGrace     int
+	CacheStaleEndpoints string
+
+	// Cache warm-up: tickers to warm (empty means every ticker with a child
+	// model on disk), predictions made at once, and whether to warm at
+	// startup and after each completed child training run
+	CacheWarmTickers     []string
+	CacheWarmConcurrency int
+	CacheWarmOnStartup   bool
+	CacheWarmOnTrain     bool
+
+	// Lease (seconds) an instance takes on a ticker while predicting it;
+	// other instances wait up to this long for its result
+	PredictLockTTL int
+
+	// Webhook deliveries: attempts per event, the wait (seconds) before the
+	// second attempt, doubled for each further one, and the request timeout
+	WebhookMaxAttempts  int
+	WebhookRetryBackoff int
+	WebhookTimeout      int
 }
 
 // Load reads configuration from environment variables with defaults
@@ -59,6 +140,17 @@ func Load() *Config {
 		// Python
 		PythonPath: getEnv("PYTHON_PATH", "python"),
 		ScriptPath: getEnv("SCRIPT_PATH", "scripts/ml_cli.py"),
+		PythonPool: getEnvBool("PYTHON_POOL", true),
+
+		// Execution
+		ExecutionMode:   getEnv("EXECUTION_MODE", "local"),
+		WorkerEndpoints: getEnvList("WORKER_ENDPOINTS"),
+		WorkerPort:      getEnv("WORKER_PORT", "8001"),
+		WorkerToken:     getEnv("WORKER_TOKEN", ""),
+
+		// Synthetic runner
+		SyntheticLatencyMs:   getEnvInt("SYNTHETIC_LATENCY_MS", 200),
+		SyntheticFailureRate: getEnvFloat("SYNTHETIC_FAILURE_RATE", 0),
 
 		// Paths
 		OutputsDir:      getEnv("OUTPUTS_DIR", "outputs"),
@@ -79,12 +171,80 @@ func Load() *Config {
 		// Timeouts
 		PythonTimeout:   getEnvInt("PYTHON_TIMEOUT", 120),
 		TrainingTimeout: getEnvInt("TRAINING_TIMEOUT", 7200),
+		KillGrace:       getEnvInt("KILL_GRACE_SECONDS", 10),
+
+		// Execution policies
+		PythonPolicies: getEnv("PYTHON_POLICIES", ""),
+
+		// Resource limits
+		PythonMemoryLimitMB:     getEnvInt("PYTHON_MEMORY_LIMIT_MB", 0),
+		PythonCPULimitSeconds:   getEnvInt("PYTHON_CPU_LIMIT_SECONDS", 0),
+		PythonNice:              getEnvInt("PYTHON_NICE", 0),
+		TrainingMemoryLimitMB:   getEnvInt("TRAINING_MEMORY_LIMIT_MB", 0),
+		TrainingCPULimitSeconds: getEnvInt("TRAINING_CPU_LIMIT_SECONDS", 0),
+		TrainingNice:            getEnvInt("TRAINING_NICE", 10),
 
 		// Workers
 		MaxWorkers: getEnvInt("MAX_WORKERS", 4),
+
+		// Execution archive
+		ExecutionLogMax: getEnvInt("EXECUTION_LOG_MAX", 500),
+
+		// Training run history
+		TaskHistoryDays: getEnvInt("TASK_HISTORY_DAYS", 30),
+
+		// Orphaned task reconciliation
+		InstanceID:        getEnv("INSTANCE_ID", hostname()),
+		OrphanAction:      getEnv("ORPHAN_ACTION", "leave"),
+		ReconcileInterval: getEnvInt("RECONCILE_INTERVAL_SECONDS", 60),
+
+		// Task leases
+		TaskLockTTL: getEnvInt("TASK_LOCK_TTL_SECONDS", 30),
+
+		// Task state without Redis
+		TaskStateDir: getEnv("TASK_STATE_DIR", ""),
+
+		// Task retries
+		TaskMaxAttempts:  getEnvInt("TASK_MAX_ATTEMPTS", 3),
+		TaskRetryBackoff: getEnvInt("TASK_RETRY_BACKOFF_SECONDS", 60),
+
+		// Scheduler
+		SchedulerEnabled: getEnvBool("SCHEDULER_ENABLED", true),
+
+		// In-process prediction cache
+		CacheLocalMaxEntries: getEnvInt("CACHE_LOCAL_MAX_ENTRIES", 1000),
+		CacheLocalTTL:        getEnvInt("CACHE_LOCAL_TTL_SECONDS", 30),
+
+		// Stale-while-revalidate
+		CacheStaleGrace:     getEnvInt("CACHE_STALE_GRACE_SECONDS", 3600),
+		CacheStaleEndpoints: getEnv("CACHE_STALE_ENDPOINTS", ""),
+
+		// Cache warm-up
+		CacheWarmTickers:     getEnvList("CACHE_WARM_TICKERS"),
+		CacheWarmConcurrency: getEnvInt("CACHE_WARM_CONCURRENCY", 2),
+		CacheWarmOnStartup:   getEnvBool("CACHE_WARM_ON_STARTUP", true),
+		CacheWarmOnTrain:     getEnvBool("CACHE_WARM_ON_TRAIN", true),
+
+		// Prediction coalescing across instances
+		PredictLockTTL: getEnvInt("PREDICT_LOCK_TTL_SECONDS", 30),
+
+		// Webhooks
+		WebhookMaxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookRetryBackoff: getEnvInt("WEBHOOK_RETRY_BACKOFF_SECONDS", 5),
+		WebhookTimeout:      getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10),
 	}
 }
 
+// hostname identifies this instance by default; in Kubernetes it is the
+// pod name, which survives container restarts
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "local"
+	}
+	return name
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -100,3 +260,31 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	}
+	return defaultValue
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
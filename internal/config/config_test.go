@@ -10,10 +10,19 @@ func TestLoadDefaults(t *testing.T) {
 	envKeys := []string{
 		"PORT", "GRACEFUL_TIMEOUT",
 		"REDIS_HOST", "REDIS_PORT", "REDIS_DB",
-		"PYTHON_PATH", "SCRIPT_PATH",
+		"PYTHON_PATH", "SCRIPT_PATH", "PYTHON_POOL",
+		"EXECUTION_MODE", "WORKER_ENDPOINTS", "WORKER_PORT", "WORKER_TOKEN",
+		"SYNTHETIC_LATENCY_MS", "SYNTHETIC_FAILURE_RATE", "KILL_GRACE_SECONDS", "PYTHON_POLICIES",
+		"PYTHON_MEMORY_LIMIT_MB", "PYTHON_CPU_LIMIT_SECONDS", "PYTHON_NICE",
+		"TRAINING_MEMORY_LIMIT_MB", "TRAINING_CPU_LIMIT_SECONDS", "TRAINING_NICE",
 		"OUTPUTS_DIR", "LOGS_DIR", "PARENT_DIR", "PARENT_TICKER",
-		"PYTHON_TIMEOUT", "TRAINING_TIMEOUT", "MAX_WORKERS",
-		"LLM_MODEL",
+		"PYTHON_TIMEOUT", "TRAINING_TIMEOUT", "MAX_WORKERS", "EXECUTION_LOG_MAX",
+		"TASK_HISTORY_DAYS", "ORPHAN_ACTION", "RECONCILE_INTERVAL_SECONDS",
+		"TASK_LOCK_TTL_SECONDS", "TASK_STATE_DIR", "TASK_MAX_ATTEMPTS", "TASK_RETRY_BACKOFF_SECONDS",
+		"SCHEDULER_ENABLED", "LLM_MODEL", "CACHE_LOCAL_MAX_ENTRIES", "CACHE_LOCAL_TTL_SECONDS",
+		"PREDICT_LOCK_TTL_SECONDS", "CACHE_STALE_GRACE_SECONDS", "CACHE_STALE_ENDPOINTS",
+		"CACHE_WARM_TICKERS", "CACHE_WARM_CONCURRENCY", "CACHE_WARM_ON_STARTUP", "CACHE_WARM_ON_TRAIN",
+		"WEBHOOK_MAX_ATTEMPTS", "WEBHOOK_RETRY_BACKOFF_SECONDS", "WEBHOOK_TIMEOUT_SECONDS",
 	}
 	for _, k := range envKeys {
 		os.Unsetenv(k)
@@ -33,13 +42,45 @@ func TestLoadDefaults(t *testing.T) {
 		{"RedisDB", cfg.RedisDB, 0},
 		{"PythonPath", cfg.PythonPath, "python"},
 		{"ScriptPath", cfg.ScriptPath, "scripts/ml_cli.py"},
+		{"PythonPool", cfg.PythonPool, true},
+		{"ExecutionMode", cfg.ExecutionMode, "local"},
+		{"WorkerEndpoints", len(cfg.WorkerEndpoints), 0},
+		{"WorkerPort", cfg.WorkerPort, "8001"},
+		{"WorkerToken", cfg.WorkerToken, ""},
+		{"SyntheticLatencyMs", cfg.SyntheticLatencyMs, 200},
+		{"SyntheticFailureRate", cfg.SyntheticFailureRate, 0.0},
 		{"OutputsDir", cfg.OutputsDir, "outputs"},
 		{"LogsDir", cfg.LogsDir, "logs"},
 		{"ParentDir", cfg.ParentDir, "outputs/parent"},
 		{"ParentTicker", cfg.ParentTicker, "^GSPC"},
 		{"PythonTimeout", cfg.PythonTimeout, 120},
 		{"TrainingTimeout", cfg.TrainingTimeout, 7200},
+		{"KillGrace", cfg.KillGrace, 10},
+		{"PythonPolicies", cfg.PythonPolicies, ""},
+		{"PythonMemoryLimitMB", cfg.PythonMemoryLimitMB, 0},
+		{"TrainingNice", cfg.TrainingNice, 10},
 		{"MaxWorkers", cfg.MaxWorkers, 4},
+		{"ExecutionLogMax", cfg.ExecutionLogMax, 500},
+		{"TaskHistoryDays", cfg.TaskHistoryDays, 30},
+		{"OrphanAction", cfg.OrphanAction, "leave"},
+		{"ReconcileInterval", cfg.ReconcileInterval, 60},
+		{"TaskLockTTL", cfg.TaskLockTTL, 30},
+		{"TaskStateDir", cfg.TaskStateDir, ""},
+		{"TaskMaxAttempts", cfg.TaskMaxAttempts, 3},
+		{"TaskRetryBackoff", cfg.TaskRetryBackoff, 60},
+		{"SchedulerEnabled", cfg.SchedulerEnabled, true},
+		{"CacheLocalMaxEntries", cfg.CacheLocalMaxEntries, 1000},
+		{"CacheLocalTTL", cfg.CacheLocalTTL, 30},
+		{"CacheStaleGrace", cfg.CacheStaleGrace, 3600},
+		{"CacheStaleEndpoints", cfg.CacheStaleEndpoints, ""},
+		{"CacheWarmTickers", len(cfg.CacheWarmTickers), 0},
+		{"CacheWarmConcurrency", cfg.CacheWarmConcurrency, 2},
+		{"CacheWarmOnStartup", cfg.CacheWarmOnStartup, true},
+		{"CacheWarmOnTrain", cfg.CacheWarmOnTrain, true},
+		{"PredictLockTTL", cfg.PredictLockTTL, 30},
+		{"WebhookMaxAttempts", cfg.WebhookMaxAttempts, 5},
+		{"WebhookRetryBackoff", cfg.WebhookRetryBackoff, 5},
+		{"WebhookTimeout", cfg.WebhookTimeout, 10},
 		{"LLMModel", cfg.LLMModel, "qwen3-7b"},
 	}
 
@@ -86,3 +127,18 @@ func TestGetEnvIntInvalidFallsBack(t *testing.T) {
 		t.Errorf("MaxWorkers with invalid env = %d, want default 4", cfg.MaxWorkers)
 	}
 }
+
+func TestGetEnvListTrimsAndSkipsEmpty(t *testing.T) {
+	t.Setenv("WORKER_ENDPOINTS", " http://w1:8001, ,http://w2:8001 ")
+
+	cfg := Load()
+	want := []string{"http://w1:8001", "http://w2:8001"}
+	if len(cfg.WorkerEndpoints) != len(want) {
+		t.Fatalf("WorkerEndpoints = %v, want %v", cfg.WorkerEndpoints, want)
+	}
+	for i := range want {
+		if cfg.WorkerEndpoints[i] != want[i] {
+			t.Errorf("WorkerEndpoints[%d] = %q, want %q", i, cfg.WorkerEndpoints[i], want[i])
+		}
+	}
+}
@@ -47,11 +47,7 @@ func (h *AnalyzeHandler) Analyze(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.runner.Analyze(r.Context(), ticker, req.ThreadID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"detail": "Analysis failed: " + err.Error(),
-		})
+		respondRunnerError(w, "Analysis failed: ", err)
 		return
 	}
 
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
 )
 
 // respondJSON writes a JSON response with the given status code
@@ -23,3 +26,32 @@ func respondError(w http.ResponseWriter, status int, message string) {
 func decodeJSON(r *http.Request, v interface{}) error {
 	return json.NewDecoder(r.Body).Decode(v)
 }
+
+// statusForError maps a Python runner error to an HTTP status code
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, python.ErrModelMissing):
+		return http.StatusNotFound
+	case errors.Is(err, python.ErrInvalidTicker):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, python.ErrDataUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, python.ErrTimeout):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondRunnerError writes a Python runner error with its mapped status
+// code and, for typed errors, the machine-readable error code
+func respondRunnerError(w http.ResponseWriter, prefix string, err error) {
+	body := map[string]string{
+		"detail": prefix + err.Error(),
+	}
+	var cliErr *python.Error
+	if errors.As(err, &cliErr) {
+		body["code"] = cliErr.Code
+	}
+	respondJSON(w, statusForError(err), body)
+}
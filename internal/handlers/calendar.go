@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/calendar"
+)
+
+// Day range for GET /calendar
+const (
+	defaultCalendarDays = 14
+	maxCalendarDays     = 366
+)
+
+// CalendarHandler handles the trading calendar endpoint
+type CalendarHandler struct {
+	calendar *calendar.Calendar
+	now      func() time.Time
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(cal *calendar.Calendar) *CalendarHandler {
+	return &CalendarHandler{calendar: cal, now: time.Now}
+}
+
+// Get handles GET /calendar
+func (h *CalendarHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if h.calendar == nil {
+		respondError(w, http.StatusServiceUnavailable, "Trading calendar not loaded")
+		return
+	}
+
+	now := h.now().In(h.calendar.Location())
+	from := now
+	if value := r.URL.Query().Get("from"); value != "" {
+		day, err := time.ParseInLocation("2006-01-02", value, h.calendar.Location())
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "from: want a date as YYYY-MM-DD")
+			return
+		}
+		from = day
+	}
+	days, err := parseIntParam(r.URL.Query().Get("days"), defaultCalendarDays, 1, maxCalendarDays)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "days: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"exchange":   h.calendar.Exchange(),
+		"timezone":   h.calendar.Location().String(),
+		"now":        now,
+		"is_open":    h.calendar.IsOpen(now),
+		"next_open":  h.calendar.NextOpen(now),
+		"next_close": h.calendar.NextClose(now),
+		"sessions":   h.calendar.Sessions(from, days),
+		"holidays":   h.calendar.Holidays(from, days),
+		"known_from": h.calendar.FirstDay(),
+		"known_to":   h.calendar.LastDay(),
+	})
+}
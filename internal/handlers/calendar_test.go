@@ -0,0 +1,49 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/handlers"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/calendar"
+)
+
+func TestCalendar_Get(t *testing.T) {
+	cal, err := calendar.NYSE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := handlers.NewCalendarHandler(cal)
+
+	rec := httptest.NewRecorder()
+	h.Get(rec, httptest.NewRequest(http.MethodGet, "/calendar?from=2026-12-21&days=14", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Get status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Exchange string             `json:"exchange"`
+		Sessions []calendar.Session `json:"sessions"`
+		Holidays []calendar.Holiday `json:"holidays"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	// Two weeks over Christmas and New Year: 10 weekdays less two holidays
+	if resp.Exchange != "NYSE" || len(resp.Sessions) != 8 || len(resp.Holidays) != 2 {
+		t.Errorf("Get = %s, want 8 NYSE sessions and 2 holidays", rec.Body.String())
+	}
+
+	for _, query := range []string{"from=12/21/2026", "days=0", "days=1000"} {
+		rec = httptest.NewRecorder()
+		h.Get(rec, httptest.NewRequest(http.MethodGet, "/calendar?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Get(%s) status = %d, want 400", query, rec.Code)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handlers.NewCalendarHandler(nil).Get(rec, httptest.NewRequest(http.MethodGet, "/calendar", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Get(no calendar) status = %d, want 503", rec.Code)
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
+)
+
+// ExecutionHandler serves archived Python execution logs
+type ExecutionHandler struct {
+	archive     python.ExecutionStore
+	taskManager tasks.ManagerInterface
+}
+
+// NewExecutionHandler creates a new execution handler
+func NewExecutionHandler(archive python.ExecutionStore, taskManager tasks.ManagerInterface) *ExecutionHandler {
+	return &ExecutionHandler{
+		archive:     archive,
+		taskManager: taskManager,
+	}
+}
+
+// GetExecution handles GET /executions/{id}
+func (h *ExecutionHandler) GetExecution(w http.ResponseWriter, r *http.Request) {
+	h.respondExecution(w, chi.URLParam(r, "id"))
+}
+
+// GetTaskLogs handles GET /status/{task_id}/logs
+func (h *ExecutionHandler) GetTaskLogs(w http.ResponseWriter, r *http.Request) {
+	taskID := strings.ToLower(chi.URLParam(r, "task_id"))
+	if taskID == "parent" {
+		taskID = "parent_training"
+	}
+
+	var status *tasks.TaskStatus
+	if h.taskManager != nil {
+		status = h.taskManager.GetStatus(taskID)
+	}
+	if status == nil {
+		respondError(w, http.StatusNotFound, "Task '"+taskID+"' not found.")
+		return
+	}
+	if status.ExecutionID == "" {
+		respondError(w, http.StatusNotFound, "No logs recorded for task '"+taskID+"' (status: "+status.Status+").")
+		return
+	}
+
+	h.respondExecution(w, status.ExecutionID)
+}
+
+// respondExecution writes an archived execution or the matching error
+func (h *ExecutionHandler) respondExecution(w http.ResponseWriter, id string) {
+	execution, err := h.archive.Get(id)
+	if errors.Is(err, python.ErrExecutionNotFound) {
+		respondError(w, http.StatusNotFound, "Execution '"+id+"' not found.")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to read execution: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, execution)
+}
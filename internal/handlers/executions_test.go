@@ -0,0 +1,92 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/handlers"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
+)
+
+// archiveWith returns an archive in a temp dir holding one execution
+func archiveWith(t *testing.T, e *python.Execution) *python.Archive {
+	t.Helper()
+
+	cfg := config.Load()
+	cfg.LogsDir = t.TempDir()
+	archive := python.NewArchive(cfg)
+	if err := archive.Save(e); err != nil {
+		t.Fatalf("archive.Save err = %v", err)
+	}
+	return archive
+}
+
+func TestGetExecution_Found(t *testing.T) {
+	archive := archiveWith(t, &python.Execution{ID: "20260101T000000-abcd", ExitCode: 1, Stderr: "Traceback"})
+	h := handlers.NewExecutionHandler(archive, newMockManager())
+
+	req := chiRequest(http.MethodGet, "/executions/20260101T000000-abcd", map[string]string{"id": "20260101T000000-abcd"})
+	rec := httptest.NewRecorder()
+	h.GetExecution(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GetExecution status = %d, want 200", rec.Code)
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp["stderr"] != "Traceback" || resp["exit_code"] != float64(1) {
+		t.Errorf("GetExecution body = %v, want stderr and exit_code", resp)
+	}
+}
+
+func TestGetExecution_NotFound(t *testing.T) {
+	archive := archiveWith(t, &python.Execution{ID: "20260101T000000-abcd"})
+	h := handlers.NewExecutionHandler(archive, newMockManager())
+
+	req := chiRequest(http.MethodGet, "/executions/nope", map[string]string{"id": "nope"})
+	rec := httptest.NewRecorder()
+	h.GetExecution(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("GetExecution(unknown) status = %d, want 404", rec.Code)
+	}
+}
+
+func TestGetTaskLogs_UsesTaskExecution(t *testing.T) {
+	archive := archiveWith(t, &python.Execution{ID: "20260101T000000-abcd", Stdout: "{}"})
+	mm := newMockManager()
+	mm.statuses["parent_training"] = &tasks.TaskStatus{Status: "failed", ExecutionID: "20260101T000000-abcd"}
+	h := handlers.NewExecutionHandler(archive, mm)
+
+	req := chiRequest(http.MethodGet, "/status/parent/logs", map[string]string{"task_id": "parent"})
+	rec := httptest.NewRecorder()
+	h.GetTaskLogs(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GetTaskLogs status = %d, want 200", rec.Code)
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp["id"] != "20260101T000000-abcd" {
+		t.Errorf("GetTaskLogs id = %v, want the task's execution", resp["id"])
+	}
+}
+
+func TestGetTaskLogs_RunningTaskHasNoLogs(t *testing.T) {
+	archive := archiveWith(t, &python.Execution{ID: "20260101T000000-abcd"})
+	mm := newMockManager()
+	mm.statuses["aapl"] = &tasks.TaskStatus{Status: "running"}
+	h := handlers.NewExecutionHandler(archive, mm)
+
+	req := chiRequest(http.MethodGet, "/status/aapl/logs", map[string]string{"task_id": "aapl"})
+	rec := httptest.NewRecorder()
+	h.GetTaskLogs(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("GetTaskLogs(running) status = %d, want 404", rec.Code)
+	}
+}
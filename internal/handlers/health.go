@@ -48,6 +48,8 @@ func (h *HealthHandler) Root(w http.ResponseWriter, r *http.Request) {
 			"training": map[string]string{
 				"train_parent": "POST /train-parent - Train parent model (S&P 500)",
 				"train_child":  "POST /train-child - Train child model for specific ticker",
+				"train_batch":  "POST /train-child/batch - Train child models for a list of tickers",
+				"batch_status": "GET /train-child/batch/{batch_id} - Per-ticker state of a training batch",
 			},
 			"prediction": map[string]string{
 				"predict_parent": "POST /predict-parent - Predict using parent model",
@@ -55,17 +57,47 @@ func (h *HealthHandler) Root(w http.ResponseWriter, r *http.Request) {
 			},
 			"monitoring": map[string]string{
 				"status":         "GET /status/{task_id} - Check training task status",
+				"cancel_task":    "POST /tasks/{task_id}/cancel - Cancel a running or queued training task",
+				"task_runs":      "GET /tasks - Training run history (ticker, status, since, until, limit, offset)",
+				"task_run":       "GET /tasks/{run_id} - Get one training run",
+				"resume_task":    "POST /tasks/{run_id}/resume - Re-run a failed run from the step that did not complete",
+				"dead_letters":   "GET /tasks/dead-letter - Tasks that failed on every attempt, with their errors",
+				"retry_dead":     "POST /tasks/dead-letter/{task_id}/retry - Re-run a dead-lettered task",
+				"delete_dead":    "DELETE /tasks/dead-letter/{task_id} - Discard a dead-lettered task",
+				"task_logs":      "GET /status/{task_id}/logs - Get stdout/stderr of a task's Python run",
+				"execution":      "GET /executions/{id} - Get an archived Python execution",
 				"monitor_parent": "POST /monitor/parent - Monitor parent model drift & agent eval",
 				"monitor_ticker": "POST /monitor/{ticker} - Monitor specific ticker",
 				"drift_report":   "GET /monitor/{ticker}/drift - Get drift analysis JSON",
 				"eval_report":    "GET /monitor/{ticker}/eval - Get agent evaluation JSON",
 			},
+			"schedules": map[string]string{
+				"list":   "GET /schedules - List recurring training and monitoring schedules",
+				"create": "POST /schedules - Create a schedule (action, tickers, cron, timezone)",
+				"get":    "GET /schedules/{id} - Get a schedule with its next and last run",
+				"update": "PUT /schedules/{id} - Replace a schedule's definition",
+				"delete": "DELETE /schedules/{id} - Delete a schedule",
+			},
+			"webhooks": map[string]string{
+				"list":       "GET /webhooks - List webhook subscriptions",
+				"create":     "POST /webhooks - Subscribe a URL to task events (url, events, tickers, secret)",
+				"get":        "GET /webhooks/{id} - Get a webhook subscription",
+				"update":     "PUT /webhooks/{id} - Replace a webhook subscription",
+				"delete":     "DELETE /webhooks/{id} - Delete a webhook subscription and its delivery log",
+				"deliveries": "GET /webhooks/{id}/deliveries - Latest delivery attempts (limit)",
+				"test":       "POST /webhooks/{id}/test - Send a signed ping event",
+			},
+			"calendar": map[string]string{
+				"get": "GET /calendar - NYSE sessions, holidays and early closes (from, days); next open and close",
+			},
 			"system": map[string]string{
-				"outputs": "GET /outputs - List all files in outputs directory",
-				"cache":   "GET /system/cache - Inspect Redis cache",
-				"logs":    "GET /system/logs - Retrieve latest log lines",
-				"reset":   "DELETE /system/reset - Wipe all system data (Redis, Qdrant, Feast, Outputs)",
-				"metrics": "GET /metrics - Prometheus metrics",
+				"outputs":     "GET /outputs - List all files in outputs directory",
+				"cache":       "GET /system/cache - Inspect Redis cache",
+				"cache_warm":  "POST /system/cache/warm - Precompute and cache predictions (tickers optional)",
+				"warm_status": "GET /system/cache/warm[/{id}] - Warm-up targets and run progress",
+				"logs":        "GET /system/logs - Retrieve latest log lines",
+				"reset":       "DELETE /system/reset - Wipe all system data (Redis, Qdrant, Feast, Outputs)",
+				"metrics":     "GET /metrics - Prometheus metrics",
 			},
 			"agent": map[string]string{
 				"analyze": "POST /analyze - Analyze stock with AI agent",
@@ -15,11 +15,11 @@ import (
 // MonitorHandler handles monitoring endpoints
 type MonitorHandler struct {
 	cfg    *config.Config
-	runner *python.Runner
+	runner python.RunnerInterface
 }
 
 // NewMonitorHandler creates a new monitor handler
-func NewMonitorHandler(cfg *config.Config, runner *python.Runner) *MonitorHandler {
+func NewMonitorHandler(cfg *config.Config, runner python.RunnerInterface) *MonitorHandler {
 	return &MonitorHandler{
 		cfg:    cfg,
 		runner: runner,
@@ -30,11 +30,7 @@ func NewMonitorHandler(cfg *config.Config, runner *python.Runner) *MonitorHandle
 func (h *MonitorHandler) MonitorParent(w http.ResponseWriter, r *http.Request) {
 	result, err := h.runner.MonitorParent(r.Context())
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"detail": "Monitoring failed: " + err.Error(),
-		})
+		respondRunnerError(w, "Monitoring failed: ", err)
 		return
 	}
 
@@ -58,11 +54,7 @@ func (h *MonitorHandler) MonitorTicker(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.runner.MonitorTicker(r.Context(), ticker)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"detail": "Monitoring failed: " + err.Error(),
-		})
+		respondRunnerError(w, "Monitoring failed: ", err)
 		return
 	}
 
@@ -3,9 +3,11 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/shrithkshahapure/stock-agent-ops/internal/services/cache"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/webhooks"
 )
 
 // PredictHandler handles prediction endpoints
@@ -22,16 +25,19 @@ type PredictHandler struct {
 	runner      python.RunnerInterface
 	cache       cache.CacheInterface
 	taskManager tasks.ManagerInterface
+	events      webhooks.PublisherInterface
 	metrics     *metrics.Metrics
 }
 
-// NewPredictHandler creates a new predict handler
-func NewPredictHandler(cfg *config.Config, runner python.RunnerInterface, c cache.CacheInterface, taskManager tasks.ManagerInterface, m *metrics.Metrics) *PredictHandler {
+// NewPredictHandler creates a new predict handler. events, if not nil, is
+// told when a missing model starts training.
+func NewPredictHandler(cfg *config.Config, runner python.RunnerInterface, c cache.CacheInterface, taskManager tasks.ManagerInterface, events webhooks.PublisherInterface, m *metrics.Metrics) *PredictHandler {
 	return &PredictHandler{
 		cfg:         cfg,
 		runner:      runner,
 		cache:       c,
 		taskManager: taskManager,
+		events:      events,
 		metrics:     m,
 	}
 }
@@ -57,11 +63,7 @@ func (h *PredictHandler) PredictParent(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.runner.PredictParent(r.Context())
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"detail": err.Error(),
-		})
+		respondRunnerError(w, "", err)
 		return
 	}
 
@@ -107,45 +109,28 @@ func (h *PredictHandler) PredictChild(w http.ResponseWriter, r *http.Request) {
 	}
 	start := time.Now()
 
-	// Check cache first
+	// Serve from the cache, or make one prediction for every concurrent
+	// request for this ticker and cache it
+	predict := func(ctx context.Context) (map[string]interface{}, error) {
+		result, err := h.runner.PredictChild(ctx, ticker)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	}
+	var entry *cache.Entry
+	var err error
 	if h.cache != nil {
-		if cached, found := h.cache.Get(ticker); found {
-			if h.metrics != nil {
-				h.metrics.PredictionLatency.WithLabelValues("child").Observe(time.Since(start).Seconds())
-			}
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"result": cached,
-			})
-			return
+		entry, err = h.cache.Fill(r.Context(), ticker, cache.EndpointPredictChild, predict)
+	} else {
+		var data map[string]interface{}
+		if data, err = predict(r.Context()); err == nil {
+			entry = &cache.Entry{Ticker: ticker, Data: data}
 		}
 	}
-
-	// Execute prediction
-	result, err := h.runner.PredictChild(r.Context(), ticker)
 	if err != nil {
-		errStr := err.Error()
-
-		// Check if model missing - trigger auto-training
-		if strings.Contains(strings.ToLower(errStr), "missing") || strings.Contains(strings.ToLower(errStr), "not found") {
-			// Check parent model
-			if !h.modelExists("parent", "parent") {
-				if h.taskManager != nil {
-					parentStatus := h.taskManager.GetStatus("parent_training")
-					if parentStatus == nil || parentStatus.Status != "completed" {
-						h.taskManager.StartTrainParent()
-						w.Header().Set("Content-Type", "application/json")
-						w.WriteHeader(http.StatusAccepted)
-						json.NewEncoder(w).Encode(map[string]interface{}{
-							"status":  "training",
-							"detail":  "Parent model missing. Training parent first.",
-							"task_id": "parent_training",
-						})
-						return
-					}
-				}
-			}
-
+		// Model missing - trigger auto-training
+		if errors.Is(err, python.ErrModelMissing) {
 			// Check if child training is already running
 			if h.taskManager != nil && h.taskManager.IsRunning(taskID) {
 				w.Header().Set("Content-Type", "application/json")
@@ -158,48 +143,76 @@ func (h *PredictHandler) PredictChild(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
-			// Start child training with cache refresh chain
+			// Train whatever is missing, then predict and warm the cache
+			detail := "Model for " + ticker + " missing. Training started (with auto-prediction)."
 			if h.taskManager != nil {
-				chainFn := func() {
-					// After training, cache the prediction
-					ctx := context.Background()
-					res, err := h.runner.PredictChild(ctx, ticker)
-					if err == nil && h.cache != nil {
-						h.cache.Set(ticker, res.Data)
+				parentMissing := !h.modelExists("parent", "parent")
+				if parentMissing {
+					detail = "Parent model missing. Training parent, then " + ticker + " (with auto-prediction)."
+				}
+				steps := tasks.ChildPipeline(parentMissing, tasks.ActionPredictChild, tasks.ActionWarmCache)
+				outcome, err := h.taskManager.StartTrainChild(taskID, tasks.PriorityNormal, steps)
+				if err != nil {
+					respondError(w, http.StatusServiceUnavailable, err.Error())
+					return
+				}
+				if h.events != nil && outcome != tasks.OutcomeActive {
+					pipeline := make([]string, len(steps))
+					for i, step := range steps {
+						pipeline[i] = step.Name
 					}
+					h.events.Publish(webhooks.Event{
+						Type:   webhooks.EventAutoHeal,
+						TaskID: taskID,
+						Ticker: ticker,
+						Data: map[string]interface{}{
+							"parent_missing": parentMissing,
+							"pipeline":       pipeline,
+							"queued":         outcome == tasks.OutcomeQueued,
+						},
+					})
 				}
-				h.taskManager.StartTrainChild(taskID, chainFn)
 			}
 
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusAccepted)
 			json.NewEncoder(w).Encode(map[string]interface{}{
 				"status":  "training",
-				"detail":  "Model for " + ticker + " missing. Training started (with auto-prediction).",
+				"detail":  detail,
 				"task_id": taskID,
 			})
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"detail": errStr,
-		})
+		respondRunnerError(w, "", err)
 		return
 	}
 
-	// Cache result
-	if h.cache != nil {
-		h.cache.Set(ticker, result.Data)
-	}
-
 	if h.metrics != nil {
 		h.metrics.PredictionLatency.WithLabelValues("child").Observe(time.Since(start).Seconds())
 	}
 
+	setCacheHeaders(w, entry)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"result": result.Data,
+		"result": entry.Data,
 	})
 }
+
+// setCacheHeaders tells the client whether a prediction came from the
+// cache and how old it is. Stale predictions also say how long ago they
+// expired; a fresh one is being made for the next request.
+func setCacheHeaders(w http.ResponseWriter, entry *cache.Entry) {
+	switch {
+	case !entry.Hit:
+		w.Header().Set("X-Cache", "miss")
+	case entry.Stale:
+		w.Header().Set("X-Cache", "stale")
+		w.Header().Set("X-Cache-Stale-Seconds", strconv.Itoa(int(time.Since(entry.ExpiresAt).Seconds())))
+	default:
+		w.Header().Set("X-Cache", "hit")
+	}
+	if !entry.CachedAt.IsZero() {
+		w.Header().Set("Age", strconv.Itoa(int(time.Since(entry.CachedAt).Seconds())))
+	}
+}
@@ -1,22 +1,27 @@
 package handlers_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/handlers"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/cache"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/webhooks"
 )
 
 // mockCache is a test double that implements cache.CacheInterface.
 type mockCache struct {
 	data    map[string]map[string]interface{}
+	entries map[string]*cache.Entry // returned by Fill ahead of data
 	tickers []string
 }
 
@@ -36,6 +41,20 @@ func (c *mockCache) Delete(ticker string) error {
 	delete(c.data, strings.ToLower(ticker))
 	return nil
 }
+func (c *mockCache) Fill(ctx context.Context, ticker, endpoint string, predict func(context.Context) (map[string]interface{}, error)) (*cache.Entry, error) {
+	if entry, ok := c.entries[strings.ToLower(ticker)]; ok {
+		return entry, nil
+	}
+	if v, ok := c.Get(ticker); ok {
+		return &cache.Entry{Ticker: strings.ToUpper(ticker), Data: v, Hit: true}, nil
+	}
+	v, err := predict(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(ticker, v)
+	return &cache.Entry{Ticker: strings.ToUpper(ticker), Data: v}, nil
+}
 func (c *mockCache) GetCachedTickers() ([]string, error) { return c.tickers, nil }
 func (c *mockCache) GetForTicker(ticker string) (map[string]interface{}, error) {
 	v, ok := c.data[strings.ToLower(ticker)]
@@ -45,24 +64,98 @@ func (c *mockCache) GetForTicker(ticker string) (map[string]interface{}, error)
 	return v, nil
 }
 
+// mockPublisher is a test double that implements webhooks.PublisherInterface.
+type mockPublisher struct {
+	events []webhooks.Event
+}
+
+func (p *mockPublisher) Publish(event webhooks.Event) {
+	p.events = append(p.events, event)
+}
+
 // mockManager is a test double that implements tasks.ManagerInterface.
 type mockManager struct {
-	statuses map[string]*tasks.TaskStatus
-	running  map[string]bool
+	statuses  map[string]*tasks.TaskStatus
+	running   map[string]bool
+	cancelled map[string]string
+	outcome   tasks.Outcome // returned by StartTrainParent and StartTrainChild
+	runs      []*tasks.Run
+	filter    tasks.RunFilter // last ListRuns filter
+	steps     []tasks.Step    // last StartTrainChild pipeline
+	resumed   string          // last Resume run ID
+	resumeErr error
+	batch     *tasks.BatchSpec // last StartTrainBatch request
+	batches   map[string]*tasks.Batch
+	dead      map[string]*tasks.DeadLetter
 }
 
 func newMockManager() *mockManager {
 	return &mockManager{
-		statuses: make(map[string]*tasks.TaskStatus),
-		running:  make(map[string]bool),
+		statuses:  make(map[string]*tasks.TaskStatus),
+		running:   make(map[string]bool),
+		cancelled: make(map[string]string),
+		batches:   make(map[string]*tasks.Batch),
+		dead:      make(map[string]*tasks.DeadLetter),
 	}
 }
 
 func (m *mockManager) GetStatus(taskID string) *tasks.TaskStatus { return m.statuses[taskID] }
 func (m *mockManager) IsRunning(taskID string) bool              { return m.running[taskID] }
-func (m *mockManager) StartTrainParent() (bool, error)           { return true, nil }
-func (m *mockManager) StartTrainChild(_ string, _ func()) (bool, error) {
-	return true, nil
+func (m *mockManager) StartTrainParent(tasks.Priority) (tasks.Outcome, error) {
+	return m.outcome, nil
+}
+func (m *mockManager) StartTrainChild(_ string, _ tasks.Priority, steps []tasks.Step) (tasks.Outcome, error) {
+	m.steps = steps
+	return m.outcome, nil
+}
+func (m *mockManager) StartTrainBatch(spec tasks.BatchSpec) (*tasks.Batch, error) {
+	m.batch = &spec
+	batch := &tasks.Batch{BatchID: "batch-1", Status: "running"}
+	m.batches[batch.BatchID] = batch
+	return batch, nil
+}
+func (m *mockManager) GetBatch(batchID string) *tasks.Batch { return m.batches[batchID] }
+func (m *mockManager) Resume(runID string) (tasks.Outcome, error) {
+	m.resumed = runID
+	return m.outcome, m.resumeErr
+}
+func (m *mockManager) GetRun(runID string) *tasks.Run {
+	for _, run := range m.runs {
+		if run.RunID == runID {
+			return run
+		}
+	}
+	return nil
+}
+func (m *mockManager) ListRuns(filter tasks.RunFilter) ([]*tasks.Run, int, error) {
+	m.filter = filter
+	return m.runs, len(m.runs), nil
+}
+func (m *mockManager) ListDeadLetters() ([]*tasks.DeadLetter, error) {
+	entries := []*tasks.DeadLetter{}
+	for _, entry := range m.dead {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+func (m *mockManager) RetryDeadLetter(taskID string) (tasks.Outcome, error) {
+	if m.dead[taskID] == nil {
+		return tasks.OutcomeStarted, tasks.ErrDeadLetterNotFound
+	}
+	delete(m.dead, taskID)
+	return m.outcome, nil
+}
+func (m *mockManager) DeleteDeadLetter(taskID string) (bool, error) {
+	_, ok := m.dead[taskID]
+	delete(m.dead, taskID)
+	return ok, nil
+}
+func (m *mockManager) Cancel(taskID, cancelledBy string) bool {
+	if !m.running[taskID] {
+		return false
+	}
+	m.cancelled[taskID] = cancelledBy
+	return true
 }
 
 // ── PredictParent ──────────────────────────────────────────────────────────
@@ -73,7 +166,7 @@ func TestPredictParent_RunnerError(t *testing.T) {
 
 	h := handlers.NewPredictHandler(cfg,
 		&mockRunner{predictErr: errors.New("model not found")},
-		nil, nil, nil,
+		nil, nil, nil, nil,
 	)
 
 	req := httptest.NewRequest(http.MethodPost, "/predict-parent", nil)
@@ -93,7 +186,7 @@ func TestPredictParent_Success(t *testing.T) {
 		&mockRunner{predictResult: &python.Result{Data: map[string]interface{}{
 			"ticker": "^GSPC",
 		}}},
-		nil, nil, nil,
+		nil, nil, nil, nil,
 	)
 
 	req := httptest.NewRequest(http.MethodPost, "/predict-parent", nil)
@@ -114,7 +207,7 @@ func TestPredictParent_Success(t *testing.T) {
 
 func TestPredictChild_MissingTicker(t *testing.T) {
 	cfg := config.Load()
-	h := handlers.NewPredictHandler(cfg, &mockRunner{}, nil, nil, nil)
+	h := handlers.NewPredictHandler(cfg, &mockRunner{}, nil, nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/predict-child",
 		strings.NewReader(`{"ticker":""}`))
@@ -129,7 +222,7 @@ func TestPredictChild_MissingTicker(t *testing.T) {
 
 func TestPredictChild_InvalidJSON(t *testing.T) {
 	cfg := config.Load()
-	h := handlers.NewPredictHandler(cfg, &mockRunner{}, nil, nil, nil)
+	h := handlers.NewPredictHandler(cfg, &mockRunner{}, nil, nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/predict-child",
 		strings.NewReader("not json"))
@@ -147,7 +240,7 @@ func TestPredictChild_CacheHit(t *testing.T) {
 	mc := newMockCache()
 	mc.data["aapl"] = map[string]interface{}{"ticker": "AAPL", "cached": true}
 
-	h := handlers.NewPredictHandler(cfg, &mockRunner{}, mc, nil, nil)
+	h := handlers.NewPredictHandler(cfg, &mockRunner{}, mc, nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/predict-child",
 		strings.NewReader(`{"ticker":"AAPL"}`))
@@ -166,6 +259,39 @@ func TestPredictChild_CacheHit(t *testing.T) {
 	}
 }
 
+func TestPredictChild_StaleHeaders(t *testing.T) {
+	cfg := config.Load()
+	mc := newMockCache()
+	mc.entries = map[string]*cache.Entry{"aapl": {
+		Ticker:    "AAPL",
+		CachedAt:  time.Now().Add(-2 * time.Hour),
+		ExpiresAt: time.Now().Add(-90 * time.Second),
+		Stale:     true,
+		Hit:       true,
+		Data:      map[string]interface{}{"ticker": "AAPL"},
+	}}
+
+	h := handlers.NewPredictHandler(cfg, &mockRunner{}, mc, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/predict-child",
+		strings.NewReader(`{"ticker":"AAPL"}`))
+	rec := httptest.NewRecorder()
+	h.PredictChild(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PredictChild(stale) status = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("X-Cache"); got != "stale" {
+		t.Errorf("X-Cache = %q, want stale", got)
+	}
+	if got := rec.Header().Get("Age"); got != "7200" {
+		t.Errorf("Age = %q, want 7200", got)
+	}
+	if got := rec.Header().Get("X-Cache-Stale-Seconds"); got != "90" {
+		t.Errorf("X-Cache-Stale-Seconds = %q, want 90", got)
+	}
+}
+
 func TestPredictChild_Success_CachesResult(t *testing.T) {
 	cfg := config.Load()
 	mc := newMockCache()
@@ -173,7 +299,7 @@ func TestPredictChild_Success_CachesResult(t *testing.T) {
 		&mockRunner{predictResult: &python.Result{Data: map[string]interface{}{
 			"ticker": "TSLA",
 		}}},
-		mc, nil, nil,
+		mc, nil, nil, nil,
 	)
 
 	req := httptest.NewRequest(http.MethodPost, "/predict-child",
@@ -189,6 +315,9 @@ func TestPredictChild_Success_CachesResult(t *testing.T) {
 	if _, found := mc.Get("TSLA"); !found {
 		t.Error("PredictChild(success) result should be cached after prediction")
 	}
+	if got := rec.Header().Get("X-Cache"); got != "miss" {
+		t.Errorf("X-Cache = %q, want miss", got)
+	}
 }
 
 func TestPredictChild_ModelMissing_StartsTraining(t *testing.T) {
@@ -197,9 +326,10 @@ func TestPredictChild_ModelMissing_StartsTraining(t *testing.T) {
 	cfg.ParentDir = t.TempDir()
 
 	mm := newMockManager()
+	events := &mockPublisher{}
 	h := handlers.NewPredictHandler(cfg,
-		&mockRunner{predictErr: errors.New("missing model file")},
-		newMockCache(), mm, nil,
+		&mockRunner{predictErr: &python.Error{Code: python.CodeModelMissing, Message: "Missing model for NVDA"}},
+		newMockCache(), mm, events, nil,
 	)
 
 	req := httptest.NewRequest(http.MethodPost, "/predict-child",
@@ -217,4 +347,78 @@ func TestPredictChild_ModelMissing_StartsTraining(t *testing.T) {
 	if resp["status"] != "training" {
 		t.Errorf("PredictChild(missing model) status = %v, want \"training\"", resp["status"])
 	}
+	if resp["task_id"] != "nvda" {
+		t.Errorf("PredictChild(missing model) task_id = %v, want \"nvda\"", resp["task_id"])
+	}
+
+	// Parent and child are both missing: one pipeline trains them, then
+	// predicts and warms the cache
+	var actions []string
+	for _, step := range mm.steps {
+		actions = append(actions, step.Action)
+	}
+	want := []string{tasks.ActionTrainParent, tasks.ActionTrainChild, tasks.ActionPredictChild, tasks.ActionWarmCache}
+	if strings.Join(actions, ",") != strings.Join(want, ",") {
+		t.Errorf("PredictChild(missing model) pipeline = %v, want %v", actions, want)
+	}
+
+	if len(events.events) != 1 || events.events[0].Type != webhooks.EventAutoHeal || events.events[0].Ticker != "NVDA" {
+		t.Errorf("PredictChild(missing model) published %+v, want one %s event for NVDA", events.events, webhooks.EventAutoHeal)
+	}
+}
+
+func TestPredictChild_UntypedMissingError_NoAutoTrain(t *testing.T) {
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.ParentDir = t.TempDir()
+
+	// Wording alone must not trigger self-healing; only the typed code does
+	h := handlers.NewPredictHandler(cfg,
+		&mockRunner{predictErr: errors.New("missing model file")},
+		newMockCache(), newMockManager(), nil, nil,
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/predict-child",
+		strings.NewReader(`{"ticker":"NVDA"}`))
+	rec := httptest.NewRecorder()
+	h.PredictChild(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("PredictChild(untyped error) status = %d, want 500", rec.Code)
+	}
+}
+
+func TestPredictChild_TypedErrorStatus(t *testing.T) {
+	tests := []struct {
+		code string
+		want int
+	}{
+		{python.CodeInvalidTicker, http.StatusUnprocessableEntity},
+		{python.CodeDataUnavailable, http.StatusServiceUnavailable},
+		{python.CodeTimeout, http.StatusGatewayTimeout},
+		{python.CodeInternal, http.StatusInternalServerError},
+	}
+	for _, tc := range tests {
+		t.Run(tc.code, func(t *testing.T) {
+			cfg := config.Load()
+			h := handlers.NewPredictHandler(cfg,
+				&mockRunner{predictErr: &python.Error{Code: tc.code, Message: "boom"}},
+				newMockCache(), newMockManager(), nil, nil,
+			)
+
+			req := httptest.NewRequest(http.MethodPost, "/predict-child",
+				strings.NewReader(`{"ticker":"AAPL"}`))
+			rec := httptest.NewRecorder()
+			h.PredictChild(rec, req)
+
+			if rec.Code != tc.want {
+				t.Fatalf("PredictChild(%s) status = %d, want %d", tc.code, rec.Code, tc.want)
+			}
+			var resp map[string]interface{}
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if resp["code"] != tc.code {
+				t.Errorf("PredictChild(%s) code = %v, want %q", tc.code, resp["code"], tc.code)
+			}
+		})
+	}
 }
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/scheduler"
+)
+
+// ScheduleHandler handles the recurring job endpoints
+type ScheduleHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+// NewScheduleHandler creates a new schedule handler
+func NewScheduleHandler(s *scheduler.Scheduler) *ScheduleHandler {
+	return &ScheduleHandler{scheduler: s}
+}
+
+// List handles GET /schedules
+func (h *ScheduleHandler) List(w http.ResponseWriter, r *http.Request) {
+	schedules, err := h.scheduler.List()
+	if err != nil {
+		respondError(w, http.StatusServiceUnavailable, "Failed to read schedules: "+err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"schedules": schedules,
+		"total":     len(schedules),
+	})
+}
+
+// Create handles POST /schedules
+func (h *ScheduleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var spec scheduler.Spec
+	if err := decodeJSON(r, &spec); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	schedule, err := h.scheduler.Create(spec)
+	if err != nil {
+		respondScheduleError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, schedule)
+}
+
+// Get handles GET /schedules/{id}
+func (h *ScheduleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToLower(chi.URLParam(r, "id"))
+
+	schedule, err := h.scheduler.Get(id)
+	if err != nil {
+		respondError(w, http.StatusServiceUnavailable, "Failed to read schedule: "+err.Error())
+		return
+	}
+	if schedule == nil {
+		respondError(w, http.StatusNotFound, "Schedule '"+id+"' not found.")
+		return
+	}
+	respondJSON(w, http.StatusOK, schedule)
+}
+
+// Update handles PUT /schedules/{id}
+func (h *ScheduleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToLower(chi.URLParam(r, "id"))
+
+	var spec scheduler.Spec
+	if err := decodeJSON(r, &spec); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	schedule, err := h.scheduler.Update(id, spec)
+	if err != nil {
+		respondScheduleError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, schedule)
+}
+
+// Delete handles DELETE /schedules/{id}
+func (h *ScheduleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToLower(chi.URLParam(r, "id"))
+
+	deleted, err := h.scheduler.Delete(id)
+	if err != nil {
+		respondError(w, http.StatusServiceUnavailable, "Failed to delete schedule: "+err.Error())
+		return
+	}
+	if !deleted {
+		respondError(w, http.StatusNotFound, "Schedule '"+id+"' not found.")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{
+		"status": "deleted",
+		"id":     id,
+	})
+}
+
+// respondScheduleError maps a scheduler error to an HTTP status code
+func respondScheduleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, scheduler.ErrInvalid):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, scheduler.ErrNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, scheduler.ErrExists):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		respondError(w, http.StatusServiceUnavailable, err.Error())
+	}
+}
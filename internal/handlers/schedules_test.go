@@ -0,0 +1,100 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/handlers"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/scheduler"
+)
+
+// chiRequestBody is chiRequest with a request body
+func chiRequestBody(method, url string, params map[string]string, body string) *http.Request {
+	req := chiRequest(method, url, params)
+	req.Body = io.NopCloser(strings.NewReader(body))
+	return req
+}
+
+func newScheduleHandler(t *testing.T) *handlers.ScheduleHandler {
+	t.Helper()
+	s := scheduler.New(config.Load(), nil, newMockManager(), &mockRunner{})
+	t.Cleanup(s.Close)
+	return handlers.NewScheduleHandler(s)
+}
+
+func TestSchedules_CRUD(t *testing.T) {
+	h := newScheduleHandler(t)
+
+	body := `{"id":"nightly","action":"train-child","tickers":["AAPL","MSFT"],"cron":"0 2 * * *","timezone":"UTC"}`
+	rec := httptest.NewRecorder()
+	h.Create(rec, httptest.NewRequest(http.MethodPost, "/schedules", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Create status = %d, want 201: %s", rec.Code, rec.Body.String())
+	}
+	var created map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if created["next_run"] == nil || created["enabled"] != true {
+		t.Errorf("Create = %v, want enabled with a next_run", created)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Create(rec, httptest.NewRequest(http.MethodPost, "/schedules", strings.NewReader(body)))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Create(duplicate) status = %d, want 409", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Update(rec, chiRequestBody(http.MethodPut, "/schedules/nightly", map[string]string{"id": "nightly"},
+		`{"action":"monitor-ticker","tickers":["AAPL"],"cron":"@hourly"}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Update status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.Get(rec, chiRequest(http.MethodGet, "/schedules/nightly", map[string]string{"id": "nightly"}))
+	var got map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	if got["action"] != "monitor-ticker" || got["cron"] != "@hourly" {
+		t.Errorf("Get after update = %v, want hourly monitor-ticker", got)
+	}
+
+	rec = httptest.NewRecorder()
+	h.List(rec, httptest.NewRequest(http.MethodGet, "/schedules", nil))
+	var list map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if list["total"] != float64(1) {
+		t.Errorf("List total = %v, want 1", list["total"])
+	}
+
+	rec = httptest.NewRecorder()
+	h.Delete(rec, chiRequest(http.MethodDelete, "/schedules/nightly", map[string]string{"id": "nightly"}))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Delete status = %d, want 200", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.Get(rec, chiRequest(http.MethodGet, "/schedules/nightly", map[string]string{"id": "nightly"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Get after delete status = %d, want 404", rec.Code)
+	}
+}
+
+func TestSchedules_CreateInvalid(t *testing.T) {
+	h := newScheduleHandler(t)
+
+	for _, body := range []string{
+		`not json`,
+		`{"action":"train-child","cron":"@daily"}`,
+		`{"action":"train-parent","cron":"at noon"}`,
+	} {
+		rec := httptest.NewRecorder()
+		h.Create(rec, httptest.NewRequest(http.MethodPost, "/schedules", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Create(%s) status = %d, want 400", body, rec.Code)
+		}
+	}
+}
@@ -84,8 +84,9 @@ func (h *StatusHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// If status is not running/failed but file exists, mark as completed
-	if status.Status != "running" && status.Status != "failed" && fileExists {
+	// If status is not queued/running/failed/cancelled/interrupted but file exists, mark as completed
+	if status.Status != "queued" && status.Status != "running" && status.Status != "failed" &&
+		status.Status != "cancelled" && status.Status != "interrupted" && fileExists {
 		status.Status = "completed"
 	}
 
@@ -95,6 +96,20 @@ func (h *StatusHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 		"task_id": taskID,
 	}
 
+	if status.Status == "queued" {
+		if status.QueuePosition > 0 {
+			response["queue_position"] = status.QueuePosition
+		}
+		if status.QueuedAt != "" {
+			response["queued_at"] = status.QueuedAt
+		}
+		if status.Priority != "" {
+			response["priority"] = status.Priority
+		}
+	}
+	if status.RunID != "" {
+		response["run_id"] = status.RunID
+	}
 	if status.Result != nil {
 		response["result"] = status.Result
 	}
@@ -107,15 +122,37 @@ func (h *StatusHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 	if status.FailedAt != "" {
 		response["failed_at"] = status.FailedAt
 	}
+	if status.InterruptedAt != "" {
+		response["interrupted_at"] = status.InterruptedAt
+	}
+	if status.CancelledAt != "" {
+		response["cancelled_at"] = status.CancelledAt
+		response["cancelled_by"] = status.CancelledBy
+	}
 
 	// Calculate elapsed seconds for running tasks
 	if status.Status == "running" && status.StartTime != "" {
-		startTime, err := time.Parse("2006-01-02 15:04:05", status.StartTime)
+		// Statuses written before run history used local time without a zone
+		startTime, err := time.Parse(time.RFC3339, status.StartTime)
+		if err != nil {
+			startTime, err = time.ParseInLocation("2006-01-02 15:04:05", status.StartTime, time.Local)
+		}
 		if err == nil {
 			response["elapsed_seconds"] = int(time.Since(startTime).Seconds())
 		}
 	}
 
+	// Each step of the task's pipeline and how far it got
+	if len(status.Pipeline) > 0 {
+		response["pipeline"] = status.Pipeline
+	}
+
+	// Latest progress reported by the training pipeline
+	if status.Status == "running" && status.Progress != nil {
+		response["progress"] = status.Progress
+		response["percent_complete"] = status.Progress.Percent
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
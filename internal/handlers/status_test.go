@@ -10,6 +10,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/handlers"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
 )
 
@@ -70,6 +71,37 @@ func TestGetStatus_RunningTask(t *testing.T) {
 	}
 }
 
+func TestGetStatus_RunningTaskProgress(t *testing.T) {
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.ParentDir = t.TempDir()
+
+	mm := newMockManager()
+	mm.statuses["aapl"] = &tasks.TaskStatus{
+		Status:    "running",
+		StartTime: "2025-01-01 12:00:00",
+		Progress: &python.Progress{
+			Phase: "train", Epoch: 5, TotalEpochs: 10, Loss: 0.02, ValMSE: 0.03, Percent: 50,
+		},
+	}
+
+	h := handlers.NewStatusHandler(cfg, mm)
+
+	req := chiRequest(http.MethodGet, "/status/aapl", map[string]string{"task_id": "aapl"})
+	rec := httptest.NewRecorder()
+	h.GetStatus(rec, req)
+
+	var resp map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp["percent_complete"] != 50.0 {
+		t.Errorf("GetStatus(progress) percent_complete = %v, want 50", resp["percent_complete"])
+	}
+	progress, _ := resp["progress"].(map[string]interface{})
+	if progress["phase"] != "train" || progress["val_mse"] != 0.03 {
+		t.Errorf("GetStatus(progress) progress = %v, want phase=train val_mse=0.03", progress)
+	}
+}
+
 func TestGetStatus_CompletedTask(t *testing.T) {
 	cfg := config.Load()
 	cfg.OutputsDir = t.TempDir()
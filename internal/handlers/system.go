@@ -13,22 +13,26 @@ import (
 
 	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/services/cache"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
 	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
 )
 
 // SystemHandler handles system endpoints
 type SystemHandler struct {
-	cfg   *config.Config
-	redis *redisclient.Client
-	cache *cache.Cache
+	cfg    *config.Config
+	redis  *redisclient.Client
+	cache  *cache.Cache
+	runner python.RunnerInterface
 }
 
-// NewSystemHandler creates a new system handler
-func NewSystemHandler(cfg *config.Config, redis *redisclient.Client, cache *cache.Cache) *SystemHandler {
+// NewSystemHandler creates a new system handler. runner refreshes stale
+// predictions served from the cache.
+func NewSystemHandler(cfg *config.Config, redis *redisclient.Client, cache *cache.Cache, runner python.RunnerInterface) *SystemHandler {
 	return &SystemHandler{
-		cfg:   cfg,
-		redis: redis,
-		cache: cache,
+		cfg:    cfg,
+		redis:  redis,
+		cache:  cache,
+		runner: runner,
 	}
 }
 
@@ -122,7 +126,7 @@ func (h *SystemHandler) GetCache(w http.ResponseWriter, r *http.Request) {
 	ticker := r.URL.Query().Get("ticker")
 
 	if ticker == "" {
-		// Return list of cached tickers
+		// Return list of cached tickers with the model version of each
 		tickers, err := h.cache.GetCachedTickers()
 		if err != nil {
 			w.Header().Set("Content-Type", "application/json")
@@ -131,10 +135,19 @@ func (h *SystemHandler) GetCache(w http.ResponseWriter, r *http.Request) {
 			})
 			return
 		}
+		entries, err := h.cache.Entries()
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"cached_tickers": tickers,
+			"entries":        entries,
 			"count":          len(tickers),
 		})
 		return
@@ -142,17 +155,15 @@ func (h *SystemHandler) GetCache(w http.ResponseWriter, r *http.Request) {
 
 	// Return specific ticker's cache
 	ticker = strings.TrimSpace(strings.ToUpper(ticker))
-	data, err := h.cache.GetForTicker(ticker)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"detail": err.Error(),
-		})
-		return
-	}
-
-	if data == nil {
+	// A stale prediction is refreshed in the background, as /predict-child does
+	entry, found := h.cache.Serve(ticker, cache.EndpointSystemCache, func(ctx context.Context) (map[string]interface{}, error) {
+		result, err := h.runner.PredictChild(ctx, ticker)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	})
+	if !found {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{
@@ -162,7 +173,10 @@ func (h *SystemHandler) GetCache(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(data)
+	w.Header().Set("X-Model-Version", entry.ModelVersion)
+	w.Header().Set("X-Cached-At", entry.CachedAt.Format(time.RFC3339))
+	setCacheHeaders(w, entry)
+	json.NewEncoder(w).Encode(entry.Data)
 }
 
 // Reset handles DELETE /system/reset
@@ -180,6 +194,10 @@ func (h *SystemHandler) Reset(w http.ResponseWriter, r *http.Request) {
 	} else {
 		results["redis"] = "Skipped (Not connected)"
 	}
+	if h.cache != nil {
+		// Drop in-process copies of the flushed predictions on every instance
+		h.cache.Clear()
+	}
 
 	// 2. Reset Qdrant (via Python - simplified, just note it)
 	// In a full implementation, we'd call a Python script or Qdrant API
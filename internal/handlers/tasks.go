@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
+)
+
+// TaskHandler handles task control endpoints
+type TaskHandler struct {
+	cfg         *config.Config
+	taskManager tasks.ManagerInterface
+}
+
+// NewTaskHandler creates a new task handler
+func NewTaskHandler(cfg *config.Config, taskManager tasks.ManagerInterface) *TaskHandler {
+	return &TaskHandler{
+		cfg:         cfg,
+		taskManager: taskManager,
+	}
+}
+
+// Cancel handles POST /tasks/{task_id}/cancel
+func (h *TaskHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	taskID := strings.ToLower(chi.URLParam(r, "task_id"))
+	if taskID == "parent" {
+		taskID = "parent_training"
+	}
+
+	// Body is optional; it only identifies who cancelled the task
+	var req struct {
+		CancelledBy string `json:"cancelled_by"`
+	}
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&req)
+	}
+	cancelledBy := strings.TrimSpace(req.CancelledBy)
+	if cancelledBy == "" {
+		cancelledBy = r.RemoteAddr
+	}
+
+	var status *tasks.TaskStatus
+	if h.taskManager != nil {
+		status = h.taskManager.GetStatus(taskID)
+	}
+	if status == nil {
+		respondError(w, http.StatusNotFound, "Task '"+taskID+"' not found.")
+		return
+	}
+	if status.Status != "running" && status.Status != "queued" && status.Status != "retrying" {
+		respondError(w, http.StatusConflict, "Task '"+taskID+"' is not running (status: "+status.Status+").")
+		return
+	}
+
+	if !h.taskManager.Cancel(taskID, cancelledBy) {
+		respondError(w, http.StatusConflict, "Task '"+taskID+"' is no longer running.")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":       "cancelled",
+		"task_id":      taskID,
+		"cancelled_by": cancelledBy,
+	})
+}
+
+// defaultRunsLimit and maxRunsLimit bound a page of GET /tasks
+const (
+	defaultRunsLimit = 50
+	maxRunsLimit     = 500
+)
+
+// ListTasks handles GET /tasks: training run history, newest first, filtered
+// by ticker, status and a created_at range (since/until, RFC3339)
+func (h *TaskHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := tasks.RunFilter{
+		TaskID: strings.ToLower(strings.TrimSpace(query.Get("ticker"))),
+		Status: strings.ToLower(strings.TrimSpace(query.Get("status"))),
+		Limit:  defaultRunsLimit,
+	}
+	if filter.TaskID == "parent" {
+		filter.TaskID = "parent_training"
+	}
+
+	var err error
+	if filter.Since, err = parseTimeParam(query.Get("since")); err != nil {
+		respondError(w, http.StatusBadRequest, "since: "+err.Error())
+		return
+	}
+	if filter.Until, err = parseTimeParam(query.Get("until")); err != nil {
+		respondError(w, http.StatusBadRequest, "until: "+err.Error())
+		return
+	}
+	if filter.Limit, err = parseIntParam(query.Get("limit"), defaultRunsLimit, 1, maxRunsLimit); err != nil {
+		respondError(w, http.StatusBadRequest, "limit: "+err.Error())
+		return
+	}
+	if filter.Offset, err = parseIntParam(query.Get("offset"), 0, 0, -1); err != nil {
+		respondError(w, http.StatusBadRequest, "offset: "+err.Error())
+		return
+	}
+
+	if h.taskManager == nil {
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"runs": []*tasks.Run{}, "total": 0, "limit": filter.Limit, "offset": filter.Offset,
+		})
+		return
+	}
+
+	runs, total, err := h.taskManager.ListRuns(filter)
+	if err != nil {
+		respondError(w, http.StatusServiceUnavailable, "Failed to read run history: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"runs":   runs,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// GetTask handles GET /tasks/{run_id}
+func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
+	runID := chi.URLParam(r, "run_id")
+
+	var run *tasks.Run
+	if h.taskManager != nil {
+		run = h.taskManager.GetRun(runID)
+	}
+	if run == nil {
+		respondError(w, http.StatusNotFound, "Run '"+runID+"' not found.")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, run)
+}
+
+// Resume handles POST /tasks/{run_id}/resume: it runs a failed, cancelled
+// or interrupted run's pipeline again from the steps that did not complete
+func (h *TaskHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	runID := chi.URLParam(r, "run_id")
+
+	var run *tasks.Run
+	if h.taskManager != nil {
+		run = h.taskManager.GetRun(runID)
+	}
+	if run == nil {
+		respondError(w, http.StatusNotFound, "Run '"+runID+"' not found.")
+		return
+	}
+
+	outcome, err := h.taskManager.Resume(runID)
+	switch {
+	case errors.Is(err, tasks.ErrRunNotFound):
+		respondError(w, http.StatusNotFound, "Run '"+runID+"' not found.")
+		return
+	case errors.Is(err, tasks.ErrNotResumable):
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		respondError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case outcome == tasks.OutcomeActive:
+		respondError(w, http.StatusConflict, "Task '"+run.TaskID+"' is already running or queued.")
+		return
+	}
+
+	status := "started"
+	if outcome == tasks.OutcomeQueued {
+		status = "queued"
+	}
+	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"status":       status,
+		"task_id":      run.TaskID,
+		"resumed_from": runID,
+	})
+}
+
+// ListDeadLetters handles GET /tasks/dead-letter: tasks that failed on
+// every attempt, with their error history
+func (h *TaskHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	entries := []*tasks.DeadLetter{}
+	if h.taskManager != nil {
+		var err error
+		if entries, err = h.taskManager.ListDeadLetters(); err != nil {
+			respondError(w, http.StatusServiceUnavailable, "Failed to read dead-letter list: "+err.Error())
+			return
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"dead_letters": entries,
+		"total":        len(entries),
+	})
+}
+
+// RetryDeadLetter handles POST /tasks/dead-letter/{task_id}/retry: it runs a
+// dead-lettered task again with a fresh set of attempts
+func (h *TaskHandler) RetryDeadLetter(w http.ResponseWriter, r *http.Request) {
+	taskID := strings.ToLower(chi.URLParam(r, "task_id"))
+	if taskID == "parent" {
+		taskID = "parent_training"
+	}
+	if h.taskManager == nil {
+		respondError(w, http.StatusNotFound, "Task '"+taskID+"' is not dead-lettered.")
+		return
+	}
+
+	outcome, err := h.taskManager.RetryDeadLetter(taskID)
+	switch {
+	case errors.Is(err, tasks.ErrDeadLetterNotFound):
+		respondError(w, http.StatusNotFound, "Task '"+taskID+"' is not dead-lettered.")
+		return
+	case err != nil:
+		respondError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case outcome == tasks.OutcomeActive:
+		respondError(w, http.StatusConflict, "Task '"+taskID+"' is already running or queued.")
+		return
+	}
+
+	status := "started"
+	if outcome == tasks.OutcomeQueued {
+		status = "queued"
+	}
+	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"status":  status,
+		"task_id": taskID,
+	})
+}
+
+// DeleteDeadLetter handles DELETE /tasks/dead-letter/{task_id}
+func (h *TaskHandler) DeleteDeadLetter(w http.ResponseWriter, r *http.Request) {
+	taskID := strings.ToLower(chi.URLParam(r, "task_id"))
+	if taskID == "parent" {
+		taskID = "parent_training"
+	}
+
+	deleted := false
+	if h.taskManager != nil {
+		var err error
+		if deleted, err = h.taskManager.DeleteDeadLetter(taskID); err != nil {
+			respondError(w, http.StatusServiceUnavailable, "Failed to delete dead letter: "+err.Error())
+			return
+		}
+	}
+	if !deleted {
+		respondError(w, http.StatusNotFound, "Task '"+taskID+"' is not dead-lettered.")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{
+		"status":  "deleted",
+		"task_id": taskID,
+	})
+}
+
+// parseTimeParam parses an optional RFC3339 query parameter
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("want an RFC3339 time such as 2026-01-02T15:04:05Z")
+	}
+	return t, nil
+}
+
+// parseIntParam parses an optional integer query parameter within
+// [min, max]; max < 0 means unbounded
+func parseIntParam(value string, def, min, max int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || (max >= 0 && n > max) {
+		if max < 0 {
+			return 0, fmt.Errorf("want an integer >= %d", min)
+		}
+		return 0, fmt.Errorf("want an integer from %d to %d", min, max)
+	}
+	return n, nil
+}
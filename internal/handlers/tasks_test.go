@@ -0,0 +1,190 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/handlers"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
+)
+
+func TestCancelTask_Running(t *testing.T) {
+	mm := newMockManager()
+	mm.running["aapl"] = true
+	mm.statuses["aapl"] = &tasks.TaskStatus{Status: "running"}
+
+	h := handlers.NewTaskHandler(config.Load(), mm)
+
+	req := chiRequest(http.MethodPost, "/tasks/AAPL/cancel", map[string]string{"task_id": "AAPL"})
+	req.Body = io.NopCloser(strings.NewReader(`{"cancelled_by":"alice"}`))
+	rec := httptest.NewRecorder()
+	h.Cancel(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Cancel(running) status = %d, want 200", rec.Code)
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp["status"] != "cancelled" {
+		t.Errorf("Cancel(running) status = %v, want \"cancelled\"", resp["status"])
+	}
+	if mm.cancelled["aapl"] != "alice" {
+		t.Errorf("Cancel(running) cancelled_by = %q, want \"alice\"", mm.cancelled["aapl"])
+	}
+}
+
+func TestCancelTask_NotFound(t *testing.T) {
+	h := handlers.NewTaskHandler(config.Load(), newMockManager())
+
+	req := chiRequest(http.MethodPost, "/tasks/ghost/cancel", map[string]string{"task_id": "ghost"})
+	rec := httptest.NewRecorder()
+	h.Cancel(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("Cancel(unknown) status = %d, want 404", rec.Code)
+	}
+}
+
+func TestCancelTask_NotRunning(t *testing.T) {
+	mm := newMockManager()
+	mm.statuses["msft"] = &tasks.TaskStatus{Status: "completed"}
+
+	h := handlers.NewTaskHandler(config.Load(), mm)
+
+	req := chiRequest(http.MethodPost, "/tasks/msft/cancel", map[string]string{"task_id": "msft"})
+	rec := httptest.NewRecorder()
+	h.Cancel(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("Cancel(completed) status = %d, want 409", rec.Code)
+	}
+}
+
+func TestListTasks_Filters(t *testing.T) {
+	mm := newMockManager()
+	mm.runs = []*tasks.Run{{RunID: "aapl-20260102T150405Z-1a2b3c4d", TaskID: "aapl", Status: "failed"}}
+	h := handlers.NewTaskHandler(config.Load(), mm)
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/tasks?ticker=AAPL&status=failed&since=2026-01-01T00:00:00Z&limit=10&offset=5", nil)
+	rec := httptest.NewRecorder()
+	h.ListTasks(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ListTasks status = %d, want 200", rec.Code)
+	}
+	f := mm.filter
+	if f.TaskID != "aapl" || f.Status != "failed" || f.Limit != 10 || f.Offset != 5 ||
+		!f.Since.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) || !f.Until.IsZero() {
+		t.Errorf("ListTasks filter = %+v, want aapl/failed since 2026-01-01, limit 10 offset 5", f)
+	}
+	var resp struct {
+		Runs  []tasks.Run `json:"runs"`
+		Total int         `json:"total"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Total != 1 || len(resp.Runs) != 1 || resp.Runs[0].TaskID != "aapl" {
+		t.Errorf("ListTasks body = %s, want the one aapl run", rec.Body.String())
+	}
+}
+
+func TestListTasks_BadParams(t *testing.T) {
+	h := handlers.NewTaskHandler(config.Load(), newMockManager())
+
+	for _, query := range []string{"since=yesterday", "limit=0", "limit=1000", "offset=-1"} {
+		rec := httptest.NewRecorder()
+		h.ListTasks(rec, httptest.NewRequest(http.MethodGet, "/tasks?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("ListTasks(%s) status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestGetTask(t *testing.T) {
+	mm := newMockManager()
+	mm.runs = []*tasks.Run{{RunID: "msft-20260102T150405Z-1a2b3c4d", TaskID: "msft", Status: "completed"}}
+	h := handlers.NewTaskHandler(config.Load(), mm)
+
+	req := chiRequest(http.MethodGet, "/tasks/msft-20260102T150405Z-1a2b3c4d",
+		map[string]string{"run_id": "msft-20260102T150405Z-1a2b3c4d"})
+	rec := httptest.NewRecorder()
+	h.GetTask(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GetTask(known) status = %d, want 200", rec.Code)
+	}
+
+	req = chiRequest(http.MethodGet, "/tasks/ghost", map[string]string{"run_id": "ghost"})
+	rec = httptest.NewRecorder()
+	h.GetTask(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GetTask(unknown) status = %d, want 404", rec.Code)
+	}
+}
+
+func TestResumeTask(t *testing.T) {
+	mm := newMockManager()
+	mm.runs = []*tasks.Run{{RunID: "msft-20260102T150405Z-1a2b3c4d", TaskID: "msft", Status: "failed"}}
+	h := handlers.NewTaskHandler(config.Load(), mm)
+
+	req := chiRequest(http.MethodPost, "/tasks/msft-20260102T150405Z-1a2b3c4d/resume",
+		map[string]string{"run_id": "msft-20260102T150405Z-1a2b3c4d"})
+	rec := httptest.NewRecorder()
+	h.Resume(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Resume(failed run) status = %d, want 202", rec.Code)
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp["task_id"] != "msft" || mm.resumed != "msft-20260102T150405Z-1a2b3c4d" {
+		t.Errorf("Resume(failed run) = %v, resumed %q; want msft resumed from its run", resp, mm.resumed)
+	}
+
+	mm.resumeErr = fmt.Errorf("%w: it is completed", tasks.ErrNotResumable)
+	rec = httptest.NewRecorder()
+	h.Resume(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Resume(completed run) status = %d, want 409", rec.Code)
+	}
+}
+
+func TestDeadLetters_ListRetryDelete(t *testing.T) {
+	mm := newMockManager()
+	mm.dead["aapl"] = &tasks.DeadLetter{TaskID: "aapl", Attempts: 3, Failures: []tasks.Failure{{Attempt: 3, Error: "boom"}}}
+	mm.dead["msft"] = &tasks.DeadLetter{TaskID: "msft", Attempts: 3}
+	h := handlers.NewTaskHandler(config.Load(), mm)
+
+	rec := httptest.NewRecorder()
+	h.ListDeadLetters(rec, httptest.NewRequest(http.MethodGet, "/tasks/dead-letter", nil))
+	var list struct {
+		Total int `json:"total"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || list.Total != 2 {
+		t.Fatalf("ListDeadLetters = %d with %d entries, want 200 with 2", rec.Code, list.Total)
+	}
+
+	rec = httptest.NewRecorder()
+	h.RetryDeadLetter(rec, chiRequest(http.MethodPost, "/tasks/dead-letter/AAPL/retry", map[string]string{"task_id": "AAPL"}))
+	if rec.Code != http.StatusAccepted || mm.dead["aapl"] != nil {
+		t.Errorf("RetryDeadLetter(aapl) = %d, want 202 and aapl off the list", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.RetryDeadLetter(rec, chiRequest(http.MethodPost, "/tasks/dead-letter/aapl/retry", map[string]string{"task_id": "aapl"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("RetryDeadLetter(gone) = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.DeleteDeadLetter(rec, chiRequest(http.MethodDelete, "/tasks/dead-letter/msft", map[string]string{"task_id": "msft"}))
+	if rec.Code != http.StatusOK || len(mm.dead) != 0 {
+		t.Errorf("DeleteDeadLetter(msft) = %d, want 200 and an empty list", rec.Code)
+	}
+}
@@ -2,11 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
 )
@@ -52,6 +55,22 @@ func (h *TrainHandler) TrainParent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Body is optional; it only sets the queue priority
+	var req struct {
+		Priority string `json:"priority"`
+	}
+	if r.Body != nil {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	priority, err := tasks.ParsePriority(req.Priority)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Check if already running
 	if h.taskManager != nil && h.taskManager.IsRunning(taskID) {
 		w.Header().Set("Content-Type", "application/json")
@@ -64,8 +83,16 @@ func (h *TrainHandler) TrainParent(w http.ResponseWriter, r *http.Request) {
 
 	// Start training
 	if h.taskManager != nil {
-		started, _ := h.taskManager.StartTrainParent()
-		if !started {
+		outcome, err := h.taskManager.StartTrainParent(priority)
+		if err != nil {
+			respondError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		switch {
+		case outcome == tasks.OutcomeQueued || h.isQueued(taskID):
+			h.respondQueued(w, taskID, "")
+			return
+		case outcome == tasks.OutcomeActive:
 			w.Header().Set("Content-Type", "application/json")
 			json.NewEncoder(w).Encode(map[string]interface{}{
 				"status":  "already running",
@@ -82,11 +109,36 @@ func (h *TrainHandler) TrainParent(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// isQueued reports whether a task is waiting for a free worker
+func (h *TrainHandler) isQueued(taskID string) bool {
+	status := h.taskManager.GetStatus(taskID)
+	return status != nil && status.Status == "queued"
+}
+
+// respondQueued reports a task waiting for a free worker with its position
+func (h *TrainHandler) respondQueued(w http.ResponseWriter, taskID, detail string) {
+	response := map[string]interface{}{
+		"status":  "queued",
+		"task_id": taskID,
+	}
+	if status := h.taskManager.GetStatus(taskID); status != nil && status.QueuePosition > 0 {
+		response["queue_position"] = status.QueuePosition
+	}
+	if detail != "" {
+		response["detail"] = detail
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 // TrainChild handles POST /train-child
 func (h *TrainHandler) TrainChild(w http.ResponseWriter, r *http.Request) {
 	// Parse request
 	var req struct {
-		Ticker string `json:"ticker"`
+		Ticker   string   `json:"ticker"`
+		Priority string   `json:"priority"`
+		Then     []string `json:"then"` // actions to run after training, e.g. predict-child
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		w.Header().Set("Content-Type", "application/json")
@@ -107,28 +159,19 @@ func (h *TrainHandler) TrainChild(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	taskID := strings.ToLower(ticker)
+	priority, err := tasks.ParsePriority(req.Priority)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	// Check if parent model exists
-	if !h.modelExists("parent", "parent") {
-		// Check if parent is already training
-		if h.taskManager != nil {
-			parentStatus := h.taskManager.GetStatus("parent_training")
-			if parentStatus == nil || parentStatus.Status != "completed" {
-				h.taskManager.StartTrainParent()
-				if h.taskManager.IsRunning("parent_training") {
-					w.Header().Set("Content-Type", "application/json")
-					json.NewEncoder(w).Encode(map[string]interface{}{
-						"status":  "started_parent",
-						"task_id": "parent_training",
-						"detail":  "Parent model missing. Training parent first.",
-					})
-					return
-				}
-			}
-		}
+	if err := tasks.ValidateFollowUps(req.Then); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
+	taskID := strings.ToLower(ticker)
+
 	// Check if child model already exists
 	if h.modelExists(ticker, "child") {
 		w.Header().Set("Content-Type", "application/json")
@@ -151,14 +194,123 @@ func (h *TrainHandler) TrainChild(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Start training (with chain prediction)
+	// A missing parent model is trained first, as the pipeline's first step
+	parentMissing := !h.modelExists("parent", "parent")
+	detail := ""
+	if parentMissing {
+		detail = "Parent model missing. Training parent first."
+	}
+
+	// Start training, or queue it behind busy workers
 	if h.taskManager != nil {
-		h.taskManager.StartTrainChild(taskID, nil)
+		steps := tasks.ChildPipeline(parentMissing, req.Then...)
+		outcome, err := h.taskManager.StartTrainChild(taskID, priority, steps)
+		if errors.Is(err, tasks.ErrInvalidPipeline) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			respondError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		switch {
+		case outcome == tasks.OutcomeQueued || h.isQueued(taskID):
+			h.respondQueued(w, taskID, detail)
+			return
+		case outcome == tasks.OutcomeActive:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":  "running",
+				"task_id": taskID,
+				"detail":  "Training already in progress",
+			})
+			return
+		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	response := map[string]interface{}{
 		"status":  "started",
 		"task_id": taskID,
-	})
+	}
+	if detail != "" {
+		response["detail"] = detail
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// TrainBatch handles POST /train-child/batch: it trains a child model for
+// each ticker through the training queue and returns a batch ID to follow
+// them with GET /train-child/batch/{batch_id}
+func (h *TrainHandler) TrainBatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Tickers  []string `json:"tickers"`
+		Priority string   `json:"priority"`
+		Then     []string `json:"then"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	priority, err := tasks.ParsePriority(req.Priority)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Tickers whose model exists are reported, not retrained
+	spec := tasks.BatchSpec{Priority: priority, Then: req.Then}
+	seen := make(map[string]bool)
+	for _, ticker := range req.Tickers {
+		ticker = strings.TrimSpace(strings.ToUpper(ticker))
+		if ticker == "" || seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+		if h.modelExists(ticker, "child") {
+			spec.Existing = append(spec.Existing, ticker)
+		} else {
+			spec.Tickers = append(spec.Tickers, ticker)
+		}
+	}
+	if len(seen) == 0 {
+		respondError(w, http.StatusBadRequest, "tickers is required")
+		return
+	}
+
+	if h.taskManager == nil {
+		respondError(w, http.StatusServiceUnavailable, "Task manager unavailable")
+		return
+	}
+
+	// The parent model is checked once for the whole batch
+	spec.TrainParent = !h.modelExists("parent", "parent")
+
+	batch, err := h.taskManager.StartTrainBatch(spec)
+	switch {
+	case errors.Is(err, tasks.ErrInvalidBatch), errors.Is(err, tasks.ErrInvalidPipeline):
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		respondError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusAccepted, batch)
+}
+
+// GetBatch handles GET /train-child/batch/{batch_id}
+func (h *TrainHandler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	batchID := chi.URLParam(r, "batch_id")
+
+	var batch *tasks.Batch
+	if h.taskManager != nil {
+		batch = h.taskManager.GetBatch(batchID)
+	}
+	if batch == nil {
+		respondError(w, http.StatusNotFound, "Batch '"+batchID+"' not found.")
+		return
+	}
+	respondJSON(w, http.StatusOK, batch)
 }
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -59,6 +61,28 @@ func TestTrainParent_AlreadyRunning(t *testing.T) {
 	}
 }
 
+func TestTrainParent_InvalidJSON(t *testing.T) {
+	cfg := config.Load()
+	cfg.ParentDir = t.TempDir()
+
+	h := handlers.NewTrainHandler(cfg, newMockManager())
+
+	req := httptest.NewRequest(http.MethodPost, "/train-parent",
+		strings.NewReader(`{"priority":`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.TrainParent(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("TrainParent(bad JSON) status = %d, want 400", rec.Code)
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp["task_id"] != nil {
+		t.Errorf("TrainParent(bad JSON) queued task %v, want nothing queued", resp["task_id"])
+	}
+}
+
 // ── TrainChild ────────────────────────────────────────────────────────────
 
 func TestTrainChild_MissingTicker(t *testing.T) {
@@ -126,9 +150,6 @@ func TestTrainChild_StartsTraining(t *testing.T) {
 	cfg.ParentDir = t.TempDir()  // empty → no parent model
 
 	mm := newMockManager()
-	// Simulate parent completed so TrainChild doesn't redirect to parent
-	mm.statuses["parent_training"] = &tasks.TaskStatus{Status: "completed"}
-
 	h := handlers.NewTrainHandler(cfg, mm)
 
 	req := httptest.NewRequest(http.MethodPost, "/train-child",
@@ -148,4 +169,158 @@ func TestTrainChild_StartsTraining(t *testing.T) {
 	if resp["task_id"] != "msft" {
 		t.Errorf("TrainChild(start) task_id = %v, want \"msft\"", resp["task_id"])
 	}
+
+	// The parent model is missing, so the pipeline trains it first
+	if len(mm.steps) != 2 || mm.steps[0].Action != tasks.ActionTrainParent || mm.steps[1].Action != tasks.ActionTrainChild {
+		t.Errorf("TrainChild(start) pipeline = %+v, want train-parent then train-child", mm.steps)
+	}
+}
+
+func TestTrainChild_FollowUps(t *testing.T) {
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.ParentDir = t.TempDir()
+	os.WriteFile(filepath.Join(cfg.ParentDir, cfg.ParentTicker+"_parent_model.pt"), nil, 0644)
+
+	mm := newMockManager()
+	h := handlers.NewTrainHandler(cfg, mm)
+
+	req := httptest.NewRequest(http.MethodPost, "/train-child",
+		strings.NewReader(`{"ticker":"MSFT","then":["predict-child","monitor-ticker"]}`))
+	rec := httptest.NewRecorder()
+	h.TrainChild(rec, req)
+
+	var actions []string
+	for _, step := range mm.steps {
+		actions = append(actions, step.Action)
+	}
+	if got := strings.Join(actions, ","); got != "train-child,predict-child,monitor-ticker" {
+		t.Errorf("TrainChild(then) pipeline = %s, want train-child,predict-child,monitor-ticker", got)
+	}
+}
+
+func TestTrainChild_InvalidFollowUp(t *testing.T) {
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.ParentDir = t.TempDir()
+
+	mm := newMockManager()
+	h := handlers.NewTrainHandler(cfg, mm)
+
+	req := httptest.NewRequest(http.MethodPost, "/train-child",
+		strings.NewReader(`{"ticker":"MSFT","then":["train-parent"]}`))
+	rec := httptest.NewRecorder()
+	h.TrainChild(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("TrainChild(then train-parent) status = %d, want 400", rec.Code)
+	}
+	if mm.steps != nil {
+		t.Errorf("TrainChild(then train-parent) started %+v", mm.steps)
+	}
+}
+
+func TestTrainChild_Queued(t *testing.T) {
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.ParentDir = t.TempDir()
+
+	mm := newMockManager()
+	mm.outcome = tasks.OutcomeQueued
+	mm.statuses["msft"] = &tasks.TaskStatus{Status: "queued", QueuePosition: 3}
+
+	h := handlers.NewTrainHandler(cfg, mm)
+
+	req := httptest.NewRequest(http.MethodPost, "/train-child",
+		strings.NewReader(`{"ticker":"MSFT","priority":"high"}`))
+	rec := httptest.NewRecorder()
+	h.TrainChild(rec, req)
+
+	var resp map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp["status"] != "queued" {
+		t.Errorf("TrainChild(queued) status = %v, want \"queued\"", resp["status"])
+	}
+	if resp["queue_position"] != float64(3) {
+		t.Errorf("TrainChild(queued) queue_position = %v, want 3", resp["queue_position"])
+	}
+}
+
+func TestTrainChild_InvalidPriority(t *testing.T) {
+	cfg := config.Load()
+	h := handlers.NewTrainHandler(cfg, newMockManager())
+
+	req := httptest.NewRequest(http.MethodPost, "/train-child",
+		strings.NewReader(`{"ticker":"MSFT","priority":"urgent"}`))
+	rec := httptest.NewRecorder()
+	h.TrainChild(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("TrainChild(bad priority) status = %d, want 400", rec.Code)
+	}
+}
+
+// ── TrainBatch ────────────────────────────────────────────────────────────
+
+func TestTrainBatch_SplitsExistingModels(t *testing.T) {
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.ParentDir = t.TempDir() // empty → parent trained once for the batch
+	os.MkdirAll(filepath.Join(cfg.OutputsDir, "AAPL"), 0755)
+	os.WriteFile(filepath.Join(cfg.OutputsDir, "AAPL", "AAPL_child_model.pt"), nil, 0644)
+
+	mm := newMockManager()
+	h := handlers.NewTrainHandler(cfg, mm)
+
+	req := httptest.NewRequest(http.MethodPost, "/train-child/batch",
+		strings.NewReader(`{"tickers":["aapl"," msft ","MSFT","nvda"],"priority":"low"}`))
+	rec := httptest.NewRecorder()
+	h.TrainBatch(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("TrainBatch status = %d, want 202", rec.Code)
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp["batch_id"] != "batch-1" {
+		t.Errorf("TrainBatch batch_id = %v, want batch-1", resp["batch_id"])
+	}
+
+	spec := mm.batch
+	if spec == nil || strings.Join(spec.Tickers, ",") != "MSFT,NVDA" || strings.Join(spec.Existing, ",") != "AAPL" {
+		t.Fatalf("TrainBatch spec = %+v, want MSFT,NVDA to train and AAPL existing", spec)
+	}
+	if !spec.TrainParent || spec.Priority != tasks.PriorityLow {
+		t.Errorf("TrainBatch spec = %+v, want low priority with parent training", spec)
+	}
+}
+
+func TestTrainBatch_NoTickers(t *testing.T) {
+	h := handlers.NewTrainHandler(config.Load(), newMockManager())
+
+	req := httptest.NewRequest(http.MethodPost, "/train-child/batch", strings.NewReader(`{"tickers":[" "]}`))
+	rec := httptest.NewRecorder()
+	h.TrainBatch(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("TrainBatch(no tickers) status = %d, want 400", rec.Code)
+	}
+}
+
+func TestGetBatch(t *testing.T) {
+	mm := newMockManager()
+	mm.batches["batch-1"] = &tasks.Batch{BatchID: "batch-1", Status: "partial"}
+	h := handlers.NewTrainHandler(config.Load(), mm)
+
+	rec := httptest.NewRecorder()
+	h.GetBatch(rec, chiRequest(http.MethodGet, "/train-child/batch/batch-1", map[string]string{"batch_id": "batch-1"}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GetBatch status = %d, want 200", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.GetBatch(rec, chiRequest(http.MethodGet, "/train-child/batch/nope", map[string]string{"batch_id": "nope"}))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("GetBatch(unknown) status = %d, want 404", rec.Code)
+	}
 }
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/warmup"
+)
+
+// WarmupHandler handles the cache warm-up endpoints
+type WarmupHandler struct {
+	warmer *warmup.Warmer
+}
+
+// NewWarmupHandler creates a new warm-up handler
+func NewWarmupHandler(warmer *warmup.Warmer) *WarmupHandler {
+	return &WarmupHandler{warmer: warmer}
+}
+
+// Warm handles POST /system/cache/warm. The body is optional; without
+// tickers the configured targets are warmed.
+func (h *WarmupHandler) Warm(w http.ResponseWriter, r *http.Request) {
+	if h.warmer == nil {
+		respondError(w, http.StatusServiceUnavailable, "Cache warm-up not available")
+		return
+	}
+
+	var req struct {
+		Tickers []string `json:"tickers"`
+	}
+	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, h.warmer.Warm(warmup.TriggerManual, req.Tickers))
+}
+
+// List handles GET /system/cache/warm
+func (h *WarmupHandler) List(w http.ResponseWriter, r *http.Request) {
+	if h.warmer == nil {
+		respondError(w, http.StatusServiceUnavailable, "Cache warm-up not available")
+		return
+	}
+
+	runs := h.warmer.Runs()
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"targets": h.warmer.Targets(),
+		"runs":    runs,
+		"count":   len(runs),
+	})
+}
+
+// Get handles GET /system/cache/warm/{id}
+func (h *WarmupHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if h.warmer == nil {
+		respondError(w, http.StatusServiceUnavailable, "Cache warm-up not available")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	run := h.warmer.Get(id)
+	if run == nil {
+		respondError(w, http.StatusNotFound, "Warm-up run "+id+" not found")
+		return
+	}
+	respondJSON(w, http.StatusOK, run)
+}
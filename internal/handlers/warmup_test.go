@@ -0,0 +1,92 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/handlers"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/cache"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/warmup"
+)
+
+func TestWarmup_WarmAndProgress(t *testing.T) {
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.SyntheticLatencyMs, cfg.SyntheticFailureRate = 0, 0
+	warmer := warmup.New(cfg, cache.NewCache(cfg, nil, nil, nil, time.Hour), python.NewSyntheticRunner(cfg), nil)
+	defer warmer.Close()
+	h := handlers.NewWarmupHandler(warmer)
+
+	rec := httptest.NewRecorder()
+	h.Warm(rec, httptest.NewRequest(http.MethodPost, "/system/cache/warm", strings.NewReader(`{"tickers":["aapl","msft"]}`)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Warm status = %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+	var run warmup.Run
+	json.Unmarshal(rec.Body.Bytes(), &run)
+	if run.ID == "" || run.Trigger != warmup.TriggerManual || run.Total != 2 {
+		t.Fatalf("Warm = %s, want a manual run over 2 tickers", rec.Body.String())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec = httptest.NewRecorder()
+		h.Get(rec, chiRequest(http.MethodGet, "/system/cache/warm/"+run.ID, map[string]string{"id": run.ID}))
+		json.Unmarshal(rec.Body.Bytes(), &run)
+		if rec.Code == http.StatusOK && run.Status == "completed" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Get = %d %s, want the run completed", rec.Code, rec.Body.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if run.Counts[warmup.StateWarmed] != 2 {
+		t.Errorf("run counts = %v, want both tickers warmed", run.Counts)
+	}
+
+	// An empty body warms the default targets
+	rec = httptest.NewRecorder()
+	h.Warm(rec, httptest.NewRequest(http.MethodPost, "/system/cache/warm", nil))
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Warm(no body) status = %d, want 202", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.List(rec, httptest.NewRequest(http.MethodGet, "/system/cache/warm", nil))
+	var list struct {
+		Runs  []warmup.Run `json:"runs"`
+		Count int          `json:"count"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if list.Count != 2 || len(list.Runs) != 2 {
+		t.Errorf("List = %s, want both runs", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.Get(rec, chiRequest(http.MethodGet, "/system/cache/warm/nope", map[string]string{"id": "nope"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Get(unknown) status = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Warm(rec, httptest.NewRequest(http.MethodPost, "/system/cache/warm", strings.NewReader(`{`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Warm(bad JSON) status = %d, want 400", rec.Code)
+	}
+}
+
+func TestWarmup_Unavailable(t *testing.T) {
+	h := handlers.NewWarmupHandler(nil)
+	rec := httptest.NewRecorder()
+	h.Warm(rec, httptest.NewRequest(http.MethodPost, "/system/cache/warm", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Warm(nil warmer) status = %d, want 503", rec.Code)
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/webhooks"
+)
+
+// Delivery log paging for GET /webhooks/{id}/deliveries
+const (
+	defaultDeliveriesLimit = 20
+	maxDeliveriesLimit     = 100
+)
+
+// WebhookHandler handles the webhook subscription endpoints
+type WebhookHandler struct {
+	webhooks *webhooks.Dispatcher
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(d *webhooks.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{webhooks: d}
+}
+
+// List handles GET /webhooks
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.webhooks.List()
+	if err != nil {
+		respondError(w, http.StatusServiceUnavailable, "Failed to read webhooks: "+err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"webhooks": subs,
+		"total":    len(subs),
+	})
+}
+
+// Create handles POST /webhooks
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var spec webhooks.Spec
+	if err := decodeJSON(r, &spec); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	sub, err := h.webhooks.Create(spec)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, sub)
+}
+
+// Get handles GET /webhooks/{id}
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToLower(chi.URLParam(r, "id"))
+
+	sub, err := h.webhooks.Get(id)
+	if err != nil {
+		respondError(w, http.StatusServiceUnavailable, "Failed to read webhook: "+err.Error())
+		return
+	}
+	if sub == nil {
+		respondError(w, http.StatusNotFound, "Webhook '"+id+"' not found.")
+		return
+	}
+	respondJSON(w, http.StatusOK, sub)
+}
+
+// Update handles PUT /webhooks/{id}
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToLower(chi.URLParam(r, "id"))
+
+	var spec webhooks.Spec
+	if err := decodeJSON(r, &spec); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	sub, err := h.webhooks.Update(id, spec)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, sub)
+}
+
+// Delete handles DELETE /webhooks/{id}
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToLower(chi.URLParam(r, "id"))
+
+	deleted, err := h.webhooks.Delete(id)
+	if err != nil {
+		respondError(w, http.StatusServiceUnavailable, "Failed to delete webhook: "+err.Error())
+		return
+	}
+	if !deleted {
+		respondError(w, http.StatusNotFound, "Webhook '"+id+"' not found.")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{
+		"status": "deleted",
+		"id":     id,
+	})
+}
+
+// Deliveries handles GET /webhooks/{id}/deliveries
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToLower(chi.URLParam(r, "id"))
+
+	limit, err := parseIntParam(r.URL.Query().Get("limit"), defaultDeliveriesLimit, 1, maxDeliveriesLimit)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "limit: "+err.Error())
+		return
+	}
+
+	deliveries, err := h.webhooks.Deliveries(id, limit)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"id":         id,
+		"deliveries": deliveries,
+		"total":      len(deliveries),
+	})
+}
+
+// Test handles POST /webhooks/{id}/test
+func (h *WebhookHandler) Test(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToLower(chi.URLParam(r, "id"))
+
+	deliveryID, err := h.webhooks.Test(id)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusAccepted, map[string]string{
+		"status":      "sent",
+		"event":       webhooks.EventPing,
+		"delivery_id": deliveryID,
+		"detail":      "See GET /webhooks/" + id + "/deliveries for the outcome.",
+	})
+}
+
+// respondWebhookError maps a webhook error to an HTTP status code
+func respondWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, webhooks.ErrInvalid):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, webhooks.ErrNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	default:
+		respondError(w, http.StatusServiceUnavailable, err.Error())
+	}
+}
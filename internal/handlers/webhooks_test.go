@@ -0,0 +1,121 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/handlers"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/webhooks"
+)
+
+func newWebhookHandler(t *testing.T) *handlers.WebhookHandler {
+	t.Helper()
+	d := webhooks.New(config.Load(), nil, nil)
+	t.Cleanup(d.Close)
+	return handlers.NewWebhookHandler(d)
+}
+
+func TestWebhooks_CRUD(t *testing.T) {
+	h := newWebhookHandler(t)
+
+	rec := httptest.NewRecorder()
+	h.Create(rec, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url":"not a url"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Create(bad url) status = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Create(rec, httptest.NewRequest(http.MethodPost, "/webhooks",
+		strings.NewReader(`{"url":"https://example.com/hook","events":["task.completed"]}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Create status = %d, want 201: %s", rec.Code, rec.Body.String())
+	}
+	var created map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	id, _ := created["id"].(string)
+	if id == "" || created["secret"] == nil {
+		t.Fatalf("Create = %v, want an id and a secret", created)
+	}
+	params := map[string]string{"id": id}
+
+	rec = httptest.NewRecorder()
+	h.Update(rec, chiRequestBody(http.MethodPut, "/webhooks/"+id, params, `{"url":"https://example.com/other","enabled":false}`))
+	var updated map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &updated)
+	if rec.Code != http.StatusOK || updated["enabled"] != false || updated["secret"] != nil {
+		t.Errorf("Update = %d %v, want disabled, secret not shown", rec.Code, updated)
+	}
+
+	rec = httptest.NewRecorder()
+	h.List(rec, httptest.NewRequest(http.MethodGet, "/webhooks", nil))
+	var list map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if list["total"] != float64(1) || strings.Contains(rec.Body.String(), `"secret"`) {
+		t.Errorf("List = %s, want one subscription without its secret", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.Delete(rec, chiRequest(http.MethodDelete, "/webhooks/"+id, params))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Delete status = %d, want 200", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.Get(rec, chiRequest(http.MethodGet, "/webhooks/"+id, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Get after delete status = %d, want 404", rec.Code)
+	}
+}
+
+func TestWebhooks_TestLogsDelivery(t *testing.T) {
+	h := newWebhookHandler(t)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(webhooks.HeaderSignature) == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer endpoint.Close()
+
+	rec := httptest.NewRecorder()
+	h.Create(rec, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url":"`+endpoint.URL+`"}`)))
+	var created map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	id, _ := created["id"].(string)
+	params := map[string]string{"id": id}
+
+	rec = httptest.NewRecorder()
+	h.Test(rec, chiRequest(http.MethodPost, "/webhooks/"+id+"/test", params))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Test status = %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		rec = httptest.NewRecorder()
+		h.Deliveries(rec, chiRequest(http.MethodGet, "/webhooks/"+id+"/deliveries", params))
+		var resp struct {
+			Deliveries []webhooks.Delivery `json:"deliveries"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if len(resp.Deliveries) == 1 {
+			if d := resp.Deliveries[0]; d.Event != webhooks.EventPing || d.Status != "delivered" {
+				t.Errorf("delivery = %+v, want a delivered ping", d)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Deliveries = %s, want the ping", rec.Body.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Deliveries(rec, chiRequest(http.MethodGet, "/webhooks/missing/deliveries", map[string]string{"id": "missing"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Deliveries(missing) status = %d, want 404", rec.Code)
+	}
+}
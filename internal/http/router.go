@@ -1,6 +1,11 @@
 package http
 
 import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -12,9 +17,13 @@ import (
 	"github.com/shrithkshahapure/stock-agent-ops/internal/metrics"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/middleware"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/services/cache"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/calendar"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
 	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/scheduler"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/warmup"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/webhooks"
 )
 
 // Server holds dependencies for the HTTP server
@@ -24,9 +33,14 @@ type Server struct {
 	metrics     *metrics.Metrics
 	registry    *prometheus.Registry
 	router      *chi.Mux
-	runner      *python.Runner
+	runner      python.RunnerInterface
+	executions  python.ExecutionStore
 	taskManager *tasks.Manager
+	scheduler   *scheduler.Scheduler
+	webhooks    *webhooks.Dispatcher
+	calendar    *calendar.Calendar
 	cache       *cache.Cache
+	warmer      *warmup.Warmer
 }
 
 // NewServer creates a new HTTP server
@@ -41,14 +55,59 @@ func NewServer(cfg *config.Config, redis *redisclient.Client, m *metrics.Metrics
 		registry = metricsInstance.Registry()
 	}
 
-	// Create Python runner
-	runner := python.NewRunner(cfg)
+	// Create Python runner: remote workers, synthetic results, a warm local
+	// pool, or one local process per call. Execution logs are archived
+	// wherever the commands run.
+	var runner python.RunnerInterface
+	var executions python.ExecutionStore = python.NewArchive(cfg)
+	switch {
+	case cfg.ExecutionMode == "synthetic":
+		runner = python.NewSyntheticRunner(cfg)
+	case cfg.ExecutionMode == "remote":
+		remote := python.NewRemoteRunner(cfg)
+		remote.Start()
+		runner = remote
+		executions = remote
+	case cfg.PythonPool:
+		pool := python.NewPool(cfg)
+		pool.Start()
+		runner = pool
+	default:
+		runner = python.NewRunner(cfg)
+	}
+	// Load the NYSE trading calendar; cached predictions expire at the next
+	// session close, or after 24 hours without it
+	cal, err := calendar.NYSE()
+	if err != nil {
+		log.Printf("Trading calendar unavailable: %v", err)
+	}
+
+	// Create cache service with its in-process tier
+	cacheService := cache.NewCache(cfg, redis, metricsInstance, cal, 24*time.Hour)
+	cacheService.Start()
+
+	// Concurrent predictions of the same ticker by the same model share one
+	// execution
+	runner = python.NewCoalescer(runner, metricsInstance, cacheService.ModelVersion)
+
+	// Create webhook dispatcher for task lifecycle notifications
+	hooks := webhooks.New(cfg, redis, metricsInstance)
 
-	// Create task manager
+	// Create cache warmer; it warms the configured tickers now and each
+	// ticker again once its training completes
+	warmer := warmup.New(cfg, cacheService, runner, metricsInstance)
+
+	// Create task manager; it resumes any jobs still queued in Redis
 	taskManager := tasks.NewManager(cfg, runner, redis, metricsInstance)
+	taskManager.RegisterAction(tasks.ActionWarmCache, warmCache(cacheService))
+	taskManager.OnEvent(hooks.PublishTask)
+	taskManager.OnEvent(warmer.OnTaskEvent)
+	taskManager.Start()
+	warmer.Start()
 
-	// Create cache service (24 hour TTL)
-	cacheService := cache.NewCache(redis, metricsInstance, 24*time.Hour)
+	// Create scheduler for recurring training and monitoring
+	schedules := scheduler.New(cfg, redis, taskManager, runner)
+	schedules.Start()
 
 	s := &Server{
 		cfg:         cfg,
@@ -57,8 +116,13 @@ func NewServer(cfg *config.Config, redis *redisclient.Client, m *metrics.Metrics
 		registry:    registry,
 		router:      chi.NewRouter(),
 		runner:      runner,
+		executions:  executions,
 		taskManager: taskManager,
+		scheduler:   schedules,
+		webhooks:    hooks,
+		calendar:    cal,
 		cache:       cacheService,
+		warmer:      warmer,
 	}
 
 	s.setupMiddleware()
@@ -67,6 +131,29 @@ func NewServer(cfg *config.Config, redis *redisclient.Client, m *metrics.Metrics
 	return s
 }
 
+// warmCache returns the pipeline action that caches the prediction made by
+// the step it depends on, so the next /predict-child is a cache hit. The
+// task manager only accepts warm-cache steps that depend on predict-child
+// steps, and this action refuses anything but a single prediction.
+func warmCache(c *cache.Cache) tasks.Action {
+	return func(_ context.Context, ticker string, inputs map[string]*python.Result) (*python.Result, error) {
+		if len(inputs) != 1 {
+			return nil, errors.New("warm-cache must depend on exactly one predict-child step")
+		}
+		var input *python.Result
+		for _, result := range inputs {
+			input = result
+		}
+		if input == nil || input.Data == nil {
+			return nil, errors.New("no prediction to cache")
+		}
+		if err := c.Set(ticker, input.Data); err != nil {
+			return nil, err
+		}
+		return &python.Result{Data: map[string]interface{}{"cached": strings.ToUpper(ticker)}}, nil
+	}
+}
+
 // setupMiddleware configures middleware stack
 func (s *Server) setupMiddleware() {
 	// CORS
@@ -91,12 +178,18 @@ func (s *Server) setupRoutes() {
 	// Create handlers
 	healthHandler := handlers.NewHealthHandler(s.cfg)
 	trainHandler := handlers.NewTrainHandler(s.cfg, s.taskManager)
-	predictHandler := handlers.NewPredictHandler(s.cfg, s.runner, s.cache, s.taskManager, s.metrics)
+	predictHandler := handlers.NewPredictHandler(s.cfg, s.runner, s.cache, s.taskManager, s.webhooks, s.metrics)
 	analyzeHandler := handlers.NewAnalyzeHandler(s.runner)
 	statusHandler := handlers.NewStatusHandler(s.cfg, s.taskManager)
+	taskHandler := handlers.NewTaskHandler(s.cfg, s.taskManager)
+	executionHandler := handlers.NewExecutionHandler(s.executions, s.taskManager)
 	monitorHandler := handlers.NewMonitorHandler(s.cfg, s.runner)
-	systemHandler := handlers.NewSystemHandler(s.cfg, s.redis, s.cache)
+	systemHandler := handlers.NewSystemHandler(s.cfg, s.redis, s.cache, s.runner)
 	outputsHandler := handlers.NewOutputsHandler(s.cfg)
+	scheduleHandler := handlers.NewScheduleHandler(s.scheduler)
+	webhookHandler := handlers.NewWebhookHandler(s.webhooks)
+	calendarHandler := handlers.NewCalendarHandler(s.calendar)
+	warmupHandler := handlers.NewWarmupHandler(s.warmer)
 
 	// Rate limiter
 	rateLimiter := middleware.NewRateLimiter(s.redis)
@@ -113,6 +206,8 @@ func (s *Server) setupRoutes() {
 	// Training endpoints (5/hour rate limit)
 	s.router.With(rateLimiter.Limit(5, time.Hour, "train_parent")).Post("/train-parent", trainHandler.TrainParent)
 	s.router.With(rateLimiter.Limit(5, time.Hour, "train_child")).Post("/train-child", trainHandler.TrainChild)
+	s.router.With(rateLimiter.Limit(5, time.Hour, "train_child_batch")).Post("/train-child/batch", trainHandler.TrainBatch)
+	s.router.Get("/train-child/batch/{batch_id}", trainHandler.GetBatch)
 
 	// Prediction endpoints (40/hour rate limit)
 	s.router.With(rateLimiter.Limit(40, time.Hour, "predict_parent")).Post("/predict-parent", predictHandler.PredictParent)
@@ -123,6 +218,38 @@ func (s *Server) setupRoutes() {
 
 	// Status
 	s.router.Get("/status/{task_id}", statusHandler.GetStatus)
+	s.router.Get("/status/{task_id}/logs", executionHandler.GetTaskLogs)
+
+	// Execution archive
+	s.router.Get("/executions/{id}", executionHandler.GetExecution)
+
+	// Task control
+	s.router.Get("/tasks", taskHandler.ListTasks)
+	s.router.Get("/tasks/{run_id}", taskHandler.GetTask)
+	s.router.Post("/tasks/{task_id}/cancel", taskHandler.Cancel)
+	s.router.Post("/tasks/{run_id}/resume", taskHandler.Resume)
+	s.router.Get("/tasks/dead-letter", taskHandler.ListDeadLetters)
+	s.router.Post("/tasks/dead-letter/{task_id}/retry", taskHandler.RetryDeadLetter)
+	s.router.Delete("/tasks/dead-letter/{task_id}", taskHandler.DeleteDeadLetter)
+
+	// Recurring schedules
+	s.router.Get("/schedules", scheduleHandler.List)
+	s.router.Post("/schedules", scheduleHandler.Create)
+	s.router.Get("/schedules/{id}", scheduleHandler.Get)
+	s.router.Put("/schedules/{id}", scheduleHandler.Update)
+	s.router.Delete("/schedules/{id}", scheduleHandler.Delete)
+
+	// Webhook subscriptions
+	s.router.Get("/webhooks", webhookHandler.List)
+	s.router.Post("/webhooks", webhookHandler.Create)
+	s.router.Get("/webhooks/{id}", webhookHandler.Get)
+	s.router.Put("/webhooks/{id}", webhookHandler.Update)
+	s.router.Delete("/webhooks/{id}", webhookHandler.Delete)
+	s.router.Get("/webhooks/{id}/deliveries", webhookHandler.Deliveries)
+	s.router.Post("/webhooks/{id}/test", webhookHandler.Test)
+
+	// Trading calendar
+	s.router.Get("/calendar", calendarHandler.Get)
 
 	// Monitoring
 	s.router.Post("/monitor/parent", monitorHandler.MonitorParent)
@@ -133,6 +260,9 @@ func (s *Server) setupRoutes() {
 	// System
 	s.router.Get("/system/logs", systemHandler.GetLogs)
 	s.router.Get("/system/cache", systemHandler.GetCache)
+	s.router.Post("/system/cache/warm", warmupHandler.Warm)
+	s.router.Get("/system/cache/warm", warmupHandler.List)
+	s.router.Get("/system/cache/warm/{id}", warmupHandler.Get)
 	s.router.Delete("/system/reset", systemHandler.Reset)
 
 	// Outputs
@@ -145,6 +275,19 @@ func (s *Server) Router() *chi.Mux {
 	return s.router
 }
 
+// Close releases resources held by the server, such as Python workers
+func (s *Server) Close() error {
+	s.scheduler.Close()
+	s.taskManager.Close()
+	s.webhooks.Close()
+	s.warmer.Close()
+	s.cache.Close()
+	if closer, ok := s.runner.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 // Metrics returns the metrics instance
 func (s *Server) Metrics() *metrics.Metrics {
 	return s.metrics
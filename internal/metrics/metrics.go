@@ -20,17 +20,30 @@ type Metrics struct {
 	RedisKeys prometheus.Gauge
 
 	// Training metrics
-	TrainingStatus   *prometheus.GaugeVec
-	TrainingMSE      prometheus.Gauge
-	TrainingDuration *prometheus.HistogramVec
+	TrainingStatus      *prometheus.GaugeVec
+	TrainingMSE         prometheus.Gauge
+	TrainingDuration    *prometheus.HistogramVec
+	TrainingProgress    *prometheus.GaugeVec
+	TrainingQueued      prometheus.Gauge
+	TrainingRetries     *prometheus.CounterVec
+	TrainingDeadLetters prometheus.Gauge
 
 	// Prediction metrics
-	PredictionTotal   *prometheus.CounterVec
-	PredictionLatency *prometheus.HistogramVec
+	PredictionTotal     *prometheus.CounterVec
+	PredictionLatency   *prometheus.HistogramVec
+	PredictionCoalesced *prometheus.CounterVec
 
 	// Cache metrics
-	CacheHit  *prometheus.CounterVec
-	CacheMiss *prometheus.CounterVec
+	CacheHit           *prometheus.CounterVec
+	CacheMiss          *prometheus.CounterVec
+	CacheLocalHit      *prometheus.CounterVec
+	CacheLocalMiss     *prometheus.CounterVec
+	CacheStaleHit      *prometheus.CounterVec
+	CacheRevalidations *prometheus.CounterVec
+	CacheWarmed        *prometheus.CounterVec
+
+	// Webhook metrics
+	WebhookDeliveries *prometheus.CounterVec
 }
 
 // New creates and registers all Prometheus metrics
@@ -77,6 +90,22 @@ func New(reg *prometheus.Registry) *Metrics {
 			Help:    "Training duration in seconds",
 			Buckets: prometheus.ExponentialBuckets(1, 2, 15), // 1s to ~9h
 		}, []string{"task_id"}),
+		TrainingProgress: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "training_progress_percent",
+			Help: "Estimated percent complete of a running training task",
+		}, []string{"task_id"}),
+		TrainingQueued: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "training_queue_depth",
+			Help: "Training jobs waiting for a free worker",
+		}),
+		TrainingRetries: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "training_retries_total",
+			Help: "Failed training runs scheduled for another attempt",
+		}, []string{"task_id"}),
+		TrainingDeadLetters: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "training_dead_letters",
+			Help: "Training tasks that failed on every attempt",
+		}),
 
 		// Prediction metrics
 		PredictionTotal: factory.NewCounterVec(prometheus.CounterOpts{
@@ -88,6 +117,10 @@ func New(reg *prometheus.Registry) *Metrics {
 			Help:    "Prediction latency",
 			Buckets: prometheus.DefBuckets,
 		}, []string{"type"}),
+		PredictionCoalesced: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "prediction_coalesced_total",
+			Help: "Prediction requests that shared another's execution, by where they were merged",
+		}, []string{"layer"}),
 
 		// Cache metrics
 		CacheHit: factory.NewCounterVec(prometheus.CounterOpts{
@@ -98,6 +131,32 @@ func New(reg *prometheus.Registry) *Metrics {
 			Name: "redis_cache_miss_total",
 			Help: "Cache misses",
 		}, []string{"key"}),
+		CacheLocalHit: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "local_cache_hit_total",
+			Help: "In-process cache hits",
+		}, []string{"key"}),
+		CacheLocalMiss: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "local_cache_miss_total",
+			Help: "In-process cache misses",
+		}, []string{"key"}),
+		CacheStaleHit: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_stale_hit_total",
+			Help: "Expired predictions served while being refreshed, by endpoint",
+		}, []string{"endpoint"}),
+		CacheRevalidations: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_revalidations_total",
+			Help: "Background refreshes of stale predictions by result",
+		}, []string{"result"}),
+		CacheWarmed: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_warm_total",
+			Help: "Tickers processed by cache warm-ups, by result",
+		}, []string{"result"}),
+
+		// Webhook metrics
+		WebhookDeliveries: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "webhook_deliveries_total",
+			Help: "Webhook delivery attempts by event and result",
+		}, []string{"event", "result"}),
 	}
 
 	return m
@@ -2,43 +2,191 @@ package cache
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"log"
 	"strings"
 	"time"
 
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
 	"github.com/shrithkshahapure/stock-agent-ops/internal/metrics"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/calendar"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/flight"
 	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
 )
 
-// Cache provides prediction caching functionality
+// Cache provides prediction caching functionality. Predictions live in
+// Redis; a small in-process LRU in front of it serves hot tickers without a
+// round trip, and is invalidated across instances over Redis pub/sub. Each
+// prediction is tagged with the child model that made it, so a retrained,
+// rolled back or deleted model stops its old predictions being served.
+// Predictions expire when the market next closes, as new bars arrive.
 type Cache struct {
-	redis   *redisclient.Client
-	metrics *metrics.Metrics
-	ttl     time.Duration
+	redis    *redisclient.Client
+	metrics  *metrics.Metrics
+	calendar *calendar.Calendar // predictions expire at the next session close
+	ttl      time.Duration      // how long they last without a calendar
+
+	local    *lru           // nil when the in-process tier is disabled
+	versions *modelVersions // nil leaves entries unversioned
+	origin   string         // tags this instance's invalidations and leases
+	stop     context.CancelFunc
+
+	flights flight.Group[*Entry] // predictions being made
+	lockTTL time.Duration        // lease on a ticker while predicting it
+	grace   time.Duration        // how long expired predictions are kept to serve stale
+	stale   StaleWindows         // how much of the grace period each endpoint uses
+}
+
+// Entry is a cached prediction. ModelVersion identifies the child model
+// that made it (empty when there was no model file, as in synthetic mode).
+type Entry struct {
+	Ticker       string                 `json:"ticker"`
+	ModelVersion string                 `json:"model_version"`
+	CachedAt     time.Time              `json:"cached_at"`
+	ExpiresAt    time.Time              `json:"expires_at"`
+	Stale        bool                   `json:"stale"`   // past ExpiresAt, within the grace period
+	Current      bool                   `json:"current"` // made by the model now on disk
+	Data         map[string]interface{} `json:"data,omitempty"`
+	Hit          bool                   `json:"-"` // served from the cache rather than just made
+}
+
+// record is how a prediction is stored in either tier
+type record struct {
+	ModelVersion string                 `json:"model_version"`
+	CachedAt     time.Time              `json:"cached_at"`
+	ExpiresAt    time.Time              `json:"expires_at"`
+	Data         map[string]interface{} `json:"data"`
+}
+
+// servable reports whether a record was made by the given model version
+// and is no more than maxStale past its expiry
+func (r *record) servable(version string, maxStale time.Duration) bool {
+	return r.ModelVersion == version && (r.ExpiresAt.IsZero() || time.Now().Before(r.ExpiresAt.Add(maxStale)))
 }
 
-// NewCache creates a new cache service
-func NewCache(redis *redisclient.Client, m *metrics.Metrics, ttl time.Duration) *Cache {
-	return &Cache{
-		redis:   redis,
-		metrics: m,
-		ttl:     ttl,
+// entry describes a record without its data
+func (r *record) entry(ticker string, current bool) *Entry {
+	return &Entry{
+		Ticker:       strings.ToUpper(ticker),
+		ModelVersion: r.ModelVersion,
+		CachedAt:     r.CachedAt,
+		ExpiresAt:    r.ExpiresAt,
+		Stale:        !r.ExpiresAt.IsZero() && !time.Now().Before(r.ExpiresAt),
+		Current:      current,
+	}
+}
+
+// hit describes a record served from the cache, with its data
+func (r *record) hit(ticker string) *Entry {
+	entry := r.entry(ticker, true)
+	entry.Data = r.Data
+	entry.Hit = true
+	return entry
+}
+
+// invalidation is published on redisclient.CacheInvalidateChannel when a
+// prediction changes; an empty Key drops every entry
+type invalidation struct {
+	Origin string `json:"origin"`
+	Key    string `json:"key,omitempty"`
+}
+
+// NewCache creates a new cache service. Predictions expire at cal's next
+// session close, or after ttl when cal is nil, and are kept for the stale
+// grace period after that.
+func NewCache(cfg *config.Config, redis *redisclient.Client, m *metrics.Metrics, cal *calendar.Calendar, ttl time.Duration) *Cache {
+	c := &Cache{
+		redis:    redis,
+		metrics:  m,
+		calendar: cal,
+		ttl:      ttl,
+		versions: newModelVersions(cfg.OutputsDir),
+		origin:   newOrigin(),
+		lockTTL:  time.Duration(cfg.PredictLockTTL) * time.Second,
+		grace:    time.Duration(cfg.CacheStaleGrace) * time.Second,
+	}
+	if cfg.CacheLocalMaxEntries > 0 && cfg.CacheLocalTTL > 0 {
+		c.local = newLRU(cfg.CacheLocalMaxEntries, time.Duration(cfg.CacheLocalTTL)*time.Second)
+	}
+	if c.grace < 0 {
+		c.grace = 0
+	}
+	stale, err := LoadStaleWindows(cfg)
+	if err != nil {
+		log.Printf("Warning: %v; serving stale predictions for the whole grace period", err)
+	}
+	c.stale = stale
+	return c
+}
+
+// StaleWindow returns how long past its expiry endpoint serves a prediction
+// while it is refreshed
+func (c *Cache) StaleWindow(endpoint string) time.Duration {
+	return c.stale.For(endpoint)
+}
+
+// Start subscribes to invalidations from other instances
+func (c *Cache) Start() {
+	if c.redis == nil || c.local == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := c.redis.Subscribe(ctx, redisclient.CacheInvalidateChannel, c.invalidate); err != nil {
+		cancel()
+		log.Printf("Cache invalidation subscription failed: %v", err)
+		return
+	}
+	c.stop = cancel
+}
+
+// Close stops listening for invalidations
+func (c *Cache) Close() {
+	if c.stop != nil {
+		c.stop()
 	}
 }
 
 // Get retrieves a cached value for a ticker
 func (c *Cache) Get(ticker string) (map[string]interface{}, bool) {
+	entry, found := c.Lookup(ticker, 0)
+	if !found {
+		return nil, false
+	}
+	return entry.Data, true
+}
+
+// Lookup retrieves a cached prediction with the model version that made
+// it. Entries from any other version than the model now on disk are
+// misses, and so are entries more than maxStale past their expiry.
+func (c *Cache) Lookup(ticker string, maxStale time.Duration) (*Entry, bool) {
+	key := redisclient.CacheKey(strings.ToLower(ticker))
+	version := c.modelVersion(ticker)
+
+	var gen uint64
+	if c.local != nil {
+		if rec, ok := c.local.get(key); ok && rec.servable(version, maxStale) {
+			if c.metrics != nil {
+				c.metrics.CacheLocalHit.WithLabelValues(key).Inc()
+			}
+			return rec.hit(ticker), true
+		}
+		if c.metrics != nil {
+			c.metrics.CacheLocalMiss.WithLabelValues(key).Inc()
+		}
+		gen = c.local.generation()
+	}
+
 	if c.redis == nil {
 		return nil, false
 	}
 
 	ctx := context.Background()
-	key := redisclient.CacheKey(strings.ToLower(ticker))
 
-	val, err := c.redis.Get(ctx, key)
-	if err != nil {
-		// Cache miss
+	rec, err := c.read(ctx, key)
+	if err != nil || rec == nil || !rec.servable(version, maxStale) {
+		// Cache miss, or a prediction from another model
 		if c.metrics != nil {
 			c.metrics.CacheMiss.WithLabelValues(key).Inc()
 		}
@@ -50,48 +198,84 @@ func (c *Cache) Get(ticker string) (map[string]interface{}, bool) {
 		c.metrics.CacheHit.WithLabelValues(key).Inc()
 	}
 
-	var data map[string]interface{}
-	if err := json.Unmarshal([]byte(val), &data); err != nil {
-		log.Printf("Failed to unmarshal cached value: %v", err)
-		return nil, false
+	if c.local != nil {
+		c.local.addIfCurrent(key, rec, gen)
 	}
-
-	return data, true
+	return rec.hit(ticker), true
 }
 
-// Set stores a value in the cache
+// Set stores a value in the cache, tagged with the model now on disk
 func (c *Cache) Set(ticker string, data map[string]interface{}) error {
-	if c.redis == nil {
-		return nil
-	}
+	_, err := c.store(ticker, data)
+	return err
+}
 
-	ctx := context.Background()
+// store caches a prediction. Redis keeps it for the grace period past its
+// expiry, so it can still be served stale while it is refreshed.
+func (c *Cache) store(ticker string, data map[string]interface{}) (*record, error) {
 	key := redisclient.CacheKey(strings.ToLower(ticker))
+	now := time.Now().UTC()
+	rec := &record{ModelVersion: c.modelVersion(ticker), CachedAt: now, ExpiresAt: c.expiry(now), Data: data}
 
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return err
+	if c.redis != nil {
+		ctx := context.Background()
+
+		jsonData, err := json.Marshal(rec)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.redis.Set(ctx, key, string(jsonData), rec.ExpiresAt.Sub(now)+c.grace); err != nil {
+			return nil, err
+		}
 	}
 
-	return c.redis.Set(ctx, key, string(jsonData), c.ttl)
+	if c.local != nil {
+		c.local.add(key, rec)
+	}
+	c.publish(key)
+	return rec, nil
 }
 
 // Delete removes a cached value
 func (c *Cache) Delete(ticker string) error {
+	key := redisclient.CacheKey(strings.ToLower(ticker))
+
+	if c.local != nil {
+		c.local.remove(key)
+	}
 	if c.redis == nil {
 		return nil
 	}
 
 	ctx := context.Background()
-	key := redisclient.CacheKey(strings.ToLower(ticker))
+	if err := c.redis.Del(ctx, key); err != nil {
+		return err
+	}
+	c.publish(key)
+	return nil
+}
 
-	return c.redis.Del(ctx, key)
+// Clear drops the in-process tier here and on every other instance, for
+// when Redis has been flushed
+func (c *Cache) Clear() {
+	if c.local != nil {
+		c.local.clear()
+	}
+	c.publish("")
 }
 
 // GetCachedTickers returns a list of all cached tickers
 func (c *Cache) GetCachedTickers() ([]string, error) {
 	if c.redis == nil {
-		return nil, nil
+		// Without Redis only the in-process tier holds predictions
+		if c.local == nil {
+			return nil, nil
+		}
+		var tickers []string
+		for _, entry := range c.local.list() {
+			tickers = append(tickers, tickerOf(entry.key))
+		}
+		return tickers, nil
 	}
 
 	ctx := context.Background()
@@ -104,15 +288,46 @@ func (c *Cache) GetCachedTickers() ([]string, error) {
 
 	// Extract ticker names from keys
 	tickers := make([]string, 0, len(keys))
-	prefix := "predict_child_"
 	for _, key := range keys {
-		ticker := strings.TrimPrefix(key, prefix)
-		tickers = append(tickers, strings.ToUpper(ticker))
+		tickers = append(tickers, tickerOf(key))
 	}
 
 	return tickers, nil
 }
 
+// Entries describes every cached prediction: the model version it came
+// from, when it was cached, and whether that is still the current model
+func (c *Cache) Entries() ([]*Entry, error) {
+	if c.redis == nil {
+		if c.local == nil {
+			return nil, nil
+		}
+		var entries []*Entry
+		for _, entry := range c.local.list() {
+			ticker := tickerOf(entry.key)
+			entries = append(entries, entry.record.entry(ticker, entry.record.ModelVersion == c.modelVersion(ticker)))
+		}
+		return entries, nil
+	}
+
+	ctx := context.Background()
+	keys, err := c.redis.Keys(ctx, "predict_child_*")
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(keys))
+	for _, key := range keys {
+		rec, err := c.read(ctx, key)
+		if err != nil || rec == nil {
+			continue // expired since KEYS, or unreadable
+		}
+		ticker := tickerOf(key)
+		entries = append(entries, rec.entry(ticker, rec.ModelVersion == c.modelVersion(ticker)))
+	}
+	return entries, nil
+}
+
 // GetForTicker retrieves the cached data for a specific ticker
 func (c *Cache) GetForTicker(ticker string) (map[string]interface{}, error) {
 	data, found := c.Get(ticker)
@@ -121,3 +336,85 @@ func (c *Cache) GetForTicker(ticker string) (map[string]interface{}, error) {
 	}
 	return data, nil
 }
+
+// read returns the record stored under a Redis key, or nil when there is
+// none. Values cached before predictions were versioned count as none.
+func (c *Cache) read(ctx context.Context, key string) (*record, error) {
+	val, err := c.redis.Get(ctx, key)
+	if err != nil {
+		return nil, nil
+	}
+
+	var rec record
+	if err := json.Unmarshal([]byte(val), &rec); err != nil {
+		log.Printf("Failed to unmarshal cached value: %v", err)
+		return nil, err
+	}
+	if rec.Data == nil {
+		return nil, nil
+	}
+	return &rec, nil
+}
+
+// expiry returns when a prediction made at now goes stale
+func (c *Cache) expiry(now time.Time) time.Time {
+	if c.calendar != nil {
+		return c.calendar.NextClose(now).UTC()
+	}
+	return now.Add(c.ttl)
+}
+
+// ModelVersion returns the version of a ticker's child model now on disk,
+// or "" if it has none or entries are unversioned
+func (c *Cache) ModelVersion(ticker string) string {
+	return c.modelVersion(ticker)
+}
+
+// modelVersion returns the version of a ticker's child model now on disk
+func (c *Cache) modelVersion(ticker string) string {
+	if c.versions == nil {
+		return ""
+	}
+	return c.versions.of(ticker)
+}
+
+// tickerOf returns the ticker a cache key belongs to
+func tickerOf(key string) string {
+	return strings.ToUpper(strings.TrimPrefix(key, "predict_child_"))
+}
+
+// publish tells other instances to drop their local copy of key
+func (c *Cache) publish(key string) {
+	if c.redis == nil || c.local == nil {
+		return
+	}
+	msg, _ := json.Marshal(invalidation{Origin: c.origin, Key: key})
+	if err := c.redis.Publish(context.Background(), redisclient.CacheInvalidateChannel, string(msg)); err != nil {
+		log.Printf("Failed to publish cache invalidation for %q: %v", key, err)
+	}
+}
+
+// invalidate handles an invalidation published by another instance
+func (c *Cache) invalidate(message string) {
+	var msg invalidation
+	if err := json.Unmarshal([]byte(message), &msg); err != nil {
+		log.Printf("Ignoring malformed cache invalidation %q: %v", message, err)
+		return
+	}
+	if msg.Origin == c.origin {
+		return
+	}
+	if msg.Key == "" {
+		c.local.clear()
+		return
+	}
+	c.local.remove(msg.Key)
+}
+
+// newOrigin returns a random ID that tells this instance's invalidations
+// apart from others', even when they share an INSTANCE_ID
+func newOrigin() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
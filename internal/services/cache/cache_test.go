@@ -1,7 +1,18 @@
 package cache
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/calendar"
+	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
 )
 
 // TestCacheGetWithNilRedis verifies that Get returns (nil, false) gracefully when Redis is nil.
@@ -54,3 +65,323 @@ func TestCacheGetForTickerWithNilRedis(t *testing.T) {
 		t.Errorf("GetForTicker(nil redis) = %v, want nil", data)
 	}
 }
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	l := newLRU(2, time.Minute)
+	l.add("a", &record{ModelVersion: "1"})
+	l.add("b", &record{ModelVersion: "2"})
+	l.get("a") // b is now the least recently used
+	l.add("c", &record{ModelVersion: "3"})
+
+	if _, ok := l.get("b"); ok {
+		t.Error("get(b) ok = true, want it evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := l.get(key); !ok {
+			t.Errorf("get(%s) ok = false, want it kept", key)
+		}
+	}
+}
+
+func TestLRU_Expires(t *testing.T) {
+	now := time.Now()
+	l := newLRU(10, 30*time.Second)
+	l.now = func() time.Time { return now }
+	l.add("a", &record{ModelVersion: "1"})
+
+	now = now.Add(29 * time.Second)
+	if _, ok := l.get("a"); !ok {
+		t.Error("get(a) before TTL ok = false, want true")
+	}
+	now = now.Add(time.Second)
+	if _, ok := l.get("a"); ok {
+		t.Error("get(a) at TTL ok = true, want expired")
+	}
+	if entries := l.list(); len(entries) != 0 {
+		t.Errorf("list() = %v, want none", entries)
+	}
+}
+
+func TestLRU_AddIfCurrent(t *testing.T) {
+	l := newLRU(10, time.Minute)
+	gen := l.generation()
+	l.remove("a") // invalidated while the Redis read was in flight
+	l.addIfCurrent("a", &record{ModelVersion: "stale"}, gen)
+	if _, ok := l.get("a"); ok {
+		t.Error("addIfCurrent after an invalidation stored the entry")
+	}
+
+	l.addIfCurrent("a", &record{ModelVersion: "fresh"}, l.generation())
+	if _, ok := l.get("a"); !ok {
+		t.Error("addIfCurrent with the current generation did not store the entry")
+	}
+}
+
+func TestCache_LocalTierWithoutRedis(t *testing.T) {
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.CacheLocalMaxEntries, cfg.CacheLocalTTL = 10, 30
+	c := NewCache(cfg, nil, nil, nil, time.Hour)
+
+	c.Set("aapl", map[string]interface{}{"ticker": "AAPL"})
+	if got, ok := c.Get("AAPL"); !ok || got["ticker"] != "AAPL" {
+		t.Errorf("Get(AAPL) = %v, %v, want the value just set", got, ok)
+	}
+	if tickers, _ := c.GetCachedTickers(); len(tickers) != 1 || tickers[0] != "AAPL" {
+		t.Errorf("GetCachedTickers() = %v, want [AAPL]", tickers)
+	}
+
+	c.Delete("AAPL")
+	if _, ok := c.Get("AAPL"); ok {
+		t.Error("Get(AAPL) after Delete ok = true, want false")
+	}
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.CacheLocalMaxEntries, cfg.CacheLocalTTL = 10, 30
+	c := NewCache(cfg, nil, nil, nil, time.Hour)
+	c.Set("aapl", map[string]interface{}{"ticker": "AAPL"})
+	c.Set("msft", map[string]interface{}{"ticker": "MSFT"})
+
+	// An instance ignores its own announcements
+	c.invalidate(`{"origin":"` + c.origin + `","key":"predict_child_aapl"}`)
+	if _, ok := c.Get("AAPL"); !ok {
+		t.Error("own invalidation dropped AAPL")
+	}
+
+	c.invalidate(`{"origin":"other","key":"predict_child_aapl"}`)
+	if _, ok := c.Get("AAPL"); ok {
+		t.Error("Get(AAPL) after another instance's invalidation ok = true, want false")
+	}
+	if _, ok := c.Get("MSFT"); !ok {
+		t.Error("invalidating AAPL dropped MSFT")
+	}
+
+	c.invalidate(`{"origin":"other"}`)
+	if _, ok := c.Get("MSFT"); ok {
+		t.Error("Get(MSFT) after a clear ok = true, want false")
+	}
+}
+
+func TestCache_ModelVersion(t *testing.T) {
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.CacheLocalMaxEntries, cfg.CacheLocalTTL = 10, 30
+	c := NewCache(cfg, nil, nil, nil, time.Hour)
+
+	model := filepath.Join(cfg.OutputsDir, "AAPL", "AAPL_child_model.pt")
+	os.MkdirAll(filepath.Dir(model), 0755)
+	os.WriteFile(model, []byte("weights v1"), 0644)
+
+	c.Set("aapl", map[string]interface{}{"ticker": "AAPL"})
+	entry, ok := c.Lookup("aapl", 0)
+	if !ok || entry.ModelVersion == "" || entry.Ticker != "AAPL" || entry.Data["ticker"] != "AAPL" {
+		t.Fatalf("Lookup(aapl) = %+v, %v, want AAPL from a versioned model", entry, ok)
+	}
+	v1 := entry.ModelVersion
+
+	// Retraining replaces the model: the old prediction is not served
+	os.WriteFile(model, []byte("weights v2, retrained"), 0644)
+	if _, ok := c.Get("AAPL"); ok {
+		t.Error("Get(AAPL) after a retrain ok = true, want false")
+	}
+	entries, _ := c.Entries()
+	if len(entries) != 1 || entries[0].ModelVersion != v1 || entries[0].Current || entries[0].Data != nil {
+		t.Errorf("Entries() = %+v, want one stale entry from %s without its data", entries, v1)
+	}
+
+	c.Set("aapl", map[string]interface{}{"ticker": "AAPL"})
+	if entry, ok := c.Lookup("aapl", 0); !ok || entry.ModelVersion == v1 {
+		t.Errorf("Lookup(aapl) = %+v, %v, want the retrained model's version", entry, ok)
+	}
+
+	// So does deleting it
+	os.Remove(model)
+	if _, ok := c.Get("AAPL"); ok {
+		t.Error("Get(AAPL) after the model was deleted ok = true, want false")
+	}
+}
+
+func TestCache_ExpiresAtNextClose(t *testing.T) {
+	cal, err := calendar.NYSE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.CacheLocalMaxEntries, cfg.CacheLocalTTL = 10, 30
+	c := NewCache(cfg, nil, nil, cal, time.Hour)
+
+	c.Set("aapl", map[string]interface{}{"ticker": "AAPL"})
+	entry, ok := c.Lookup("aapl", 0)
+	if !ok {
+		t.Fatal("Lookup(aapl) ok = false, want the value just set")
+	}
+	if want := cal.NextClose(entry.CachedAt); !entry.ExpiresAt.Equal(want) {
+		t.Errorf("ExpiresAt = %v, want the next close %v", entry.ExpiresAt, want)
+	}
+
+	// An entry past its expiry is not served, even from the local tier
+	c.local.add(redisclient.CacheKey("aapl"), &record{CachedAt: entry.CachedAt, ExpiresAt: time.Now().Add(-time.Second), Data: entry.Data})
+	if _, ok := c.Get("aapl"); ok {
+		t.Error("Get(aapl) past its expiry ok = true, want false")
+	}
+}
+
+func TestCache_FillSharesOnePrediction(t *testing.T) {
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.CacheLocalMaxEntries, cfg.CacheLocalTTL = 10, 30
+	c := NewCache(cfg, nil, nil, nil, time.Hour)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	predict := func(context.Context) (map[string]interface{}, error) {
+		calls.Add(1)
+		<-release
+		return map[string]interface{}{"ticker": "AAPL"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if entry, err := c.Fill(context.Background(), "aapl", EndpointPredictChild, predict); err != nil || entry.Data["ticker"] != "AAPL" {
+				t.Errorf("Fill = %+v, %v, want the prediction", entry, err)
+			}
+		}()
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for c.flights.Waiters(redisclient.CacheKey("aapl")+"@") < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("Fill callers did not share one prediction")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("predict ran %d times, want once", calls.Load())
+	}
+	// The result was cached, so the next fill does not predict
+	if _, err := c.Fill(context.Background(), "AAPL", EndpointPredictChild, predict); err != nil || calls.Load() != 1 {
+		t.Errorf("Fill after caching err = %v, predict calls = %d, want a cache hit", err, calls.Load())
+	}
+
+	// Errors reach every caller unchanged and are not cached
+	boom := errors.New("model missing")
+	if _, err := c.Fill(context.Background(), "MSFT", EndpointPredictChild, func(context.Context) (map[string]interface{}, error) { return nil, boom }); err != boom {
+		t.Errorf("Fill(failing) err = %v, want %v", err, boom)
+	}
+	if _, ok := c.Get("MSFT"); ok {
+		t.Error("a failed prediction was cached")
+	}
+}
+
+func TestCache_ServesStaleWhileRevalidating(t *testing.T) {
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.CacheLocalMaxEntries, cfg.CacheLocalTTL = 10, 30
+	cfg.CacheStaleGrace = 600
+	cfg.CacheStaleEndpoints = `{"system-cache":0}`
+	c := NewCache(cfg, nil, nil, nil, time.Hour)
+
+	expired := &record{CachedAt: time.Now().Add(-2 * time.Hour), ExpiresAt: time.Now().Add(-time.Minute), Data: map[string]interface{}{"price": 1.0}}
+	c.local.add(redisclient.CacheKey("aapl"), expired)
+
+	if _, ok := c.Lookup("aapl", c.StaleWindow(EndpointSystemCache)); ok {
+		t.Error("Lookup with no stale window ok = true, want false")
+	}
+
+	refreshed := make(chan struct{})
+	predict := func(context.Context) (map[string]interface{}, error) {
+		defer close(refreshed)
+		return map[string]interface{}{"price": 2.0}, nil
+	}
+	entry, err := c.Fill(context.Background(), "aapl", EndpointPredictChild, predict)
+	if err != nil || !entry.Hit || !entry.Stale || entry.Data["price"] != 1.0 {
+		t.Fatalf("Fill = %+v, %v, want the stale prediction at once", entry, err)
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stale prediction was not refreshed")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		entry, ok := c.Lookup("aapl", 0)
+		if ok && !entry.Stale && entry.Data["price"] == 2.0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Lookup after the refresh = %+v, %v, want the fresh prediction", entry, ok)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Past the grace period nothing is served
+	c.local.add(redisclient.CacheKey("msft"), &record{ExpiresAt: time.Now().Add(-time.Hour), Data: map[string]interface{}{"price": 1.0}})
+	if _, ok := c.Lookup("msft", c.StaleWindow(EndpointPredictChild)); ok {
+		t.Error("Lookup past the grace period ok = true, want false")
+	}
+}
+
+func TestCache_ServeRevalidatesWithoutPredictingOnMiss(t *testing.T) {
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.CacheLocalMaxEntries, cfg.CacheLocalTTL = 10, 30
+	cfg.CacheStaleGrace = 600
+	c := NewCache(cfg, nil, nil, nil, time.Hour)
+
+	var calls atomic.Int32
+	refreshed := make(chan struct{}, 1)
+	predict := func(context.Context) (map[string]interface{}, error) {
+		calls.Add(1)
+		refreshed <- struct{}{}
+		return map[string]interface{}{"price": 2.0}, nil
+	}
+
+	if _, ok := c.Serve("msft", EndpointSystemCache, predict); ok {
+		t.Error("Serve(uncached) ok = true, want false")
+	}
+
+	c.local.add(redisclient.CacheKey("aapl"), &record{ExpiresAt: time.Now().Add(-time.Minute), Data: map[string]interface{}{"price": 1.0}})
+	entry, ok := c.Serve("aapl", EndpointSystemCache, predict)
+	if !ok || !entry.Stale || entry.Data["price"] != 1.0 {
+		t.Fatalf("Serve(stale) = %+v, %v, want the stale prediction", entry, ok)
+	}
+	select {
+	case <-refreshed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stale prediction served by Serve was not refreshed")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("predict ran %d times, want once: for the stale entry only", calls.Load())
+	}
+}
+
+func TestLoadStaleWindows(t *testing.T) {
+	cfg := &config.Config{CacheStaleGrace: 600, CacheStaleEndpoints: `{"predict-child":60,"system-cache":7200}`}
+	w, err := LoadStaleWindows(cfg)
+	if err != nil {
+		t.Fatalf("LoadStaleWindows err = %v", err)
+	}
+	if w.For(EndpointPredictChild) != time.Minute || w.For(EndpointSystemCache) != 10*time.Minute || w.For("other") != 0 {
+		t.Errorf("windows = %v, want predict-child 1m and system-cache capped at the grace period", w)
+	}
+
+	for _, raw := range []string{`{`, `{"predict-parent":60}`, `{"predict-child":-1}`} {
+		w, err := LoadStaleWindows(&config.Config{CacheStaleGrace: 600, CacheStaleEndpoints: raw})
+		if err == nil {
+			t.Errorf("LoadStaleWindows(%s) err = nil, want error", raw)
+		}
+		if w.For(EndpointPredictChild) != 10*time.Minute {
+			t.Errorf("LoadStaleWindows(%s) did not fall back to the grace period", raw)
+		}
+	}
+}
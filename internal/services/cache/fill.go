@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
+)
+
+// awaitInterval is how often an instance waiting on another's prediction
+// looks for it
+const awaitInterval = 100 * time.Millisecond
+
+// Fill returns the cached prediction for ticker, or calls predict to make
+// one and caches it. Concurrent fills for the same ticker and model on this
+// instance share one predict call. With Redis, an instance that finds
+// another already predicting the ticker waits for that result instead, for
+// as long as the other holds its lease; predict's error is returned as is.
+//
+// A prediction within endpoint's stale window past its expiry is returned
+// straight away, marked stale, while a fresh one is made in the background.
+func (c *Cache) Fill(ctx context.Context, ticker, endpoint string, predict func(context.Context) (map[string]interface{}, error)) (*Entry, error) {
+	if entry, ok := c.Serve(ticker, endpoint, predict); ok {
+		return entry, nil
+	}
+
+	entry, shared, err := c.flights.Do(ctx, c.flightKey(ticker), func(ctx context.Context) (*Entry, error) {
+		return c.fill(ctx, ticker, predict)
+	})
+	if shared && c.metrics != nil {
+		c.metrics.PredictionCoalesced.WithLabelValues("cache").Inc()
+	}
+	return entry, err
+}
+
+// Serve returns the prediction cached for ticker, if any, like Fill but
+// without predicting on a miss. A prediction within endpoint's stale window
+// is returned marked stale while predict refreshes it in the background.
+func (c *Cache) Serve(ticker, endpoint string, predict func(context.Context) (map[string]interface{}, error)) (*Entry, bool) {
+	entry, ok := c.Lookup(ticker, c.StaleWindow(endpoint))
+	if ok && entry.Stale {
+		if c.metrics != nil {
+			c.metrics.CacheStaleHit.WithLabelValues(endpoint).Inc()
+		}
+		c.revalidate(ticker, predict)
+	}
+	return entry, ok
+}
+
+// flightKey identifies the prediction of a ticker by the model now on disk
+func (c *Cache) flightKey(ticker string) string {
+	return redisclient.CacheKey(strings.ToLower(ticker)) + "@" + c.modelVersion(ticker)
+}
+
+// revalidate refreshes a stale prediction in the background, unless a
+// refresh is under way already
+func (c *Cache) revalidate(ticker string, predict func(context.Context) (map[string]interface{}, error)) {
+	key := c.flightKey(ticker)
+	if c.flights.Waiters(key) > 0 {
+		return
+	}
+	go func() {
+		_, shared, err := c.flights.Do(context.Background(), key, func(ctx context.Context) (*Entry, error) {
+			return c.fill(ctx, ticker, predict)
+		})
+		if shared {
+			return
+		}
+		result := "refreshed"
+		if err != nil {
+			log.Printf("Failed to refresh stale prediction for %s: %v", ticker, err)
+			result = "failed"
+		}
+		if c.metrics != nil {
+			c.metrics.CacheRevalidations.WithLabelValues(result).Inc()
+		}
+	}()
+}
+
+// fill makes and caches one prediction, unless another instance is making
+// it already
+func (c *Cache) fill(ctx context.Context, ticker string, predict func(context.Context) (map[string]interface{}, error)) (*Entry, error) {
+	if c.redis != nil && c.lockTTL > 0 {
+		lockKey := redisclient.PredictLockKey(strings.ToLower(ticker))
+		lock, err := c.redis.AcquireLock(ctx, lockKey, c.origin, c.lockTTL)
+		switch {
+		case err != nil:
+			log.Printf("Failed to take prediction lease on %s, predicting without it: %v", ticker, err)
+		case lock == nil:
+			if entry, ok := c.await(ctx, ticker, lockKey); ok {
+				if c.metrics != nil {
+					c.metrics.PredictionCoalesced.WithLabelValues("replica").Inc()
+				}
+				return entry, nil
+			}
+			// The other instance gave up or failed; predict here
+		default:
+			defer lock.Release(context.Background())
+		}
+	}
+
+	data, err := predict(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := c.store(ticker, data)
+	if err != nil {
+		log.Printf("Failed to cache prediction for %s: %v", ticker, err)
+		return &Entry{Ticker: strings.ToUpper(ticker), ModelVersion: c.modelVersion(ticker), Current: true, Data: data}, nil
+	}
+	entry := rec.entry(ticker, true)
+	entry.Data = data
+	return entry, nil
+}
+
+// await waits for the prediction another instance is making to be cached.
+// It gives up when that instance lets go of its lease without caching one,
+// or ctx is done.
+func (c *Cache) await(ctx context.Context, ticker, lockKey string) (*Entry, bool) {
+	poll := time.NewTicker(awaitInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-poll.C:
+		}
+
+		if entry, ok := c.peek(ctx, ticker); ok {
+			return entry, true
+		}
+		owner, err := c.redis.LockOwner(ctx, lockKey)
+		if err != nil || owner == "" {
+			// It may have been cached just before the lease was released
+			return c.peek(ctx, ticker)
+		}
+	}
+}
+
+// peek reads an unexpired prediction from Redis without counting a hit or
+// miss, and keeps a local copy
+func (c *Cache) peek(ctx context.Context, ticker string) (*Entry, bool) {
+	key := redisclient.CacheKey(strings.ToLower(ticker))
+	rec, err := c.read(ctx, key)
+	if err != nil || rec == nil || !rec.servable(c.modelVersion(ticker), 0) {
+		return nil, false
+	}
+	if c.local != nil {
+		c.local.add(key, rec)
+	}
+	return rec.hit(ticker), true
+}
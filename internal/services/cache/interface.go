@@ -1,11 +1,14 @@
 package cache
 
+import "context"
+
 // CacheInterface defines the contract for prediction caching.
 // Using an interface allows handlers to be tested with mock implementations.
 type CacheInterface interface {
 	Get(ticker string) (map[string]interface{}, bool)
 	Set(ticker string, data map[string]interface{}) error
 	Delete(ticker string) error
+	Fill(ctx context.Context, ticker, endpoint string, predict func(context.Context) (map[string]interface{}, error)) (*Entry, error)
 	GetCachedTickers() ([]string, error)
 	GetForTicker(ticker string) (map[string]interface{}, error)
 }
@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru is a bounded in-process map of predictions whose entries expire after
+// a short TTL; the least recently used entry is evicted when it is full
+type lru struct {
+	mu      sync.Mutex
+	max     int
+	ttl     time.Duration
+	order   *list.List // front is the most recently used
+	entries map[string]*list.Element
+	gen     uint64 // bumped on every invalidation
+	now     func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	record    *record
+	expiresAt time.Time
+}
+
+func newLRU(max int, ttl time.Duration) *lru {
+	return &lru{
+		max:     max,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// get returns an entry that has not expired and marks it recently used
+func (l *lru) get(key string) (*record, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if !l.now().Before(entry.expiresAt) {
+		l.order.Remove(elem)
+		delete(l.entries, key)
+		return nil, false
+	}
+	l.order.MoveToFront(elem)
+	return entry.record, true
+}
+
+// generation returns a token for addIfCurrent
+func (l *lru) generation() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.gen
+}
+
+// add stores an entry, evicting the least recently used one when full
+func (l *lru) add(key string, rec *record) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.addLocked(key, rec)
+}
+
+// addIfCurrent stores an entry read from Redis unless something was
+// invalidated since gen was taken, as the read may predate the change
+func (l *lru) addIfCurrent(key string, rec *record, gen uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.gen != gen {
+		return
+	}
+	l.addLocked(key, rec)
+}
+
+func (l *lru) addLocked(key string, rec *record) {
+	expiresAt := l.now().Add(l.ttl)
+	if elem, ok := l.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.record, entry.expiresAt = rec, expiresAt
+		l.order.MoveToFront(elem)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, record: rec, expiresAt: expiresAt})
+	for l.order.Len() > l.max {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// remove invalidates one entry
+func (l *lru) remove(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.gen++
+	if elem, ok := l.entries[key]; ok {
+		l.order.Remove(elem)
+		delete(l.entries, key)
+	}
+}
+
+// clear invalidates every entry
+func (l *lru) clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.gen++
+	l.order.Init()
+	l.entries = make(map[string]*list.Element)
+}
+
+// list returns the entries that have not expired, most recently used first
+func (l *lru) list() []lruEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	entries := make([]lruEntry, 0, len(l.entries))
+	for elem := l.order.Front(); elem != nil; elem = elem.Next() {
+		if entry := elem.Value.(*lruEntry); now.Before(entry.expiresAt) {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries
+}
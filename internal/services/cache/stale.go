@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+)
+
+// Endpoints that serve cached predictions, as named in CACHE_STALE_ENDPOINTS
+const (
+	EndpointPredictChild = "predict-child"
+	EndpointSystemCache  = "system-cache"
+)
+
+var staleEndpoints = map[string]bool{
+	EndpointPredictChild: true,
+	EndpointSystemCache:  true,
+}
+
+// StaleWindows maps endpoints to how long past its expiry each still serves
+// a prediction
+type StaleWindows map[string]time.Duration
+
+// For returns the window of an endpoint; unknown endpoints never serve stale
+func (w StaleWindows) For(endpoint string) time.Duration {
+	return w[endpoint]
+}
+
+// LoadStaleWindows gives every endpoint the whole grace period, with
+// cfg.CacheStaleEndpoints (JSON seconds keyed by endpoint) applied on top.
+// No window is longer than the grace period, as nothing older is kept.
+func LoadStaleWindows(cfg *config.Config) (StaleWindows, error) {
+	grace := time.Duration(cfg.CacheStaleGrace) * time.Second
+	if grace < 0 {
+		grace = 0
+	}
+	windows := make(StaleWindows, len(staleEndpoints))
+	for endpoint := range staleEndpoints {
+		windows[endpoint] = grace
+	}
+	if strings.TrimSpace(cfg.CacheStaleEndpoints) == "" {
+		return windows, nil
+	}
+
+	var overrides map[string]int
+	if err := json.Unmarshal([]byte(cfg.CacheStaleEndpoints), &overrides); err != nil {
+		return windows, fmt.Errorf("invalid CACHE_STALE_ENDPOINTS: %w", err)
+	}
+	for endpoint, seconds := range overrides {
+		if !staleEndpoints[endpoint] {
+			return windows, fmt.Errorf("invalid CACHE_STALE_ENDPOINTS: unknown endpoint %q", endpoint)
+		}
+		if seconds < 0 {
+			return windows, fmt.Errorf("invalid CACHE_STALE_ENDPOINTS: negative window for %q", endpoint)
+		}
+	}
+	for endpoint, seconds := range overrides {
+		windows[endpoint] = min(time.Duration(seconds)*time.Second, grace)
+	}
+	return windows, nil
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// modelVersions identifies the child model a prediction comes from by a
+// hash of its model file. A file is only hashed again when its size or
+// modification time changes.
+type modelVersions struct {
+	outputsDir string
+
+	mu     sync.Mutex
+	hashes map[string]fileHash // by path
+}
+
+type fileHash struct {
+	modTime time.Time
+	size    int64
+	version string
+}
+
+func newModelVersions(outputsDir string) *modelVersions {
+	return &modelVersions{outputsDir: outputsDir, hashes: make(map[string]fileHash)}
+}
+
+// of returns the version of a ticker's child model, or "" when there is
+// none
+func (v *modelVersions) of(ticker string) string {
+	ticker = strings.ToUpper(ticker)
+	path := filepath.Join(v.outputsDir, ticker, ticker+"_child_model.pt")
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+
+	v.mu.Lock()
+	known, ok := v.hashes[path]
+	v.mu.Unlock()
+	if ok && known.modTime.Equal(info.ModTime()) && known.size == info.Size() {
+		return known.version
+	}
+
+	version, err := hashFile(path)
+	if err != nil {
+		log.Printf("Failed to hash model %s: %v", path, err)
+		return ""
+	}
+	v.mu.Lock()
+	v.hashes[path] = fileHash{modTime: info.ModTime(), size: info.Size(), version: version}
+	v.mu.Unlock()
+	return version
+}
+
+// hashFile returns the first 12 hex digits of a file's SHA-256
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12], nil
+}
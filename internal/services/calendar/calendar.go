@@ -0,0 +1,244 @@
+// Package calendar knows when the exchange trades: regular sessions,
+// holidays and early closes, loaded from an embedded data file.
+package calendar
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"time"
+	_ "time/tzdata" // the exchange time zone must load on hosts without zoneinfo
+)
+
+//go:embed nyse.json
+var nyseData []byte
+
+// dateLayout is how days are written in the data file and the API
+const dateLayout = "2006-01-02"
+
+// maxGap bounds the search for the next session; no exchange closes for
+// longer than this
+const maxGap = 14
+
+// Session is one trading day
+type Session struct {
+	Date       string    `json:"date"`
+	Open       time.Time `json:"open"`
+	Close      time.Time `json:"close"`
+	EarlyClose bool      `json:"early_close"`
+	Name       string    `json:"name,omitempty"` // why it closes early
+}
+
+// Holiday is a weekday the exchange is closed
+type Holiday struct {
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+// Calendar is an exchange's trading calendar. Days after the last one in
+// its data file are assumed to be regular sessions on weekdays.
+type Calendar struct {
+	exchange string
+	loc      *time.Location
+	open     clock
+	close    clock
+	firstDay string
+	lastDay  string
+	holidays map[string]string
+	early    map[string]earlyClose
+}
+
+type earlyClose struct {
+	close clock
+	name  string
+}
+
+// clock is a time of day in the exchange's time zone
+type clock struct {
+	hour, minute int
+}
+
+func parseClock(s string) (clock, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return clock{}, fmt.Errorf("time of day %q: want HH:MM", s)
+	}
+	return clock{hour: t.Hour(), minute: t.Minute()}, nil
+}
+
+// on returns the clock's time on a day
+func (c clock) on(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), c.hour, c.minute, 0, 0, day.Location())
+}
+
+// data is the layout of the embedded file
+type data struct {
+	Exchange    string    `json:"exchange"`
+	Timezone    string    `json:"timezone"`
+	Open        string    `json:"open"`
+	Close       string    `json:"close"`
+	FirstDay    string    `json:"first_day"`
+	LastDay     string    `json:"last_day"`
+	Holidays    []Holiday `json:"holidays"`
+	EarlyCloses []struct {
+		Date  string `json:"date"`
+		Close string `json:"close"`
+		Name  string `json:"name"`
+	} `json:"early_closes"`
+}
+
+// NYSE returns the New York Stock Exchange calendar
+func NYSE() (*Calendar, error) {
+	return Parse(nyseData)
+}
+
+// Parse reads a calendar in the layout of the embedded nyse.json
+func Parse(raw []byte) (*Calendar, error) {
+	var d data
+	if err := json.Unmarshal(raw, &d); err != nil {
+		return nil, fmt.Errorf("calendar: %w", err)
+	}
+
+	loc, err := time.LoadLocation(d.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("calendar: %w", err)
+	}
+	c := &Calendar{
+		exchange: d.Exchange,
+		loc:      loc,
+		firstDay: d.FirstDay,
+		lastDay:  d.LastDay,
+		holidays: make(map[string]string, len(d.Holidays)),
+		early:    make(map[string]earlyClose, len(d.EarlyCloses)),
+	}
+	if c.open, err = parseClock(d.Open); err != nil {
+		return nil, fmt.Errorf("calendar: open: %w", err)
+	}
+	if c.close, err = parseClock(d.Close); err != nil {
+		return nil, fmt.Errorf("calendar: close: %w", err)
+	}
+	for _, day := range []string{d.FirstDay, d.LastDay} {
+		if _, err := time.Parse(dateLayout, day); err != nil {
+			return nil, fmt.Errorf("calendar: date %q: want YYYY-MM-DD", day)
+		}
+	}
+	for _, h := range d.Holidays {
+		if _, err := time.Parse(dateLayout, h.Date); err != nil {
+			return nil, fmt.Errorf("calendar: holiday %q: want YYYY-MM-DD", h.Date)
+		}
+		c.holidays[h.Date] = h.Name
+	}
+	for _, e := range d.EarlyCloses {
+		if _, err := time.Parse(dateLayout, e.Date); err != nil {
+			return nil, fmt.Errorf("calendar: early close %q: want YYYY-MM-DD", e.Date)
+		}
+		at, err := parseClock(e.Close)
+		if err != nil {
+			return nil, fmt.Errorf("calendar: early close %s: %w", e.Date, err)
+		}
+		c.early[e.Date] = earlyClose{close: at, name: e.Name}
+	}
+	return c, nil
+}
+
+// Exchange returns the exchange's name
+func (c *Calendar) Exchange() string { return c.exchange }
+
+// Location returns the exchange's time zone
+func (c *Calendar) Location() *time.Location { return c.loc }
+
+// FirstDay returns the first day whose holidays are known
+func (c *Calendar) FirstDay() string { return c.firstDay }
+
+// LastDay returns the last day whose holidays are known
+func (c *Calendar) LastDay() string { return c.lastDay }
+
+// day returns midnight of t's date in the exchange's time zone
+func (c *Calendar) day(t time.Time) time.Time {
+	t = t.In(c.loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc)
+}
+
+// Session returns the session on t's date in the exchange's time zone, or
+// false on weekends and holidays
+func (c *Calendar) Session(t time.Time) (Session, bool) {
+	day := c.day(t)
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return Session{}, false
+	}
+	date := day.Format(dateLayout)
+	if _, ok := c.holidays[date]; ok {
+		return Session{}, false
+	}
+
+	session := Session{Date: date, Open: c.open.on(day), Close: c.close.on(day)}
+	if early, ok := c.early[date]; ok {
+		session.Close = early.close.on(day)
+		session.EarlyClose = true
+		session.Name = early.name
+	}
+	return session, true
+}
+
+// IsOpen reports whether the exchange is trading at t
+func (c *Calendar) IsOpen(t time.Time) bool {
+	session, ok := c.Session(t)
+	return ok && !t.Before(session.Open) && t.Before(session.Close)
+}
+
+// NextSession returns the session that is in progress at t, or else the
+// next one to open
+func (c *Calendar) NextSession(t time.Time) Session {
+	day := c.day(t)
+	for i := 0; i <= maxGap; i++ {
+		if session, ok := c.Session(day); ok && t.Before(session.Close) {
+			return session
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	// Unreachable with any real calendar; close at the end of the search
+	return Session{Date: day.Format(dateLayout), Open: c.open.on(day), Close: c.close.on(day)}
+}
+
+// NextClose returns the first session close after t
+func (c *Calendar) NextClose(t time.Time) time.Time {
+	return c.NextSession(t).Close
+}
+
+// NextOpen returns the first session open after t
+func (c *Calendar) NextOpen(t time.Time) time.Time {
+	session := c.NextSession(t)
+	if t.Before(session.Open) {
+		return session.Open
+	}
+	return c.NextSession(session.Close).Open
+}
+
+// Sessions returns the sessions on the days from from's date, for days
+// days
+func (c *Calendar) Sessions(from time.Time, days int) []Session {
+	sessions := []Session{}
+	day := c.day(from)
+	for i := 0; i < days; i++ {
+		if session, ok := c.Session(day); ok {
+			sessions = append(sessions, session)
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return sessions
+}
+
+// Holidays returns the weekday closures on the days from from's date, for
+// days days
+func (c *Calendar) Holidays(from time.Time, days int) []Holiday {
+	holidays := []Holiday{}
+	day := c.day(from)
+	for i := 0; i < days; i++ {
+		date := day.Format(dateLayout)
+		if name, ok := c.holidays[date]; ok {
+			holidays = append(holidays, Holiday{Date: date, Name: name})
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return holidays
+}
@@ -0,0 +1,115 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func nyse(t *testing.T) *Calendar {
+	t.Helper()
+	c, err := NYSE()
+	if err != nil {
+		t.Fatalf("NYSE(): %v", err)
+	}
+	return c
+}
+
+// at returns a time in New York
+func at(t *testing.T, c *Calendar, s string) time.Time {
+	t.Helper()
+	ts, err := time.ParseInLocation("2006-01-02 15:04", s, c.Location())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func TestSession(t *testing.T) {
+	c := nyse(t)
+	tests := []struct {
+		day   string
+		open  bool
+		close string
+	}{
+		{"2026-10-16", true, "2026-10-16 16:00"}, // Friday
+		{"2026-10-17", false, ""},                // Saturday
+		{"2026-11-26", false, ""},                // Thanksgiving
+		{"2026-11-27", true, "2026-11-27 13:00"}, // early close
+		{"2026-07-03", false, ""},                // Independence Day observed
+		{"2028-03-15", true, "2028-03-15 16:00"}, // past the data file: a plain weekday
+	}
+	for _, tc := range tests {
+		session, ok := c.Session(at(t, c, tc.day+" 12:00"))
+		if ok != tc.open {
+			t.Errorf("Session(%s) ok = %v, want %v", tc.day, ok, tc.open)
+			continue
+		}
+		if ok && !session.Close.Equal(at(t, c, tc.close)) {
+			t.Errorf("Session(%s) close = %v, want %s", tc.day, session.Close, tc.close)
+		}
+	}
+}
+
+func TestNextClose(t *testing.T) {
+	c := nyse(t)
+	tests := []struct {
+		now, want string
+	}{
+		{"2026-10-15 15:55", "2026-10-15 16:00"}, // minutes before the close
+		{"2026-10-15 16:00", "2026-10-16 16:00"}, // at the close
+		{"2026-10-16 20:00", "2026-10-19 16:00"}, // Friday evening: Monday
+		{"2026-10-17 10:00", "2026-10-19 16:00"}, // Saturday
+		{"2026-11-25 17:00", "2026-11-27 13:00"}, // over Thanksgiving to the early close
+		{"2026-12-31 18:00", "2027-01-04 16:00"}, // over New Year and a weekend
+	}
+	for _, tc := range tests {
+		if got := c.NextClose(at(t, c, tc.now)); !got.Equal(at(t, c, tc.want)) {
+			t.Errorf("NextClose(%s) = %v, want %s", tc.now, got, tc.want)
+		}
+	}
+
+	// A time in another zone is judged in New York
+	utc := at(t, c, "2026-10-16 20:00").UTC()
+	if got := c.NextClose(utc); !got.Equal(at(t, c, "2026-10-19 16:00")) {
+		t.Errorf("NextClose(%v) = %v, want Monday's close", utc, got)
+	}
+}
+
+func TestIsOpenAndNextOpen(t *testing.T) {
+	c := nyse(t)
+	if !c.IsOpen(at(t, c, "2026-10-16 09:30")) || c.IsOpen(at(t, c, "2026-10-16 16:00")) || c.IsOpen(at(t, c, "2026-10-17 12:00")) {
+		t.Error("IsOpen is wrong at the open, the close or on a Saturday")
+	}
+	if got, want := c.NextOpen(at(t, c, "2026-10-16 12:00")), at(t, c, "2026-10-19 09:30"); !got.Equal(want) {
+		t.Errorf("NextOpen(during Friday's session) = %v, want %v", got, want)
+	}
+	if got, want := c.NextOpen(at(t, c, "2026-10-19 08:00")), at(t, c, "2026-10-19 09:30"); !got.Equal(want) {
+		t.Errorf("NextOpen(Monday morning) = %v, want %v", got, want)
+	}
+}
+
+func TestSessionsAndHolidays(t *testing.T) {
+	c := nyse(t)
+	from := at(t, c, "2026-11-23 00:00")
+	sessions := c.Sessions(from, 7)
+	if len(sessions) != 4 || sessions[3].Date != "2026-11-27" || !sessions[3].EarlyClose {
+		t.Errorf("Sessions(Thanksgiving week) = %+v, want 4 ending in an early close", sessions)
+	}
+	holidays := c.Holidays(from, 7)
+	if len(holidays) != 1 || holidays[0].Date != "2026-11-26" {
+		t.Errorf("Holidays(Thanksgiving week) = %+v, want Thanksgiving", holidays)
+	}
+}
+
+func TestParse_Rejects(t *testing.T) {
+	for name, raw := range map[string]string{
+		"bad json":     `{`,
+		"bad timezone": `{"timezone":"Mars/Olympus","open":"09:30","close":"16:00"}`,
+		"bad clock":    `{"timezone":"UTC","open":"9am","close":"16:00"}`,
+		"bad holiday":  `{"timezone":"UTC","open":"09:30","close":"16:00","first_day":"2026-01-01","last_day":"2026-12-31","holidays":[{"date":"26/12/25"}]}`,
+	} {
+		if _, err := Parse([]byte(raw)); err == nil {
+			t.Errorf("Parse(%s) err = nil, want an error", name)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package flight merges concurrent calls for the same key into one
+// execution whose result they all share.
+package flight
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Group runs at most one call per key at a time. The zero value is ready
+// to use.
+type Group[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[T]
+}
+
+type call[T any] struct {
+	done    chan struct{}
+	val     T
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// Do runs fn for key, or waits for the run already in progress and returns
+// its result; shared reports the latter. fn gets a context of its own that
+// is cancelled only once every caller waiting on it has given up, so one
+// caller going away does not fail the others.
+func (g *Group[T]) Do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (v T, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[T])
+	}
+	c, shared := g.calls[key]
+	if shared {
+		c.waiters++
+	} else {
+		runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call[T]{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.calls[key] = c
+		go g.run(runCtx, key, c, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, shared, c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			// Nobody wants the result any more; later callers start afresh
+			c.cancel()
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		var zero T
+		return zero, shared, ctx.Err()
+	}
+}
+
+// Waiters returns how many callers wait on the run in progress for key
+func (g *Group[T]) Waiters(key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if c, ok := g.calls[key]; ok {
+		return c.waiters
+	}
+	return 0
+}
+
+func (g *Group[T]) run(ctx context.Context, key string, c *call[T], fn func(ctx context.Context) (T, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = fmt.Errorf("flight %s: panic: %v", key, r)
+		}
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		c.cancel()
+		close(c.done)
+	}()
+	c.val, c.err = fn(ctx)
+}
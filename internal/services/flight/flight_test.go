@@ -0,0 +1,111 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDo_SharesOneRun(t *testing.T) {
+	var g Group[int]
+	var runs atomic.Int32
+	release := make(chan struct{})
+
+	const callers = 5
+	var wg sync.WaitGroup
+	var sharedCount atomic.Int32
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, shared, err := g.Do(context.Background(), "aapl", func(context.Context) (int, error) {
+				runs.Add(1)
+				<-release
+				return 42, nil
+			})
+			if v != 42 || err != nil {
+				t.Errorf("Do = %d, %v, want 42", v, err)
+			}
+			if shared {
+				sharedCount.Add(1)
+			}
+		}()
+	}
+
+	// Let every caller join before the run finishes
+	deadline := time.Now().Add(2 * time.Second)
+	for g.Waiters("aapl") < callers {
+		if time.Now().After(deadline) {
+			t.Fatal("callers did not join the run")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if runs.Load() != 1 || sharedCount.Load() != callers-1 {
+		t.Errorf("runs = %d, shared = %d, want 1 run shared by %d callers", runs.Load(), sharedCount.Load(), callers-1)
+	}
+
+	// The next call runs again
+	v, shared, _ := g.Do(context.Background(), "aapl", func(context.Context) (int, error) { return 7, nil })
+	if v != 7 || shared {
+		t.Errorf("Do after the run = %d, shared %v, want a fresh run", v, shared)
+	}
+}
+
+func TestDo_CancelsOnlyWhenEveryCallerLeaves(t *testing.T) {
+	var g Group[int]
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return 0, ctx.Err()
+	}
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	second, cancelSecond := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	go func() { _, _, err := g.Do(first, "aapl", fn); errs <- err }()
+	<-started
+	go func() { _, _, err := g.Do(second, "aapl", fn); errs <- err }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for g.Waiters("aapl") < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("second caller did not join")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancelFirst()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("first caller err = %v, want context.Canceled", err)
+	}
+	select {
+	case <-cancelled:
+		t.Fatal("run was cancelled while a caller still waits")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancelSecond()
+	<-errs
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("run was not cancelled after every caller left")
+	}
+}
+
+func TestDo_RecoversPanic(t *testing.T) {
+	var g Group[int]
+	_, _, err := g.Do(context.Background(), "aapl", func(context.Context) (int, error) { panic("boom") })
+	if err == nil {
+		t.Error("Do(panicking fn) err = nil, want an error")
+	}
+}
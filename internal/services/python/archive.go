@@ -0,0 +1,170 @@
+package python
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+)
+
+// maxArchivedOutput caps the stdout and stderr kept per execution
+const maxArchivedOutput = 1 << 20
+
+// ErrExecutionNotFound is returned when no archived execution has the given ID
+var ErrExecutionNotFound = errors.New("execution not found")
+
+var executionIDPattern = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+// Execution is the archived record of one Python command
+type Execution struct {
+	ID              string   `json:"id"`
+	Args            []string `json:"args"`
+	StartedAt       string   `json:"started_at"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	ExitCode        int      `json:"exit_code"` // -1 if killed or never started
+	Stdout          string   `json:"stdout"`
+	Stderr          string   `json:"stderr"`
+	Error           string   `json:"error,omitempty"`
+	Code            string   `json:"code,omitempty"`
+
+	start time.Time
+}
+
+// newExecution starts a record with a fresh, time-sortable ID
+func newExecution(args []string) *Execution {
+	now := time.Now().UTC()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+
+	return &Execution{
+		ID:        now.Format("20060102T150405") + "-" + hex.EncodeToString(suffix),
+		Args:      append([]string(nil), args...),
+		StartedAt: now.Format(time.RFC3339),
+		ExitCode:  -1,
+		start:     now,
+	}
+}
+
+// finish records the duration and outcome of an execution
+func (e *Execution) finish(err error) {
+	e.DurationSeconds = time.Since(e.start).Seconds()
+	if err != nil {
+		e.Error = err.Error()
+		var cliErr *Error
+		if errors.As(err, &cliErr) {
+			e.Code = cliErr.Code
+			cliErr.ExecutionID = e.ID
+		}
+	}
+}
+
+// capOutput keeps the tail of long output, which is where tracebacks live
+func capOutput(b []byte) string {
+	if len(b) <= maxArchivedOutput {
+		return string(b)
+	}
+	return "...[truncated]\n" + string(b[len(b)-maxArchivedOutput:])
+}
+
+// ExecutionStore looks up archived execution records: an Archive on the
+// host that ran them, or a RemoteRunner that asks its workers
+type ExecutionStore interface {
+	Get(id string) (*Execution, error)
+}
+
+// Archive stores execution records as JSON files, keeping the newest max
+type Archive struct {
+	dir string
+	max int
+	mu  sync.Mutex
+}
+
+// NewArchive creates an archive under cfg.LogsDir/executions
+func NewArchive(cfg *config.Config) *Archive {
+	return &Archive{
+		dir: filepath.Join(cfg.LogsDir, "executions"),
+		max: cfg.ExecutionLogMax,
+	}
+}
+
+// Save writes an execution record and prunes the oldest beyond the limit
+func (a *Archive) Save(e *Execution) error {
+	if a == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(a.dir, e.ID+".json"), data, 0644); err != nil {
+		return err
+	}
+
+	a.prune()
+	return nil
+}
+
+// Get loads an execution record by ID
+func (a *Archive) Get(id string) (*Execution, error) {
+	if a == nil || !executionIDPattern.MatchString(id) {
+		return nil, ErrExecutionNotFound
+	}
+
+	data, err := os.ReadFile(filepath.Join(a.dir, id+".json"))
+	if os.IsNotExist(err) {
+		return nil, ErrExecutionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var e Execution
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// prune removes the oldest records beyond max; IDs sort chronologically
+func (a *Archive) prune() {
+	if a.max <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(a.dir)
+	if err != nil {
+		return
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) <= a.max {
+		return
+	}
+
+	sort.Strings(names)
+	for _, name := range names[:len(names)-a.max] {
+		os.Remove(filepath.Join(a.dir, name))
+	}
+}
@@ -0,0 +1,63 @@
+package python
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestArchive_SaveAndGet(t *testing.T) {
+	a := &Archive{dir: t.TempDir(), max: 10}
+
+	e := newExecution([]string{"monitor-ticker", "--ticker", "AAPL"})
+	e.ExitCode = 0
+	e.Stdout = `{"status": "ok"}`
+	if err := a.Save(e); err != nil {
+		t.Fatalf("Save err = %v", err)
+	}
+
+	got, err := a.Get(e.ID)
+	if err != nil {
+		t.Fatalf("Get(%s) err = %v", e.ID, err)
+	}
+	if got.Stdout != e.Stdout || got.ExitCode != 0 || len(got.Args) != 3 {
+		t.Errorf("Get(%s) = %+v, want %+v", e.ID, got, e)
+	}
+}
+
+func TestArchive_GetRejectsUnknownAndUnsafeIDs(t *testing.T) {
+	a := &Archive{dir: t.TempDir(), max: 10}
+
+	for _, id := range []string{"missing", "../secrets", ""} {
+		if _, err := a.Get(id); !errors.Is(err, ErrExecutionNotFound) {
+			t.Errorf("Get(%q) err = %v, want ErrExecutionNotFound", id, err)
+		}
+	}
+}
+
+func TestArchive_PrunesOldest(t *testing.T) {
+	a := &Archive{dir: t.TempDir(), max: 2}
+
+	for _, id := range []string{"20260101T000000-a", "20260102T000000-b", "20260103T000000-c"} {
+		if err := a.Save(&Execution{ID: id}); err != nil {
+			t.Fatalf("Save(%s) err = %v", id, err)
+		}
+	}
+
+	entries, _ := os.ReadDir(a.dir)
+	if len(entries) != 2 {
+		t.Fatalf("archive holds %d records, want 2", len(entries))
+	}
+	if _, err := a.Get("20260101T000000-a"); !errors.Is(err, ErrExecutionNotFound) {
+		t.Errorf("Get(oldest) err = %v, want ErrExecutionNotFound", err)
+	}
+}
+
+func TestCapOutput_KeepsTail(t *testing.T) {
+	out := capOutput([]byte(strings.Repeat("x", maxArchivedOutput) + "Traceback"))
+
+	if !strings.HasSuffix(out, "Traceback") || !strings.HasPrefix(out, "...[truncated]") {
+		t.Errorf("capOutput kept %q...%q, want the truncated tail", out[:20], out[len(out)-20:])
+	}
+}
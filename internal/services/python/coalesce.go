@@ -0,0 +1,60 @@
+package python
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/metrics"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/flight"
+)
+
+// Coalescer wraps a runner so that concurrent predictions for the same
+// ticker and model share one execution instead of each starting its own.
+// Every other call goes straight through.
+type Coalescer struct {
+	RunnerInterface
+	metrics      *metrics.Metrics
+	modelVersion func(ticker string) string
+	predictions  flight.Group[*Result]
+}
+
+// NewCoalescer wraps runner. modelVersion names the child model a
+// prediction for a ticker would use now, so that a caller arriving after a
+// retrain does not join a run of the old model; nil leaves runs unversioned.
+func NewCoalescer(runner RunnerInterface, m *metrics.Metrics, modelVersion func(ticker string) string) *Coalescer {
+	return &Coalescer{RunnerInterface: runner, metrics: m, modelVersion: modelVersion}
+}
+
+// PredictParent runs predict-parent, or joins the one in progress
+func (c *Coalescer) PredictParent(ctx context.Context) (*Result, error) {
+	return c.do(ctx, "predict-parent", c.RunnerInterface.PredictParent)
+}
+
+// PredictChild runs predict-child for a ticker, or joins the one in
+// progress with the same model
+func (c *Coalescer) PredictChild(ctx context.Context, ticker string) (*Result, error) {
+	key := "predict-child:" + strings.ToUpper(ticker)
+	if c.modelVersion != nil {
+		key += "@" + c.modelVersion(ticker)
+	}
+	return c.do(ctx, key, func(ctx context.Context) (*Result, error) {
+		return c.RunnerInterface.PredictChild(ctx, ticker)
+	})
+}
+
+func (c *Coalescer) do(ctx context.Context, key string, fn func(context.Context) (*Result, error)) (*Result, error) {
+	result, shared, err := c.predictions.Do(ctx, key, fn)
+	if shared && c.metrics != nil {
+		c.metrics.PredictionCoalesced.WithLabelValues("runner").Inc()
+	}
+	return result, err
+}
+
+// Close closes the wrapped runner if it holds resources
+func (c *Coalescer) Close() error {
+	if closer, ok := c.RunnerInterface.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
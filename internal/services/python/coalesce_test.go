@@ -0,0 +1,122 @@
+package python
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/metrics"
+)
+
+// counterValue reads a Prometheus counter
+func counterValue(c prometheus.Counter) float64 {
+	var m dto.Metric
+	c.Write(&m)
+	return m.GetCounter().GetValue()
+}
+
+// slowPredictor counts predict-child runs and holds each until released
+type slowPredictor struct {
+	RunnerInterface
+	runs    atomic.Int32
+	release chan struct{}
+}
+
+func (p *slowPredictor) PredictChild(_ context.Context, ticker string) (*Result, error) {
+	p.runs.Add(1)
+	<-p.release
+	return &Result{Data: map[string]interface{}{"ticker": ticker}}, nil
+}
+
+func TestCoalescer_SharesPredictions(t *testing.T) {
+	inner := &slowPredictor{release: make(chan struct{})}
+	m := metrics.New(prometheus.NewRegistry())
+	c := NewCoalescer(inner, m, nil)
+
+	var wg sync.WaitGroup
+	results := make(chan *Result, 4)
+	for _, ticker := range []string{"AAPL", "aapl", "AAPL", "MSFT"} {
+		wg.Add(1)
+		go func(ticker string) {
+			defer wg.Done()
+			result, err := c.PredictChild(context.Background(), ticker)
+			if err != nil {
+				t.Errorf("PredictChild(%s): %v", ticker, err)
+			}
+			results <- result
+		}(ticker)
+	}
+
+	// Release the runs once all three AAPL callers wait on one
+	deadline := time.Now().Add(2 * time.Second)
+	for c.predictions.Waiters("predict-child:AAPL") < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("AAPL waiters = %d, want 3", c.predictions.Waiters("predict-child:AAPL"))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(inner.release)
+	wg.Wait()
+	close(results)
+
+	if got := counterValue(m.PredictionCoalesced.WithLabelValues("runner")); got != 2 {
+		t.Errorf("coalesced = %v, want 2", got)
+	}
+
+	if runs := inner.runs.Load(); runs != 2 {
+		t.Errorf("predict-child ran %d times, want once per ticker", runs)
+	}
+	n := 0
+	for result := range results {
+		if result == nil || result.Data["ticker"] == nil {
+			t.Errorf("result = %+v, want a prediction", result)
+		}
+		n++
+	}
+	if n != 4 {
+		t.Errorf("got %d results, want 4", n)
+	}
+}
+
+func TestCoalescer_DoesNotJoinRunsOfAnotherModel(t *testing.T) {
+	inner := &slowPredictor{release: make(chan struct{})}
+	var version atomic.Value
+	version.Store("old")
+	c := NewCoalescer(inner, nil, func(string) string { return version.Load().(string) })
+
+	var wg sync.WaitGroup
+	predict := func() {
+		defer wg.Done()
+		c.PredictChild(context.Background(), "AAPL")
+	}
+	wg.Add(1)
+	go predict()
+	deadline := time.Now().Add(2 * time.Second)
+	for c.predictions.Waiters("predict-child:AAPL@old") < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("first prediction never started")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// A retrain swaps the model while the first run is in flight
+	version.Store("new")
+	wg.Add(1)
+	go predict()
+	for c.predictions.Waiters("predict-child:AAPL@new") < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("prediction by the new model joined the old run")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(inner.release)
+	wg.Wait()
+
+	if runs := inner.runs.Load(); runs != 2 {
+		t.Errorf("predict-child ran %d times, want once per model", runs)
+	}
+}
@@ -0,0 +1,58 @@
+package python
+
+import "errors"
+
+// Error codes reported by ml_cli.py in the "code" field of an error response
+const (
+	CodeModelMissing    = "model_missing"
+	CodeDataUnavailable = "data_unavailable"
+	CodeInvalidTicker   = "invalid_ticker"
+	CodeTimeout         = "timeout"
+	CodeResourceLimit   = "resource_limit" // set by the Go runner, not ml_cli.py
+	CodeInternal        = "internal"
+)
+
+// Sentinel errors matched with errors.Is against an *Error
+var (
+	ErrModelMissing    = errors.New("model missing")
+	ErrDataUnavailable = errors.New("market data unavailable")
+	ErrInvalidTicker   = errors.New("invalid ticker")
+	ErrTimeout         = errors.New("command timed out")
+	ErrResourceLimit   = errors.New("resource limit exceeded")
+	ErrInternal        = errors.New("internal error")
+)
+
+var codeSentinels = map[string]error{
+	CodeModelMissing:    ErrModelMissing,
+	CodeDataUnavailable: ErrDataUnavailable,
+	CodeInvalidTicker:   ErrInvalidTicker,
+	CodeTimeout:         ErrTimeout,
+	CodeResourceLimit:   ErrResourceLimit,
+	CodeInternal:        ErrInternal,
+}
+
+// Error is a failed Python command. Handlers inspect it with errors.Is
+// (against the Err* sentinels) or errors.As (for the code and traceback).
+type Error struct {
+	Code        string
+	Message     string
+	Details     string // Python traceback, if any
+	ExecutionID string // archived stdout/stderr, see Archive
+}
+
+// newError builds an *Error, treating unknown or missing codes as internal
+func newError(code, message, details string) *Error {
+	if _, ok := codeSentinels[code]; !ok {
+		code = CodeInternal
+	}
+	return &Error{Code: code, Message: message, Details: details}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is the sentinel for this error's code
+func (e *Error) Is(target error) bool {
+	return codeSentinels[e.Code] == target
+}
@@ -0,0 +1,45 @@
+package python
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+)
+
+// Limits are the OS resource limits applied to one Python process. Zero
+// means unlimited (or, for Nice, the server's own priority).
+type Limits struct {
+	MemoryMB   int // address space (RLIMIT_AS), inherited by child processes
+	CPUSeconds int // CPU time (RLIMIT_CPU); SIGXCPU at the limit, SIGKILL 5s later
+	Nice       int // scheduling priority, -20..19
+}
+
+// memoryErrorMarkers are stderr fragments left by allocations refused under RLIMIT_AS
+var memoryErrorMarkers = []string{"MemoryError", "Cannot allocate memory", "std::bad_alloc", "DefaultCPUAllocator: can't allocate memory"}
+
+// trainingLimits and inferenceLimits seed DefaultPolicies from config
+func trainingLimits(cfg *config.Config) Limits {
+	return Limits{MemoryMB: cfg.TrainingMemoryLimitMB, CPUSeconds: cfg.TrainingCPULimitSeconds, Nice: cfg.TrainingNice}
+}
+
+func inferenceLimits(cfg *config.Config) Limits {
+	return Limits{MemoryMB: cfg.PythonMemoryLimitMB, CPUSeconds: cfg.PythonCPULimitSeconds, Nice: cfg.PythonNice}
+}
+
+// limitViolation explains a failed run in terms of the limits it hit, or
+// returns "" if the failure does not look limit-related
+func limitViolation(state *os.ProcessState, stderr []byte, l Limits) string {
+	if l.CPUSeconds > 0 && exceededCPU(state, l.CPUSeconds) {
+		return fmt.Sprintf("CPU time limit of %ds exceeded", l.CPUSeconds)
+	}
+	if l.MemoryMB > 0 {
+		for _, marker := range memoryErrorMarkers {
+			if bytes.Contains(stderr, []byte(marker)) {
+				return fmt.Sprintf("memory limit of %d MB exceeded", l.MemoryMB)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+//go:build linux
+
+package python
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// limitsPreamble sets rlimits and niceness on the Python process itself,
+// then execs the real command in its place. The limits are in force before
+// the command runs a single line or forks, and exec keeps them.
+const limitsPreamble = `import os, resource, sys
+mem, cpu, nice = (int(v) for v in sys.argv[1:4])
+try:
+    if mem:
+        resource.setrlimit(resource.RLIMIT_AS, (mem, mem))
+    if cpu:
+        resource.setrlimit(resource.RLIMIT_CPU, (cpu, cpu + 5))
+    if nice:
+        os.setpriority(os.PRIO_PROCESS, 0, nice)
+except (OSError, ValueError) as e:
+    sys.stderr.write(f"Failed to apply resource limits: {e}\n")
+os.execv(sys.executable, [sys.executable] + sys.argv[4:])
+`
+
+// limitedArgs returns the arguments that run python with args under l:
+// args unchanged without limits, else behind limitsPreamble
+func limitedArgs(l Limits, args []string) []string {
+	if l == (Limits{}) {
+		return args
+	}
+	return append([]string{"-c", limitsPreamble,
+		strconv.FormatUint(uint64(max(l.MemoryMB, 0))<<20, 10),
+		strconv.Itoa(max(l.CPUSeconds, 0)),
+		strconv.Itoa(l.Nice),
+	}, args...)
+}
+
+// exceededCPU reports whether the process died from its CPU rlimit
+func exceededCPU(state *os.ProcessState, limit int) bool {
+	if state == nil {
+		return false
+	}
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return false
+	}
+	switch status.Signal() {
+	case syscall.SIGXCPU:
+		return true
+	case syscall.SIGKILL:
+		// The hard limit; a SIGKILL from us comes with a cancelled context
+		return state.UserTime()+state.SystemTime() >= time.Duration(limit)*time.Second
+	}
+	return false
+}
+
+// limitCPUFrom caps a running process's CPU time at seconds more than it
+// has used so far, or lifts the cap when seconds is 0. Pool workers live
+// across many requests, so each request gets its budget this way.
+func limitCPUFrom(pid, seconds int) error {
+	var old unix.Rlimit
+	if err := unix.Prlimit(pid, unix.RLIMIT_CPU, nil, &old); err != nil {
+		return err
+	}
+
+	limit := unix.Rlimit{Cur: old.Max, Max: old.Max}
+	if seconds > 0 {
+		used, err := cpuTime(pid)
+		if err != nil {
+			return err
+		}
+		usedSeconds := uint64((used + time.Second - 1) / time.Second)
+		limit.Cur = min(usedSeconds+uint64(seconds), old.Max)
+	}
+	return unix.Prlimit(pid, unix.RLIMIT_CPU, &limit, nil)
+}
+
+// cpuTime returns the user and system CPU time a process has used
+func cpuTime(pid int) (time.Duration, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, err
+	}
+	// utime and stime are fields 14 and 15, in clock ticks of 1/100s;
+	// skip past the parenthesised command name first
+	fields := strings.Fields(string(data[bytes.LastIndexByte(data, ')')+1:]))
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("unexpected /proc/%d/stat format", pid)
+	}
+	var ticks uint64
+	for _, field := range fields[11:13] {
+		n, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		ticks += n
+	}
+	return time.Duration(ticks) * 10 * time.Millisecond, nil
+}
@@ -0,0 +1,132 @@
+//go:build linux
+
+package python
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecute_MemoryLimit(t *testing.T) {
+	r := fakeRunner(t, `x = bytearray(1024 * 1024 * 1024)`)
+	r.policies["predict-child"] = Policy{Timeout: 5 * time.Second, Limits: Limits{MemoryMB: 256}}
+
+	_, err := r.Execute(context.Background(), "predict-child")
+	if !errors.Is(err, ErrResourceLimit) {
+		t.Fatalf("Execute(1GB alloc under 256MB) err = %v, want ErrResourceLimit", err)
+	}
+	if !strings.Contains(err.Error(), "memory limit of 256 MB exceeded") {
+		t.Errorf("Execute err = %q, want the memory limit named", err.Error())
+	}
+}
+
+func TestExecute_CPULimit(t *testing.T) {
+	r := fakeRunner(t, `while True: pass`)
+	r.policies["train-child"] = Policy{Timeout: 5 * time.Second, Limits: Limits{CPUSeconds: 1}}
+
+	_, err := r.Execute(context.Background(), "train-child")
+	if !errors.Is(err, ErrResourceLimit) {
+		t.Fatalf("Execute(busy loop under 1s CPU) err = %v, want ErrResourceLimit", err)
+	}
+	if !strings.Contains(err.Error(), "CPU time limit of 1s exceeded") {
+		t.Errorf("Execute err = %q, want the CPU limit named", err.Error())
+	}
+}
+
+func TestExecute_LimitsInForceFromTheStart(t *testing.T) {
+	// The first line of the command already runs under its limits
+	r := fakeRunner(t, `import json, resource
+print(json.dumps({"cpu": resource.getrlimit(resource.RLIMIT_CPU)[0], "as": resource.getrlimit(resource.RLIMIT_AS)[0]}))`)
+	r.policies["predict-child"] = Policy{Timeout: 5 * time.Second, Limits: Limits{MemoryMB: 2048, CPUSeconds: 30}}
+
+	result, err := r.Execute(context.Background(), "predict-child")
+	if err != nil {
+		t.Fatalf("Execute err = %v", err)
+	}
+	if result.Data["cpu"] != float64(30) || result.Data["as"] != float64(2048<<20) {
+		t.Errorf("limits at startup = %v, want cpu 30 and as %d", result.Data, 2048<<20)
+	}
+}
+
+func TestPool_CPULimitPerRequest(t *testing.T) {
+	p := fakePool(t)
+	p.policies["default"] = Policy{Timeout: 10 * time.Second, Limits: Limits{CPUSeconds: 1}}
+
+	// Each request's budget starts from what the worker has already used
+	if _, err := p.Execute(context.Background(), "ping"); err != nil {
+		t.Fatalf("Execute(ping) err = %v", err)
+	}
+	_, err := p.Execute(context.Background(), "burn")
+	if !errors.Is(err, ErrResourceLimit) {
+		t.Fatalf("Execute(busy loop under 1s CPU) err = %v, want ErrResourceLimit", err)
+	}
+	if !strings.Contains(err.Error(), "CPU time limit of 1s exceeded") {
+		t.Errorf("Execute err = %q, want the CPU limit named", err.Error())
+	}
+	if _, err := p.Execute(context.Background(), "ping"); err != nil {
+		t.Errorf("Execute(after CPU limit) err = %v, want a respawned worker", err)
+	}
+}
+
+func TestExecute_NiceAppliesPerCommandType(t *testing.T) {
+	r := fakeRunner(t, `import json, os; print(json.dumps({"nice": os.nice(0)}))`)
+	r.policies["train-parent"] = Policy{Timeout: 5 * time.Second, Limits: Limits{Nice: 7}}
+
+	train, err := r.Execute(context.Background(), "train-parent")
+	if err != nil {
+		t.Fatalf("Execute(train-parent) err = %v", err)
+	}
+	predict, err := r.Execute(context.Background(), "predict-parent")
+	if err != nil {
+		t.Fatalf("Execute(predict-parent) err = %v", err)
+	}
+
+	base := float64(niceOf(t))
+	if train.Data["nice"] != base+7 || predict.Data["nice"] != base {
+		t.Errorf("nice = train %v, predict %v; want %v and %v", train.Data["nice"], predict.Data["nice"], base+7, base)
+	}
+}
+
+func TestExecute_CancelSendsSIGTERMFirst(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "terminated")
+	r := fakeRunner(t, `import signal, sys, time
+def on_term(*_):
+    open(sys.argv[1], "w").write("clean shutdown")
+    sys.exit(0)
+signal.signal(signal.SIGTERM, on_term)
+print("ready", flush=True)
+time.sleep(30)`)
+	r.grace = 5 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := r.Execute(ctx, marker); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Execute err = %v, want ErrTimeout", err)
+	}
+
+	if data, _ := os.ReadFile(marker); string(data) != "clean shutdown" {
+		t.Errorf("SIGTERM handler did not run, marker = %q", data)
+	}
+}
+
+// niceOf returns this test process's niceness
+func niceOf(t *testing.T) int {
+	t.Helper()
+	data, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		t.Fatalf("read /proc/self/stat: %v", err)
+	}
+	// Field 19 is nice; skip past the parenthesised command name first
+	fields := strings.Fields(string(data[strings.LastIndexByte(string(data), ')')+1:]))
+	nice, err := strconv.Atoi(fields[16])
+	if err != nil {
+		t.Fatalf("parse nice %q: %v", fields[16], err)
+	}
+	return nice
+}
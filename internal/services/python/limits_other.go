@@ -0,0 +1,20 @@
+//go:build !linux
+
+package python
+
+import "os"
+
+// limitedArgs leaves args unchanged where limits are not applied
+func limitedArgs(l Limits, args []string) []string {
+	return args
+}
+
+// limitCPUFrom is a no-op where prlimit is unavailable
+func limitCPUFrom(pid, seconds int) error {
+	return nil
+}
+
+// exceededCPU never reports a violation where limits are not applied
+func exceededCPU(state *os.ProcessState, limit int) bool {
+	return false
+}
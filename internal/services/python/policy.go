@@ -0,0 +1,200 @@
+package python
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+)
+
+// defaultMaxStdout caps the JSON result a command may print
+const defaultMaxStdout = 64 << 20
+
+// baseEnv is always passed through, even with an Env allowlist
+var baseEnv = []string{"PATH", "HOME", "LANG", "LC_ALL", "TMPDIR", "PYTHONPATH", "VIRTUAL_ENV"}
+
+// policyCommands are the ml_cli.py subcommands a policy may be set for
+var policyCommands = map[string]bool{
+	"default":        true,
+	"train-parent":   true,
+	"train-child":    true,
+	"predict-parent": true,
+	"predict-child":  true,
+	"analyze":        true,
+	"monitor-parent": true,
+	"monitor-ticker": true,
+}
+
+// Policy controls how one ml_cli.py subcommand is executed
+type Policy struct {
+	Timeout   time.Duration
+	Retries   int           // extra attempts after a transient failure
+	Backoff   time.Duration // wait before the first retry, doubled after each
+	MaxStdout int           // bytes of result output kept; more is an error
+	Env       []string      // variable names the command sees; nil = all
+	Limits    Limits
+}
+
+// Policies maps subcommands to their policy; "default" covers the rest
+type Policies map[string]Policy
+
+// For returns the policy for a subcommand
+func (p Policies) For(command string) Policy {
+	if policy, ok := p[command]; ok {
+		return policy
+	}
+	return p["default"]
+}
+
+// DefaultPolicies derives the policy table from the flat config: training
+// gets TrainingTimeout and the training limits, reads retry once
+func DefaultPolicies(cfg *config.Config) Policies {
+	base := Policy{
+		Timeout:   time.Duration(cfg.PythonTimeout) * time.Second,
+		Backoff:   2 * time.Second,
+		MaxStdout: defaultMaxStdout,
+		Limits:    inferenceLimits(cfg),
+	}
+
+	training := base
+	training.Timeout = time.Duration(cfg.TrainingTimeout) * time.Second
+	training.Backoff = 30 * time.Second
+	training.Limits = trainingLimits(cfg)
+
+	read := base
+	read.Retries = 1
+
+	return Policies{
+		"default":        base,
+		"train-parent":   training,
+		"train-child":    training,
+		"predict-parent": read,
+		"predict-child":  read,
+		"analyze":        base,
+		"monitor-parent": read,
+		"monitor-ticker": read,
+	}
+}
+
+// policyOverride is one entry of PYTHON_POLICIES; omitted fields keep the default
+type policyOverride struct {
+	TimeoutSeconds  *int     `json:"timeout_seconds"`
+	Retries         *int     `json:"retries"`
+	BackoffSeconds  *float64 `json:"backoff_seconds"`
+	MaxStdoutBytes  *int     `json:"max_stdout_bytes"`
+	Env             []string `json:"env"`
+	MemoryLimitMB   *int     `json:"memory_limit_mb"`
+	CPULimitSeconds *int     `json:"cpu_limit_seconds"`
+	Nice            *int     `json:"nice"`
+}
+
+// LoadPolicies returns the default policies with cfg.PythonPolicies (JSON
+// keyed by subcommand) applied on top
+func LoadPolicies(cfg *config.Config) (Policies, error) {
+	policies := DefaultPolicies(cfg)
+	if strings.TrimSpace(cfg.PythonPolicies) == "" {
+		return policies, nil
+	}
+
+	var overrides map[string]policyOverride
+	if err := json.Unmarshal([]byte(cfg.PythonPolicies), &overrides); err != nil {
+		return policies, fmt.Errorf("invalid PYTHON_POLICIES: %w", err)
+	}
+
+	for command, o := range overrides {
+		if !policyCommands[command] {
+			return policies, fmt.Errorf("invalid PYTHON_POLICIES: unknown command %q", command)
+		}
+
+		p := policies.For(command)
+		if o.TimeoutSeconds != nil {
+			p.Timeout = time.Duration(*o.TimeoutSeconds) * time.Second
+		}
+		if o.Retries != nil {
+			p.Retries = *o.Retries
+		}
+		if o.BackoffSeconds != nil {
+			p.Backoff = time.Duration(*o.BackoffSeconds * float64(time.Second))
+		}
+		if o.MaxStdoutBytes != nil {
+			p.MaxStdout = *o.MaxStdoutBytes
+		}
+		if o.Env != nil {
+			p.Env = o.Env
+		}
+		if o.MemoryLimitMB != nil {
+			p.Limits.MemoryMB = *o.MemoryLimitMB
+		}
+		if o.CPULimitSeconds != nil {
+			p.Limits.CPUSeconds = *o.CPULimitSeconds
+		}
+		if o.Nice != nil {
+			p.Limits.Nice = *o.Nice
+		}
+		policies[command] = p
+	}
+	return policies, nil
+}
+
+// environ filters env down to the policy's allowlist plus baseEnv
+func (p Policy) environ(env []string) []string {
+	if p.Env == nil {
+		return env
+	}
+
+	allowed := make(map[string]bool, len(p.Env)+len(baseEnv))
+	for _, name := range append(append([]string(nil), baseEnv...), p.Env...) {
+		allowed[name] = true
+	}
+
+	filtered := make([]string, 0, len(allowed))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if allowed[name] {
+			filtered = append(filtered, kv)
+		}
+	}
+	return filtered
+}
+
+// isTransient reports whether a failure is worth retrying: flaky market
+// data. A timeout is not; another attempt would keep the caller waiting for
+// the full timeout again.
+func isTransient(err error) bool {
+	return errors.Is(err, ErrDataUnavailable)
+}
+
+// withRetries runs attempt until it succeeds, fails permanently, or the
+// policy's retries are used up, backing off exponentially in between
+func withRetries(ctx context.Context, policy Policy, command string, attempt func() (*Result, error)) (*Result, error) {
+	for try := 0; ; try++ {
+		result, err := attempt()
+		if err == nil || try >= policy.Retries || !isTransient(err) {
+			return result, err
+		}
+
+		delay := policy.Backoff << try
+		log.Printf("Retrying %s in %v after transient failure (retry %d/%d): %v", command, delay, try+1, policy.Retries, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		}
+	}
+}
+
+// command returns the subcommand in args
+func command(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
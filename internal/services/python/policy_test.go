@@ -0,0 +1,124 @@
+package python
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+)
+
+func TestDefaultPolicies_TrainingUsesTrainingTimeout(t *testing.T) {
+	p := DefaultPolicies(&config.Config{PythonTimeout: 120, TrainingTimeout: 7200})
+
+	if got := p.For("train-child").Timeout; got != 2*time.Hour {
+		t.Errorf("train-child timeout = %v, want 2h", got)
+	}
+	if got := p.For("predict-child").Timeout; got != 2*time.Minute {
+		t.Errorf("predict-child timeout = %v, want 2m", got)
+	}
+	if got := p.For("ping").Timeout; got != 2*time.Minute {
+		t.Errorf("unlisted command timeout = %v, want the default 2m", got)
+	}
+}
+
+func TestLoadPolicies_Overrides(t *testing.T) {
+	cfg := &config.Config{
+		PythonTimeout:   120,
+		TrainingTimeout: 7200,
+		PythonPolicies:  `{"train-child": {"timeout_seconds": 600, "retries": 2, "env": ["MLFLOW_TRACKING_URI"], "nice": 5}}`,
+	}
+
+	p, err := LoadPolicies(cfg)
+	if err != nil {
+		t.Fatalf("LoadPolicies err = %v", err)
+	}
+	got := p.For("train-child")
+	if got.Timeout != 10*time.Minute || got.Retries != 2 || got.Limits.Nice != 5 {
+		t.Errorf("train-child = %+v, want 10m timeout, 2 retries, nice 5", got)
+	}
+	if !reflect.DeepEqual(got.Env, []string{"MLFLOW_TRACKING_URI"}) {
+		t.Errorf("train-child env = %v, want [MLFLOW_TRACKING_URI]", got.Env)
+	}
+	if p.For("train-parent").Timeout != 2*time.Hour {
+		t.Errorf("train-parent timeout = %v, want the untouched default", p.For("train-parent").Timeout)
+	}
+}
+
+func TestLoadPolicies_Invalid(t *testing.T) {
+	for _, raw := range []string{`{not json`, `{"train-everything": {"retries": 1}}`} {
+		p, err := LoadPolicies(&config.Config{PythonTimeout: 120, PythonPolicies: raw})
+		if err == nil {
+			t.Errorf("LoadPolicies(%s) err = nil, want error", raw)
+		}
+		if p.For("predict-child").Timeout != 2*time.Minute {
+			t.Errorf("LoadPolicies(%s) did not fall back to defaults", raw)
+		}
+	}
+}
+
+func TestPolicy_EnvironAllowlist(t *testing.T) {
+	env := []string{"PATH=/bin", "FMI_API_KEY=secret", "LLM_MODEL=qwen", "HOME=/root"}
+
+	got := Policy{Env: []string{"LLM_MODEL"}}.environ(env)
+	want := []string{"PATH=/bin", "LLM_MODEL=qwen", "HOME=/root"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("environ = %v, want %v", got, want)
+	}
+	if got := (Policy{}).environ(env); len(got) != len(env) {
+		t.Errorf("environ without allowlist = %v, want everything", got)
+	}
+}
+
+func TestExecute_RetriesTransientFailure(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "attempts")
+	r := fakeRunner(t, `import json, os, sys
+path = sys.argv[2]
+n = int(open(path).read()) + 1 if os.path.exists(path) else 1
+open(path, "w").write(str(n))
+if n == 1:
+    print(json.dumps({"error": "yfinance hiccup", "code": sys.argv[3]}))
+    sys.exit(1)
+print(json.dumps({"attempt": n}))`)
+	r.policies["predict-child"] = Policy{Timeout: 5 * time.Second, Retries: 2, Backoff: 10 * time.Millisecond}
+
+	result, err := r.Execute(context.Background(), "predict-child", counter, CodeDataUnavailable)
+	if err != nil {
+		t.Fatalf("Execute(transient then ok) err = %v, want success on retry", err)
+	}
+	if result.Data["attempt"] != float64(2) {
+		t.Errorf("Execute attempt = %v, want 2", result.Data["attempt"])
+	}
+
+	os.Remove(counter)
+	if _, err := r.Execute(context.Background(), "predict-child", counter, CodeModelMissing); !errors.Is(err, ErrModelMissing) {
+		t.Fatalf("Execute(permanent failure) err = %v, want ErrModelMissing without retry", err)
+	}
+	if data, _ := os.ReadFile(counter); string(data) != "1" {
+		t.Errorf("permanent failure ran %s times, want 1", data)
+	}
+
+	// A timeout is not retried: the caller would wait out the timeout twice
+	os.Remove(counter)
+	if _, err := r.Execute(context.Background(), "predict-child", counter, CodeTimeout); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Execute(timeout) err = %v, want ErrTimeout without retry", err)
+	}
+	if data, _ := os.ReadFile(counter); string(data) != "1" {
+		t.Errorf("timeout ran %s times, want 1", data)
+	}
+}
+
+func TestExecute_MaxStdoutTruncates(t *testing.T) {
+	r := fakeRunner(t, `import json; print(json.dumps({"blob": "x" * 4096}))`)
+	r.policies["default"] = Policy{Timeout: 5 * time.Second, MaxStdout: 1024}
+
+	_, err := r.Execute(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "exceeded 1024 bytes") {
+		t.Fatalf("Execute(oversized output) err = %v, want truncation error", err)
+	}
+}
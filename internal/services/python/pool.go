@@ -0,0 +1,585 @@
+package python
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+)
+
+// poolHealthInterval is how often idle workers are pinged
+const poolHealthInterval = 30 * time.Second
+
+// poolPingTimeout bounds a single health check round trip
+const poolPingTimeout = 10 * time.Second
+
+// errPoolClosed is returned when a worker would be spawned after Close
+var errPoolClosed = errors.New("python worker pool is closed")
+
+// Pool executes Python CLI commands on long-lived `ml_cli.py serve` workers.
+// Requests and responses are single JSON lines on the worker's stdin/stdout,
+// so the interpreter and its imports stay warm between calls. Training runs
+// for hours and is handed to a one-shot Runner instead of holding a worker.
+type Pool struct {
+	pythonPath string
+	scriptPath string
+	env        []string
+	policies   Policies
+	limits     Limits
+	grace      time.Duration
+
+	runner  *Runner
+	archive *Archive
+	workers []*worker
+	idle    chan *worker
+	nextID  atomic.Uint64
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPool creates a worker pool sized from cfg.MaxWorkers. Workers are
+// spawned lazily on first use, or eagerly by Start.
+func NewPool(cfg *config.Config) *Pool {
+	runner := NewRunner(cfg)
+
+	// Workers are shared by every pooled command, so they take the default
+	// policy's memory limit and niceness. CPU time accumulates over a
+	// worker's whole life, so each request gets its own CPU budget instead.
+	limits := runner.policies.For("default").Limits
+	limits.CPUSeconds = 0
+
+	size := cfg.MaxWorkers
+	if size < 1 {
+		size = 1
+	}
+
+	p := &Pool{
+		pythonPath: runner.pythonPath,
+		scriptPath: runner.scriptPath,
+		env:        runner.env,
+		policies:   runner.policies,
+		limits:     limits,
+		grace:      runner.grace,
+		runner:     runner,
+		archive:    runner.archive,
+		idle:       make(chan *worker, size),
+		stop:       make(chan struct{}),
+	}
+
+	for i := 0; i < size; i++ {
+		w := &worker{id: i, pool: p}
+		p.workers = append(p.workers, w)
+		p.idle <- w
+	}
+
+	return p
+}
+
+// Start spawns every worker in the background and begins health checking
+func (p *Pool) Start() {
+	go func() {
+		for range p.workers {
+			w := <-p.idle
+			if err := w.start(); err != nil {
+				log.Printf("Python worker %d failed to start: %v", w.id, err)
+			}
+			p.idle <- w
+		}
+	}()
+	go p.healthLoop()
+}
+
+// Close stops health checks and terminates all workers; none is spawned
+// again afterwards, whether by Start, a health check or a request
+func (p *Pool) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+		for _, w := range p.workers {
+			w.close()
+		}
+	})
+	return nil
+}
+
+// Archive returns where this pool stores execution records
+func (p *Pool) Archive() *Archive {
+	return p.archive
+}
+
+// Execute runs a CLI command on a warm worker under its policy: timeout,
+// retries, env allowlist, CPU limit and output cap apply per request. Every
+// attempt is archived under an execution ID, like Runner.Execute.
+func (p *Pool) Execute(ctx context.Context, args ...string) (*Result, error) {
+	policy := p.policies.For(command(args))
+	return withRetries(ctx, policy, command(args), func() (*Result, error) {
+		return p.attempt(ctx, policy, args)
+	})
+}
+
+// attempt runs the command once and archives the run
+func (p *Pool) attempt(ctx context.Context, policy Policy, args []string) (*Result, error) {
+	record := newExecution(args)
+
+	result, err := p.execute(ctx, policy, record, args)
+
+	record.finish(err)
+	if result != nil {
+		result.ExecutionID = record.ID
+	}
+	if saveErr := p.archive.Save(record); saveErr != nil {
+		log.Printf("Failed to archive execution %s: %v", record.ID, saveErr)
+	}
+
+	return result, err
+}
+
+// execute takes an idle worker and runs one request on it
+func (p *Pool) execute(ctx context.Context, policy Policy, record *Execution, args []string) (*Result, error) {
+	caller := ctx
+	ctx, cancel := context.WithTimeout(ctx, policy.Timeout)
+	defer cancel()
+
+	var w *worker
+	select {
+	case w = <-p.idle:
+	case <-ctx.Done():
+		// The caller gave up, e.g. the client went away; that is not a
+		// capacity problem
+		if err := caller.Err(); err != nil {
+			return nil, err
+		}
+		return nil, newError(CodeTimeout, fmt.Sprintf("no python worker available after %v", policy.Timeout), "")
+	}
+	defer func() { p.idle <- w }()
+
+	if err := w.start(); err != nil {
+		return nil, newError(CodeInternal, fmt.Sprintf("command failed: %v", err), "")
+	}
+
+	// The worker swaps in the command's environment for the request
+	req := poolRequest{Argv: args}
+	if policy.Env != nil {
+		req.Env = envMap(policy.environ(p.env))
+	}
+
+	// CPU time accumulates over the worker's life, so the limit is set
+	// relative to what it has used so far and lifted afterwards
+	if cpu := policy.Limits.CPUSeconds; cpu > 0 {
+		if err := limitCPUFrom(w.cmd.Process.Pid, cpu); err != nil {
+			log.Printf("Failed to apply CPU limit to Python worker %d: %v", w.id, err)
+		}
+		defer func() {
+			if w.alive() {
+				limitCPUFrom(w.cmd.Process.Pid, 0)
+			}
+		}()
+	}
+
+	var stdout bytes.Buffer
+	result, err := w.roundTrip(ctx, req, policy, &stdout)
+	if errors.Is(err, ErrTimeout) {
+		err = newError(CodeTimeout, fmt.Sprintf("command timed out after %v", policy.Timeout), "")
+	}
+
+	// A serve worker has no per-request exit status; report what a one-shot
+	// run would have: 0 on success, 1 on a reported error, -1 if it died
+	switch {
+	case err == nil:
+		record.ExitCode = 0
+	case w.alive():
+		record.ExitCode = 1
+	}
+	record.Stdout = capOutput(stdout.Bytes())
+	record.Stderr = capOutput([]byte(w.stderr.String()))
+
+	return result, err
+}
+
+// healthLoop pings idle workers and replaces any that have died
+func (p *Pool) healthLoop() {
+	ticker := time.NewTicker(poolHealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+
+		for range p.workers {
+			var w *worker
+			select {
+			case w = <-p.idle:
+			default:
+				continue // busy workers are healthy enough
+			}
+			p.check(w)
+			p.idle <- w
+		}
+	}
+}
+
+// check pings a worker, restarting it if it has crashed or stopped answering
+func (p *Pool) check(w *worker) {
+	if w.alive() {
+		ctx, cancel := context.WithTimeout(context.Background(), poolPingTimeout)
+		_, err := w.roundTrip(ctx, poolRequest{Argv: []string{"ping"}}, Policy{}, io.Discard)
+		cancel()
+		if err == nil {
+			return
+		}
+		log.Printf("Python worker %d failed health check: %v", w.id, err)
+		w.kill()
+	} else if !w.started() {
+		return
+	}
+
+	log.Printf("Respawning Python worker %d", w.id)
+	if err := w.start(); err != nil {
+		log.Printf("Python worker %d failed to restart: %v", w.id, err)
+	}
+}
+
+// TrainParent runs the train-parent command in a dedicated process
+func (p *Pool) TrainParent(ctx context.Context) (*Result, error) {
+	return p.runner.TrainParent(ctx)
+}
+
+// TrainChild runs the train-child command in a dedicated process
+func (p *Pool) TrainChild(ctx context.Context, ticker string) (*Result, error) {
+	return p.runner.TrainChild(ctx, ticker)
+}
+
+// PredictParent runs the predict-parent command
+func (p *Pool) PredictParent(ctx context.Context) (*Result, error) {
+	return p.Execute(ctx, "predict-parent")
+}
+
+// PredictChild runs the predict-child command for a specific ticker
+func (p *Pool) PredictChild(ctx context.Context, ticker string) (*Result, error) {
+	return p.Execute(ctx, "predict-child", "--ticker", ticker)
+}
+
+// Analyze runs the analyze command for a specific ticker
+func (p *Pool) Analyze(ctx context.Context, ticker string, threadID string) (*Result, error) {
+	args := []string{"analyze", "--ticker", ticker}
+	if threadID != "" {
+		args = append(args, "--thread-id", threadID)
+	}
+	return p.Execute(ctx, args...)
+}
+
+// MonitorParent runs the monitor-parent command
+func (p *Pool) MonitorParent(ctx context.Context) (*Result, error) {
+	return p.Execute(ctx, "monitor-parent")
+}
+
+// MonitorTicker runs the monitor-ticker command for a specific ticker
+func (p *Pool) MonitorTicker(ctx context.Context, ticker string) (*Result, error) {
+	return p.Execute(ctx, "monitor-ticker", "--ticker", ticker)
+}
+
+// poolRequest is one line written to a worker's stdin
+type poolRequest struct {
+	ID   uint64            `json:"id"`
+	Argv []string          `json:"argv"`
+	Env  map[string]string `json:"env,omitempty"` // the command's whole environment; omitted = the worker's
+}
+
+// envMap turns KEY=value pairs into a map
+func envMap(env []string) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		m[name] = value
+	}
+	return m
+}
+
+// poolResponse is one line read from a worker's stdout
+type poolResponse struct {
+	ID      uint64                 `json:"id"`
+	Data    map[string]interface{} `json:"data"`
+	Error   string                 `json:"error"`
+	Code    string                 `json:"code"`
+	Details string                 `json:"details"`
+}
+
+// worker is a single `ml_cli.py serve` process. A worker is owned by at most
+// one caller at a time (whoever took it from Pool.idle); mu guards its
+// process against being started twice or killed while starting.
+type worker struct {
+	id   int
+	pool *Pool
+
+	mu     sync.Mutex
+	closed bool // set by Pool.Close; start refuses to spawn afterwards
+	cmd    *exec.Cmd
+	term   *groupTerminator
+	stdin  io.WriteCloser
+	lines  chan []byte
+	done   chan struct{}
+	stderr *tailBuffer
+}
+
+// started reports whether the worker has ever been spawned
+func (w *worker) started() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.cmd != nil
+}
+
+// alive reports whether the worker process is running
+func (w *worker) alive() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.running()
+}
+
+// running is alive for callers holding mu
+func (w *worker) running() bool {
+	if w.cmd == nil {
+		return false
+	}
+	select {
+	case <-w.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// start spawns a serve process unless one is already running, so the
+// startup loop, health checks and requests can all call it
+func (w *worker) start() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errPoolClosed
+	}
+	if w.running() {
+		return nil
+	}
+
+	p := w.pool
+	cmd := exec.Command(p.pythonPath, limitedArgs(p.limits, []string{p.scriptPath, "serve"})...)
+	cmd.Env = p.env
+	setProcessGroup(cmd)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr := newTailBuffer(64 * 1024)
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	lines := make(chan []byte)
+	done := make(chan struct{})
+	maxLine := p.maxLine()
+	term := newGroupTerminator(cmd, p.grace)
+
+	// Reader: forwards stdout lines until the process closes its end
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), maxLine)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-done:
+				return
+			}
+		}
+		// A line too long to read would leave the request waiting forever
+		if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+			fmt.Fprintf(stderr, "response line exceeded %d bytes\n", maxLine)
+			term.terminate()
+		}
+	}()
+
+	// Waiter: marks the worker dead when the process exits, after killing
+	// anything it left in its group
+	go func() {
+		term.reapStragglers()
+		cmd.Wait()
+		term.exited()
+		close(done)
+	}()
+
+	w.cmd = cmd
+	w.term = term
+	w.stdin = stdin
+	w.lines = lines
+	w.done = done
+	w.stderr = stderr
+
+	log.Printf("Python worker %d started (pid %d)", w.id, cmd.Process.Pid)
+	return nil
+}
+
+// maxLine is the longest stdout line a worker may write: enough for the
+// largest response any policy allows, so that oversized ones are reported
+// against the policy rather than cut off
+func (p *Pool) maxLine() int {
+	longest := defaultMaxStdout
+	for _, policy := range p.policies {
+		longest = max(longest, policy.MaxStdout)
+	}
+	return longest + 64*1024
+}
+
+// close stops the worker for good
+func (w *worker) close() {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+	w.kill()
+}
+
+// kill terminates the worker's process group (SIGTERM, then SIGKILL after
+// the grace period) and waits for the worker to exit
+func (w *worker) kill() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.running() {
+		return
+	}
+	w.stdin.Close()
+	w.term.terminate()
+	<-w.done
+}
+
+// roundTrip sends one request and waits for the matching response, which
+// must fit in policy.MaxStdout. Every stdout line seen meanwhile is copied
+// to transcript.
+func (w *worker) roundTrip(ctx context.Context, req poolRequest, policy Policy, transcript io.Writer) (*Result, error) {
+	id := w.pool.nextID.Add(1)
+	req.ID = id
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	w.stderr.Reset()
+	if _, err := w.stdin.Write(append(payload, '\n')); err != nil {
+		w.kill()
+		return nil, newError(CodeInternal, "command failed: "+w.crashReason(err), "")
+	}
+
+	onProgress := progressFrom(ctx)
+
+	for {
+		select {
+		case line := <-w.lines:
+			transcript.Write(append(line, '\n'))
+			if p, ok := parseProgress(line); ok {
+				if onProgress != nil && requestID(line) == id {
+					onProgress(p)
+				}
+				continue
+			}
+
+			var resp poolResponse
+			if err := json.Unmarshal(line, &resp); err != nil || resp.ID != id {
+				// Stray output or a reply to an abandoned request
+				continue
+			}
+			if policy.MaxStdout > 0 && len(line) > policy.MaxStdout {
+				return nil, newError(CodeInternal, fmt.Sprintf("command output exceeded %d bytes", policy.MaxStdout), "")
+			}
+			if resp.Error != "" {
+				if violation := limitViolation(nil, []byte(resp.Details), w.pool.limits); violation != "" {
+					return nil, newError(CodeResourceLimit, resp.Error+" ("+violation+")", resp.Details)
+				}
+				return nil, newError(resp.Code, resp.Error, resp.Details)
+			}
+			return &Result{Data: resp.Data}, nil
+
+		case <-w.done:
+			if cpu := policy.Limits.CPUSeconds; cpu > 0 && exceededCPU(w.cmd.ProcessState, cpu) {
+				return nil, newError(CodeResourceLimit, fmt.Sprintf("command failed: CPU time limit of %ds exceeded", cpu), w.stderr.String())
+			}
+			return nil, newError(CodeInternal, "command failed: "+w.crashReason(errors.New("worker exited")), "")
+
+		case <-ctx.Done():
+			// The worker is mid-request; its state is unknown, so replace it
+			w.kill()
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, newError(CodeTimeout, "command timed out", "")
+			}
+			return nil, fmt.Errorf("command cancelled: %w", context.Canceled)
+		}
+	}
+}
+
+// requestID extracts the id field from a protocol line
+func requestID(line []byte) uint64 {
+	var msg struct {
+		ID uint64 `json:"id"`
+	}
+	json.Unmarshal(line, &msg)
+	return msg.ID
+}
+
+// crashReason prefers the worker's stderr over the raw I/O error
+func (w *worker) crashReason(err error) string {
+	if tail := w.stderr.String(); tail != "" {
+		return tail
+	}
+	return err.Error()
+}
+
+// tailBuffer is an io.Writer that keeps only the last max bytes written
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func newTailBuffer(max int) *tailBuffer {
+	return &tailBuffer{max: max}
+}
+
+func (t *tailBuffer) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, b...)
+	if len(t.buf) > t.max {
+		t.buf = t.buf[len(t.buf)-t.max:]
+	}
+	return len(b), nil
+}
+
+func (t *tailBuffer) Reset() {
+	t.mu.Lock()
+	t.buf = t.buf[:0]
+	t.mu.Unlock()
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
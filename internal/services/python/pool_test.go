@@ -0,0 +1,238 @@
+package python
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServeScript answers pool requests the way `ml_cli.py serve` does,
+// including running each with the environment the request carries.
+// "crash" exits the worker, "sleep" never answers, "burn" spins on the
+// CPU, "fail" returns an error and "big" a 1 KB result.
+const fakeServeScript = `
+import json, os, sys, time
+for line in sys.stdin:
+    req = json.loads(line)
+    cmd = req["argv"][0]
+    env = req.get("env", os.environ)
+    if cmd == "crash":
+        os._exit(3)
+    if cmd == "sleep":
+        time.sleep(30)
+    if cmd == "burn":
+        while True:
+            pass
+    resp = {"id": req["id"]}
+    if cmd == "fail":
+        resp["error"] = "model missing"
+    elif cmd == "big":
+        resp["data"] = {"blob": "x" * 1024}
+    else:
+        resp["data"] = {"pid": os.getpid(), "cmd": cmd, "secret": env.get("POOL_TEST_SECRET")}
+    print("noise on stdout")
+    print(json.dumps(resp), flush=True)
+`
+
+// fakePool builds a single-worker Pool backed by fakeServeScript.
+func fakePool(t *testing.T) *Pool {
+	t.Helper()
+
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "fake_serve.py")
+	if err := os.WriteFile(scriptPath, []byte(fakeServeScript), 0755); err != nil {
+		t.Fatalf("fakePool: write script: %v", err)
+	}
+
+	p := &Pool{
+		pythonPath: "python3",
+		scriptPath: scriptPath,
+		env:        os.Environ(),
+		policies:   Policies{"default": {Timeout: 5 * time.Second}},
+		archive:    &Archive{dir: filepath.Join(dir, "executions"), max: 10},
+		idle:       make(chan *worker, 1),
+		stop:       make(chan struct{}),
+	}
+	w := &worker{id: 0, pool: p}
+	p.workers = []*worker{w}
+	p.idle <- w
+
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+func TestPool_ReusesWorker(t *testing.T) {
+	p := fakePool(t)
+
+	first, err := p.Execute(context.Background(), "predict-child", "--ticker", "AAPL")
+	if err != nil {
+		t.Fatalf("Execute(first) err = %v", err)
+	}
+	second, err := p.Execute(context.Background(), "predict-child", "--ticker", "MSFT")
+	if err != nil {
+		t.Fatalf("Execute(second) err = %v", err)
+	}
+	if first.Data["pid"] != second.Data["pid"] {
+		t.Errorf("Execute pids = %v, %v, want the same warm worker", first.Data["pid"], second.Data["pid"])
+	}
+	if second.Data["cmd"] != "predict-child" {
+		t.Errorf("Execute data = %v, want cmd=predict-child", second.Data)
+	}
+}
+
+func TestPool_ErrorField(t *testing.T) {
+	p := fakePool(t)
+
+	_, err := p.Execute(context.Background(), "fail")
+	if err == nil {
+		t.Fatal("Execute(fail) err = nil, want error")
+	}
+	if err.Error() != "model missing" {
+		t.Errorf("Execute(fail) err = %q, want \"model missing\"", err.Error())
+	}
+
+	var cliErr *Error
+	if !errors.As(err, &cliErr) {
+		t.Fatalf("Execute(fail) err = %T, want *Error", err)
+	}
+	record, err := p.Archive().Get(cliErr.ExecutionID)
+	if err != nil {
+		t.Fatalf("Archive().Get(%q) err = %v", cliErr.ExecutionID, err)
+	}
+	if record.ExitCode != 1 || !strings.Contains(record.Stdout, "noise on stdout") {
+		t.Errorf("record = %+v, want exit code 1 and the worker's stdout", record)
+	}
+}
+
+func TestPool_RespawnsAfterCrash(t *testing.T) {
+	p := fakePool(t)
+
+	before, err := p.Execute(context.Background(), "ping")
+	if err != nil {
+		t.Fatalf("Execute(ping) err = %v", err)
+	}
+	if _, err := p.Execute(context.Background(), "crash"); err == nil {
+		t.Fatal("Execute(crash) err = nil, want error")
+	}
+	after, err := p.Execute(context.Background(), "ping")
+	if err != nil {
+		t.Fatalf("Execute(ping after crash) err = %v", err)
+	}
+	if before.Data["pid"] == after.Data["pid"] {
+		t.Error("Execute after crash reused the dead worker, want a respawned one")
+	}
+}
+
+func TestPool_TimeoutReplacesWorker(t *testing.T) {
+	p := fakePool(t)
+	p.policies["default"] = Policy{Timeout: 200 * time.Millisecond}
+
+	if _, err := p.Execute(context.Background(), "sleep"); err == nil {
+		t.Fatal("Execute(sleep) err = nil, want timeout error")
+	}
+
+	p.policies["default"] = Policy{Timeout: 5 * time.Second}
+	if _, err := p.Execute(context.Background(), "ping"); err != nil {
+		t.Fatalf("Execute(after timeout) err = %v, want a fresh worker", err)
+	}
+}
+
+func TestPool_EnvAllowlistPerCommand(t *testing.T) {
+	p := fakePool(t)
+	p.env = append(p.env, "POOL_TEST_SECRET=hunter2")
+	p.policies["predict-child"] = Policy{Timeout: 5 * time.Second, Env: []string{"MLFLOW_TRACKING_URI"}}
+
+	restricted, err := p.Execute(context.Background(), "predict-child")
+	if err != nil {
+		t.Fatalf("Execute(predict-child) err = %v", err)
+	}
+	open, err := p.Execute(context.Background(), "analyze")
+	if err != nil {
+		t.Fatalf("Execute(analyze) err = %v", err)
+	}
+	if restricted.Data["secret"] != nil {
+		t.Errorf("predict-child saw POOL_TEST_SECRET = %v, want it filtered out", restricted.Data["secret"])
+	}
+	if open.Data["secret"] != "hunter2" {
+		t.Errorf("analyze saw POOL_TEST_SECRET = %v, want hunter2", open.Data["secret"])
+	}
+}
+
+func TestPool_MaxStdout(t *testing.T) {
+	p := fakePool(t)
+	p.policies["default"] = Policy{Timeout: 5 * time.Second, MaxStdout: 512}
+
+	_, err := p.Execute(context.Background(), "big")
+	if err == nil || !strings.Contains(err.Error(), "exceeded 512 bytes") {
+		t.Fatalf("Execute(1 KB result under 512 B) err = %v, want output cap error", err)
+	}
+	if _, err := p.Execute(context.Background(), "ping"); err != nil {
+		t.Errorf("Execute(after oversized result) err = %v, want the worker still usable", err)
+	}
+}
+
+func TestPool_StartIsIdempotent(t *testing.T) {
+	p := fakePool(t)
+	w := p.workers[0]
+
+	if err := w.start(); err != nil {
+		t.Fatalf("start err = %v", err)
+	}
+	pid := w.cmd.Process.Pid
+	if err := w.start(); err != nil {
+		t.Fatalf("start(again) err = %v", err)
+	}
+	if w.cmd.Process.Pid != pid {
+		t.Errorf("start(again) spawned pid %d, want the running worker %d kept", w.cmd.Process.Pid, pid)
+	}
+}
+
+func TestPool_CallerCancelWhileWaiting(t *testing.T) {
+	p := fakePool(t)
+
+	// Hold the only worker
+	w := <-p.idle
+	defer func() { p.idle <- w }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := p.Execute(ctx, "ping")
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrTimeout) {
+		t.Errorf("Execute(caller gave up waiting) err = %v, want the caller's context error", err)
+	}
+}
+
+func TestPool_NoWorkerSurvivesClose(t *testing.T) {
+	p := fakePool(t)
+
+	// Close while the startup loop and several requests race to spawn
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Execute(context.Background(), "ping")
+		}()
+	}
+	p.Start()
+	p.Close()
+	wg.Wait()
+	time.Sleep(100 * time.Millisecond) // let the startup loop finish
+
+	w := p.workers[0]
+	p.check(w)
+	if w.alive() {
+		t.Errorf("worker pid %d running after Close", w.cmd.Process.Pid)
+	}
+	if _, err := p.Execute(context.Background(), "ping"); !errors.Is(err, ErrInternal) {
+		t.Errorf("Execute after Close err = %v, want ErrInternal", err)
+	}
+	if w.alive() {
+		t.Errorf("Execute after Close spawned worker pid %d", w.cmd.Process.Pid)
+	}
+}
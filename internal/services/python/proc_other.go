@@ -0,0 +1,31 @@
+//go:build !unix
+
+package python
+
+import (
+	"os/exec"
+	"time"
+)
+
+// setProcessGroup is a no-op where process groups are unavailable
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// groupTerminator kills the direct child; there is no SIGTERM here
+type groupTerminator struct {
+	cmd *exec.Cmd
+}
+
+func newGroupTerminator(cmd *exec.Cmd, grace time.Duration) *groupTerminator {
+	return &groupTerminator{cmd: cmd}
+}
+
+func (t *groupTerminator) terminate() error {
+	if t.cmd.Process == nil {
+		return nil
+	}
+	return t.cmd.Process.Kill()
+}
+
+func (t *groupTerminator) reapStragglers() {}
+
+func (t *groupTerminator) exited() {}
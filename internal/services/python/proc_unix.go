@@ -0,0 +1,82 @@
+//go:build unix
+
+package python
+
+import (
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup runs cmd in its own process group so that killing it
+// also kills any children it spawned (torch data-loader workers, feast, ...)
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// groupTerminator stops cmd's process group: SIGTERM so Python can clean
+// up, then SIGKILL once the grace period has passed. The group is named by
+// the leader's pid, which the OS may reuse once the leader is reaped, so
+// the group is only ever signalled before that: call reapStragglers, then
+// Wait, then exited, which drops a SIGKILL still pending.
+type groupTerminator struct {
+	cmd   *exec.Cmd
+	grace time.Duration
+	kill  func(pid int, sig syscall.Signal) error
+
+	mu     sync.Mutex
+	timer  *time.Timer
+	reaped bool
+}
+
+func newGroupTerminator(cmd *exec.Cmd, grace time.Duration) *groupTerminator {
+	return &groupTerminator{cmd: cmd, grace: grace, kill: syscall.Kill}
+}
+
+// signal sends sig to the group unless the leader has been reaped
+func (t *groupTerminator) signal(sig syscall.Signal) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.reaped || t.cmd.Process == nil {
+		return nil
+	}
+	return t.kill(-t.cmd.Process.Pid, sig)
+}
+
+// terminate sends SIGTERM to the group and schedules the SIGKILL
+func (t *groupTerminator) terminate() error {
+	err := t.signal(syscall.SIGTERM)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer == nil && !t.reaped {
+		t.timer = time.AfterFunc(t.grace, func() { t.signal(syscall.SIGKILL) })
+	}
+	return err
+}
+
+// reapStragglers waits for the leader to exit, without reaping it, and
+// SIGKILLs whatever is left in its group, such as data-loader workers.
+// The unreaped leader keeps its pid, and so the group's ID, from being
+// reused meanwhile. Where that wait is unavailable, stragglers are left.
+func (t *groupTerminator) reapStragglers() {
+	t.mu.Lock()
+	reaped := t.reaped
+	t.mu.Unlock()
+	if reaped || t.cmd.Process == nil || waitExited(t.cmd.Process.Pid) != nil {
+		return
+	}
+	t.signal(syscall.SIGKILL)
+}
+
+// exited records that the leader has been reaped and cancels any pending
+// SIGKILL
+func (t *groupTerminator) exited() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reaped = true
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
@@ -0,0 +1,180 @@
+//go:build linux
+
+package python
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// processGone reports whether pid has exited (missing or a zombie).
+func processGone(pid int) bool {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(data))
+	return len(fields) > 2 && fields[2] == "Z"
+}
+
+func TestExecute_CancelKillsProcessGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	r := fakeRunner(t, `import subprocess, sys, time
+child = subprocess.Popen(["sleep", "30"])
+open(sys.argv[1], "w").write(str(child.pid))
+time.sleep(30)`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.Execute(ctx, pidFile)
+		done <- err
+	}()
+
+	var childPID int
+	for i := 0; i < 100 && childPID == 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+		if data, err := os.ReadFile(pidFile); err == nil && len(data) > 0 {
+			childPID, _ = strconv.Atoi(string(data))
+		}
+	}
+	if childPID == 0 {
+		t.Fatal("helper script never reported its child pid")
+	}
+
+	cancel()
+	if err := <-done; err == nil {
+		t.Fatal("Execute(cancelled) err = nil, want error")
+	}
+
+	for i := 0; i < 50 && !processGone(childPID); i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if !processGone(childPID) {
+		t.Errorf("grandchild %d still running after cancel, want process group killed", childPID)
+	}
+}
+
+func TestGroupTerminator_KillsAfterGraceUntilReaped(t *testing.T) {
+	// Ignores SIGTERM, so only the SIGKILL after the grace period stops it
+	cmd := exec.Command("python3", "-c", `import signal, time
+signal.signal(signal.SIGTERM, signal.SIG_IGN)
+print("ready", flush=True)
+time.sleep(30)`)
+	setProcessGroup(cmd)
+	stdout, _ := cmd.StdoutPipe()
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	bufio.NewReader(stdout).ReadString('\n')
+
+	term := newGroupTerminator(cmd, 100*time.Millisecond)
+	start := time.Now()
+	term.terminate()
+	cmd.Wait()
+	term.exited()
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("process exited after %v, want it to outlive SIGTERM until the grace period", elapsed)
+	}
+
+	// Once reaped, the group is not ours to signal
+	if err := term.terminate(); err != nil {
+		t.Errorf("terminate after exited err = %v, want a no-op", err)
+	}
+}
+
+func TestGroupTerminator_ExitedCancelsPendingKill(t *testing.T) {
+	cmd := exec.Command("python3", "-c", `import time; time.sleep(30)`)
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	term := newGroupTerminator(cmd, time.Hour)
+	term.terminate()
+	cmd.Wait()
+	term.exited()
+
+	term.mu.Lock()
+	defer term.mu.Unlock()
+	if !term.reaped || term.timer.Stop() {
+		t.Error("exited left the SIGKILL pending; it would hit whatever reuses the pgid")
+	}
+}
+
+func TestExecute_KillsStragglersAfterNormalExit(t *testing.T) {
+	// The leader exits at once, leaving a child that still holds stdout
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	r := fakeRunner(t, `import json, subprocess, sys
+child = subprocess.Popen(["sleep", "30"])
+open(sys.argv[1], "w").write(str(child.pid))
+print(json.dumps({"status": "ok"}))`)
+	r.grace = 5 * time.Second
+
+	start := time.Now()
+	if _, err := r.Execute(context.Background(), pidFile); err != nil {
+		t.Fatalf("Execute err = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > r.grace {
+		t.Errorf("Execute took %v, want it not to wait on the straggler's pipe", elapsed)
+	}
+
+	data, _ := os.ReadFile(pidFile)
+	childPID, _ := strconv.Atoi(string(data))
+	for i := 0; i < 50 && !processGone(childPID); i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if childPID == 0 || !processGone(childPID) {
+		t.Errorf("straggler %d still running after its leader exited", childPID)
+	}
+}
+
+func TestGroupTerminator_NeverSignalsAfterExited(t *testing.T) {
+	cmd := exec.Command("python3", "-c", `import time; time.sleep(30)`)
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	var mu sync.Mutex
+	var reaped bool
+	var late []syscall.Signal
+	term := newGroupTerminator(cmd, 50*time.Millisecond)
+	kill := term.kill
+	term.kill = func(pid int, sig syscall.Signal) error {
+		mu.Lock()
+		if reaped {
+			late = append(late, sig)
+		}
+		mu.Unlock()
+		return kill(pid, sig)
+	}
+
+	term.terminate()
+	term.reapStragglers()
+	cmd.Wait()
+	term.exited()
+	mu.Lock()
+	reaped = true
+	mu.Unlock()
+
+	// Neither the pending SIGKILL nor later calls may reach the old group
+	term.terminate()
+	term.reapStragglers()
+	time.Sleep(150 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(late) > 0 {
+		t.Errorf("signals sent after the leader was reaped: %v", late)
+	}
+}
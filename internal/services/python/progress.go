@@ -0,0 +1,139 @@
+package python
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+// Progress is an intermediate event printed by a long-running command
+// (one JSON line with "event": "progress") before its final result.
+type Progress struct {
+	Phase       string  `json:"phase"`
+	Epoch       int     `json:"epoch,omitempty"`
+	TotalEpochs int     `json:"total_epochs,omitempty"`
+	Loss        float64 `json:"loss,omitempty"`
+	ValMSE      float64 `json:"val_mse,omitempty"`
+	Percent     float64 `json:"percent"`
+}
+
+// Phase weights used to turn a progress event into percent complete:
+// ingest covers 0-10%, train 10-90% by epoch, evaluate 90-100%.
+var phaseStart = map[string]float64{
+	"ingest":   0,
+	"train":    10,
+	"evaluate": 90,
+}
+
+// percentComplete estimates overall completion from the phase and epoch
+func (p Progress) percentComplete() float64 {
+	start, ok := phaseStart[p.Phase]
+	if !ok {
+		return 0
+	}
+	if p.Phase == "train" && p.TotalEpochs > 0 {
+		return start + 80*float64(p.Epoch)/float64(p.TotalEpochs)
+	}
+	return start
+}
+
+// ProgressFunc receives progress events as they are printed
+type ProgressFunc func(Progress)
+
+type progressKey struct{}
+
+// WithProgress returns a context whose commands report progress to fn.
+// Runners that cannot stream progress simply ignore it.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressFrom returns the progress callback carried by ctx, if any
+func progressFrom(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// progressEvent is the wire form of a progress line
+type progressEvent struct {
+	Event string `json:"event"`
+	Progress
+}
+
+// parseProgress reports whether line is a progress event and decodes it
+func parseProgress(line []byte) (Progress, bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' || !bytes.Contains(line, []byte(`"progress"`)) {
+		return Progress{}, false
+	}
+	var ev progressEvent
+	if err := json.Unmarshal(line, &ev); err != nil || ev.Event != "progress" {
+		return Progress{}, false
+	}
+	ev.Progress.Percent = ev.Progress.percentComplete()
+	return ev.Progress, true
+}
+
+// progressWriter splits stdout into lines, hands progress events to fn and
+// keeps everything else as the command's output
+type progressWriter struct {
+	fn        ProgressFunc
+	max       int // cap on non-progress output; 0 = unlimited
+	out       bytes.Buffer
+	raw       bytes.Buffer
+	partial   []byte
+	truncated bool
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	if w.max <= 0 || w.raw.Len() < w.max {
+		w.raw.Write(b)
+	}
+	w.partial = append(w.partial, b...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.line(w.partial[:i+1])
+		w.partial = w.partial[i+1:]
+	}
+	if w.max > 0 && len(w.partial) > w.max {
+		w.truncated = true
+		w.partial = nil
+	}
+	return len(b), nil
+}
+
+func (w *progressWriter) line(line []byte) {
+	if p, ok := parseProgress(line); ok {
+		if w.fn != nil {
+			w.fn(p)
+		}
+		return
+	}
+	if w.max > 0 && w.out.Len()+len(line) > w.max {
+		w.truncated = true
+		line = line[:max(w.max-w.out.Len(), 0)]
+	}
+	w.out.Write(line)
+}
+
+// Bytes returns the non-progress output, including any unterminated last line
+func (w *progressWriter) Bytes() []byte {
+	if len(w.partial) > 0 {
+		w.line(w.partial)
+		w.partial = nil
+	}
+	return w.out.Bytes()
+}
+
+// Raw returns everything written, progress lines included
+func (w *progressWriter) Raw() []byte {
+	return w.raw.Bytes()
+}
+
+// Truncated reports whether output beyond max was dropped
+func (w *progressWriter) Truncated() bool {
+	return w.truncated
+}
@@ -0,0 +1,353 @@
+package python
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+)
+
+// remoteHealthInterval is how often worker endpoints are probed
+const remoteHealthInterval = 10 * time.Second
+
+// remoteHealthTimeout bounds a single health probe
+const remoteHealthTimeout = 5 * time.Second
+
+// remoteArchiveTimeout bounds fetching one execution record from a worker
+const remoteArchiveTimeout = 10 * time.Second
+
+// remoteJob is the body of POST /execute on a worker
+type remoteJob struct {
+	Command  string `json:"command"`
+	Ticker   string `json:"ticker,omitempty"`
+	ThreadID string `json:"thread_id,omitempty"`
+}
+
+// remoteEvent is one NDJSON line streamed back by a worker: "accepted", any
+// number of "progress" events, then exactly one "result" or "error"
+type remoteEvent struct {
+	Event       string                 `json:"event"`
+	Progress    *Progress              `json:"progress,omitempty"`
+	Data        map[string]interface{} `json:"data,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	Code        string                 `json:"code,omitempty"`
+	Details     string                 `json:"details,omitempty"`
+	ExecutionID string                 `json:"execution_id,omitempty"`
+}
+
+// errWorkerBusy marks a worker that refused a job because it is at capacity
+var errWorkerBusy = errors.New("worker busy")
+
+// RemoteRunner executes Python CLI commands on cmd/worker hosts over HTTP.
+// Jobs go round-robin to healthy endpoints; if a worker cannot be reached
+// or is full, the job fails over to the next one. A job is never retried
+// once a worker has accepted it.
+type RemoteRunner struct {
+	endpoints []*remoteEndpoint
+	next      atomic.Uint64
+	client    *http.Client
+	token     string // sent to workers as a bearer token
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// remoteEndpoint is one worker base URL and its last known health
+type remoteEndpoint struct {
+	url     string
+	healthy atomic.Bool
+}
+
+// NewRemoteRunner creates a runner for cfg.WorkerEndpoints. Endpoints are
+// assumed healthy until a probe or request says otherwise.
+func NewRemoteRunner(cfg *config.Config) *RemoteRunner {
+	r := &RemoteRunner{
+		// No client timeout: training streams for hours, the worker enforces limits
+		client: &http.Client{},
+		token:  cfg.WorkerToken,
+		stop:   make(chan struct{}),
+	}
+	for _, url := range cfg.WorkerEndpoints {
+		ep := &remoteEndpoint{url: strings.TrimRight(url, "/")}
+		ep.healthy.Store(true)
+		r.endpoints = append(r.endpoints, ep)
+	}
+	if len(r.endpoints) == 0 {
+		log.Println("Warning: EXECUTION_MODE=remote but WORKER_ENDPOINTS is empty")
+	}
+	if r.token == "" {
+		log.Println("Warning: EXECUTION_MODE=remote but WORKER_TOKEN is empty; workers will refuse every job")
+	}
+	return r
+}
+
+// Start begins periodic health probes of every endpoint
+func (r *RemoteRunner) Start() {
+	go func() {
+		ticker := time.NewTicker(remoteHealthInterval)
+		defer ticker.Stop()
+
+		for {
+			r.probeAll()
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops health probes
+func (r *RemoteRunner) Close() error {
+	r.closeOnce.Do(func() { close(r.stop) })
+	return nil
+}
+
+// probeAll refreshes the health of every endpoint
+func (r *RemoteRunner) probeAll() {
+	for _, ep := range r.endpoints {
+		healthy := r.probe(ep)
+		if was := ep.healthy.Swap(healthy); was != healthy {
+			log.Printf("Worker %s healthy=%v", ep.url, healthy)
+		}
+	}
+}
+
+// probe calls GET /health on a worker
+func (r *RemoteRunner) probe(ep *remoteEndpoint) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteHealthTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ep.url+"/health", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// candidates orders endpoints for one job: healthy ones round-robin first,
+// then unhealthy ones as a last resort in case a probe was stale
+func (r *RemoteRunner) candidates() []*remoteEndpoint {
+	n := len(r.endpoints)
+	if n == 0 {
+		return nil
+	}
+
+	start := int(r.next.Add(1) % uint64(n))
+	var healthy, unhealthy []*remoteEndpoint
+	for i := 0; i < n; i++ {
+		ep := r.endpoints[(start+i)%n]
+		if ep.healthy.Load() {
+			healthy = append(healthy, ep)
+		} else {
+			unhealthy = append(unhealthy, ep)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// run sends a job to the first worker that accepts it and streams the result
+func (r *RemoteRunner) run(ctx context.Context, job remoteJob) (*Result, error) {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error = errors.New("no worker endpoints configured")
+	for _, ep := range r.candidates() {
+		resp, err := r.submit(ctx, ep, payload)
+		if err == nil {
+			defer resp.Body.Close()
+			return r.stream(ctx, ep, resp.Body)
+		}
+		if ctx.Err() != nil {
+			return nil, contextError(ctx)
+		}
+
+		if !errors.Is(err, errWorkerBusy) {
+			ep.healthy.Store(false)
+		}
+		log.Printf("Worker %s rejected %s: %v", ep.url, job.Command, err)
+		lastErr = err
+	}
+
+	return nil, newError(CodeInternal, "no worker available: "+lastErr.Error(), "")
+}
+
+// submit posts a job; a non-nil error means the worker did not accept it
+func (r *RemoteRunner) submit(ctx context.Context, ep *remoteEndpoint, payload []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.url+"/execute", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	r.authorize(req)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return nil, errWorkerBusy
+	}
+	return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// authorize adds the shared worker token to a request
+func (r *RemoteRunner) authorize(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+r.token)
+}
+
+// stream reads progress events until the final result or error
+func (r *RemoteRunner) stream(ctx context.Context, ep *remoteEndpoint, body io.Reader) (*Result, error) {
+	onProgress := progressFrom(ctx)
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var event remoteEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+
+		switch event.Event {
+		case "progress":
+			if onProgress != nil && event.Progress != nil {
+				onProgress(*event.Progress)
+			}
+		case "result":
+			return &Result{Data: event.Data, ExecutionID: event.ExecutionID}, nil
+		case "error":
+			cliErr := newError(event.Code, event.Error, event.Details)
+			cliErr.ExecutionID = event.ExecutionID
+			return nil, cliErr
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil, contextError(ctx)
+	}
+	return nil, newError(CodeInternal, fmt.Sprintf("command failed: lost connection to worker %s", ep.url), "")
+}
+
+// Get fetches an archived execution record from whichever worker ran it.
+// Execution IDs do not name their worker, so every endpoint is asked.
+func (r *RemoteRunner) Get(id string) (*Execution, error) {
+	if !executionIDPattern.MatchString(id) {
+		return nil, ErrExecutionNotFound
+	}
+
+	var lastErr error
+	for _, ep := range r.endpoints {
+		execution, err := r.fetchExecution(ep, id)
+		if err == nil {
+			return execution, nil
+		}
+		if !errors.Is(err, ErrExecutionNotFound) {
+			lastErr = fmt.Errorf("worker %s: %w", ep.url, err)
+		}
+	}
+	// A record missing everywhere may be on a worker that is down
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, ErrExecutionNotFound
+}
+
+// fetchExecution calls GET /executions/{id} on a worker
+func (r *RemoteRunner) fetchExecution(ep *remoteEndpoint, id string) (*Execution, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteArchiveTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ep.url+"/executions/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.authorize(req)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrExecutionNotFound
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var execution Execution
+	if err := json.NewDecoder(resp.Body).Decode(&execution); err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// contextError converts a finished context into the runner's usual errors
+func contextError(ctx context.Context) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return newError(CodeTimeout, "command timed out", "")
+	}
+	return fmt.Errorf("command cancelled: %w", context.Canceled)
+}
+
+// TrainParent runs the train-parent command on a worker
+func (r *RemoteRunner) TrainParent(ctx context.Context) (*Result, error) {
+	return r.run(ctx, remoteJob{Command: "train-parent"})
+}
+
+// TrainChild runs the train-child command on a worker
+func (r *RemoteRunner) TrainChild(ctx context.Context, ticker string) (*Result, error) {
+	return r.run(ctx, remoteJob{Command: "train-child", Ticker: ticker})
+}
+
+// PredictParent runs the predict-parent command on a worker
+func (r *RemoteRunner) PredictParent(ctx context.Context) (*Result, error) {
+	return r.run(ctx, remoteJob{Command: "predict-parent"})
+}
+
+// PredictChild runs the predict-child command on a worker
+func (r *RemoteRunner) PredictChild(ctx context.Context, ticker string) (*Result, error) {
+	return r.run(ctx, remoteJob{Command: "predict-child", Ticker: ticker})
+}
+
+// Analyze runs the analyze command on a worker
+func (r *RemoteRunner) Analyze(ctx context.Context, ticker string, threadID string) (*Result, error) {
+	return r.run(ctx, remoteJob{Command: "analyze", Ticker: ticker, ThreadID: threadID})
+}
+
+// MonitorParent runs the monitor-parent command on a worker
+func (r *RemoteRunner) MonitorParent(ctx context.Context) (*Result, error) {
+	return r.run(ctx, remoteJob{Command: "monitor-parent"})
+}
+
+// MonitorTicker runs the monitor-ticker command on a worker
+func (r *RemoteRunner) MonitorTicker(ctx context.Context, ticker string) (*Result, error) {
+	return r.run(ctx, remoteJob{Command: "monitor-ticker", Ticker: ticker})
+}
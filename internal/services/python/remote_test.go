@@ -0,0 +1,232 @@
+package python
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+)
+
+// stubRunner answers every command with its name, or with err if set.
+// Train commands report one progress event and block until release closes.
+type stubRunner struct {
+	err     error
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (s *stubRunner) reply(ctx context.Context, cmd string) (*Result, error) {
+	s.calls.Add(1)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &Result{Data: map[string]interface{}{"cmd": cmd}, ExecutionID: "exec-1"}, nil
+}
+
+func (s *stubRunner) train(ctx context.Context, cmd string) (*Result, error) {
+	if fn := progressFrom(ctx); fn != nil {
+		fn(Progress{Phase: "train", Epoch: 1, TotalEpochs: 2, Percent: 50})
+	}
+	if s.release != nil {
+		select {
+		case <-s.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return s.reply(ctx, cmd)
+}
+
+func (s *stubRunner) TrainParent(ctx context.Context) (*Result, error) {
+	return s.train(ctx, "train-parent")
+}
+func (s *stubRunner) TrainChild(ctx context.Context, _ string) (*Result, error) {
+	return s.train(ctx, "train-child")
+}
+func (s *stubRunner) PredictParent(ctx context.Context) (*Result, error) {
+	return s.reply(ctx, "predict-parent")
+}
+func (s *stubRunner) PredictChild(ctx context.Context, _ string) (*Result, error) {
+	return s.reply(ctx, "predict-child")
+}
+func (s *stubRunner) Analyze(ctx context.Context, _, _ string) (*Result, error) {
+	return s.reply(ctx, "analyze")
+}
+func (s *stubRunner) MonitorParent(ctx context.Context) (*Result, error) {
+	return s.reply(ctx, "monitor-parent")
+}
+func (s *stubRunner) MonitorTicker(ctx context.Context, _ string) (*Result, error) {
+	return s.reply(ctx, "monitor-ticker")
+}
+
+// workerConfig returns a one-slot worker configuration archiving under a
+// temp dir, with the token remoteRunnerFor sends
+func workerConfig(t *testing.T) *config.Config {
+	return &config.Config{MaxWorkers: 1, LogsDir: t.TempDir(), WorkerToken: "secret"}
+}
+
+// remoteRunnerFor builds a RemoteRunner pointed at the given base URLs
+func remoteRunnerFor(urls ...string) *RemoteRunner {
+	return NewRemoteRunner(&config.Config{WorkerEndpoints: urls, WorkerToken: "secret"})
+}
+
+func TestRemoteRunner_StreamsProgressAndResult(t *testing.T) {
+	worker := httptest.NewServer(NewWorkerServer(workerConfig(t), &stubRunner{}))
+	defer worker.Close()
+
+	var got []Progress
+	ctx := WithProgress(context.Background(), func(p Progress) { got = append(got, p) })
+
+	result, err := remoteRunnerFor(worker.URL).TrainChild(ctx, "AAPL")
+	if err != nil {
+		t.Fatalf("TrainChild err = %v", err)
+	}
+	if result.Data["cmd"] != "train-child" || result.ExecutionID != "exec-1" {
+		t.Errorf("TrainChild result = %+v, want cmd=train-child from exec-1", result)
+	}
+	if len(got) != 1 || got[0].Percent != 50 {
+		t.Errorf("progress = %+v, want one event at 50%%", got)
+	}
+}
+
+func TestRemoteRunner_CarriesTypedError(t *testing.T) {
+	stub := &stubRunner{err: newError(CodeModelMissing, "Missing model for AAPL", "")}
+	worker := httptest.NewServer(NewWorkerServer(workerConfig(t), stub))
+	defer worker.Close()
+
+	_, err := remoteRunnerFor(worker.URL).PredictChild(context.Background(), "AAPL")
+	if !errors.Is(err, ErrModelMissing) {
+		t.Fatalf("PredictChild err = %v, want ErrModelMissing", err)
+	}
+	if err.Error() != "Missing model for AAPL" {
+		t.Errorf("PredictChild err = %q, want the worker's message", err.Error())
+	}
+}
+
+func TestRemoteRunner_FailsOverFromDeadWorker(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	stub := &stubRunner{}
+	live := httptest.NewServer(NewWorkerServer(workerConfig(t), stub))
+	defer live.Close()
+
+	r := remoteRunnerFor(dead.URL, live.URL)
+	for i := 0; i < 2; i++ {
+		if _, err := r.PredictParent(context.Background()); err != nil {
+			t.Fatalf("PredictParent #%d err = %v, want failover to the live worker", i, err)
+		}
+	}
+	if r.endpoints[0].healthy.Load() {
+		t.Error("dead endpoint still marked healthy after a failed request")
+	}
+	if stub.calls.Load() != 2 {
+		t.Errorf("live worker ran %d jobs, want 2", stub.calls.Load())
+	}
+}
+
+func TestRemoteRunner_FailsOverFromBusyWorker(t *testing.T) {
+	busyStub := &stubRunner{release: make(chan struct{})}
+	busy := httptest.NewServer(NewWorkerServer(workerConfig(t), busyStub))
+	defer busy.Close()
+	defer close(busyStub.release) // before busy.Close, which waits for the job
+
+	idleStub := &stubRunner{}
+	idle := httptest.NewServer(NewWorkerServer(workerConfig(t), idleStub))
+	defer idle.Close()
+
+	// Occupy the only slot on the busy worker
+	started := make(chan struct{})
+	go func() {
+		ctx := WithProgress(context.Background(), func(Progress) { close(started) })
+		remoteRunnerFor(busy.URL).TrainParent(ctx)
+	}()
+	<-started
+
+	r := remoteRunnerFor(busy.URL, idle.URL)
+	r.next.Store(uint64(len(r.endpoints) - 1)) // start at the busy worker
+	if _, err := r.MonitorParent(context.Background()); err != nil {
+		t.Fatalf("MonitorParent err = %v, want failover to the idle worker", err)
+	}
+	if idleStub.calls.Load() != 1 {
+		t.Errorf("idle worker ran %d jobs, want 1", idleStub.calls.Load())
+	}
+	if !r.endpoints[0].healthy.Load() {
+		t.Error("busy endpoint marked unhealthy, want it kept in rotation")
+	}
+}
+
+func TestRemoteRunner_NoEndpoints(t *testing.T) {
+	_, err := remoteRunnerFor().PredictParent(context.Background())
+	if !errors.Is(err, ErrInternal) {
+		t.Fatalf("PredictParent err = %v, want ErrInternal", err)
+	}
+}
+
+func TestRemoteRunner_ProbeMarksHealth(t *testing.T) {
+	live := httptest.NewServer(NewWorkerServer(workerConfig(t), &stubRunner{}))
+	defer live.Close()
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	r := remoteRunnerFor(live.URL, dead.URL)
+	r.probeAll()
+
+	if !r.endpoints[0].healthy.Load() || r.endpoints[1].healthy.Load() {
+		t.Errorf("health = [%v %v], want [true false]", r.endpoints[0].healthy.Load(), r.endpoints[1].healthy.Load())
+	}
+}
+
+func TestRemoteRunner_GetsExecutionFromTheWorkerThatRanIt(t *testing.T) {
+	other := httptest.NewServer(NewWorkerServer(workerConfig(t), &stubRunner{}))
+	defer other.Close()
+
+	cfg := workerConfig(t)
+	if err := NewArchive(cfg).Save(&Execution{ID: "20260101T000000-abcd", Stderr: "Traceback"}); err != nil {
+		t.Fatalf("Save err = %v", err)
+	}
+	ran := httptest.NewServer(NewWorkerServer(cfg, &stubRunner{}))
+	defer ran.Close()
+
+	r := remoteRunnerFor(other.URL, ran.URL)
+	execution, err := r.Get("20260101T000000-abcd")
+	if err != nil {
+		t.Fatalf("Get err = %v", err)
+	}
+	if execution.Stderr != "Traceback" {
+		t.Errorf("Get = %+v, want the archived record", execution)
+	}
+	if _, err := r.Get("20260101T000000-ffff"); !errors.Is(err, ErrExecutionNotFound) {
+		t.Errorf("Get(unknown) err = %v, want ErrExecutionNotFound", err)
+	}
+}
+
+func TestWorkerServer_RequiresToken(t *testing.T) {
+	stub := &stubRunner{}
+	worker := httptest.NewServer(NewWorkerServer(workerConfig(t), stub))
+	defer worker.Close()
+
+	for _, token := range []string{"", "wrong"} {
+		r := NewRemoteRunner(&config.Config{WorkerEndpoints: []string{worker.URL}, WorkerToken: token})
+		if _, err := r.PredictParent(context.Background()); !errors.Is(err, ErrInternal) {
+			t.Errorf("PredictParent with token %q err = %v, want rejected", token, err)
+		}
+		if _, err := r.Get("20260101T000000-abcd"); err == nil || errors.Is(err, ErrExecutionNotFound) {
+			t.Errorf("Get with token %q err = %v, want unauthorized", token, err)
+		}
+	}
+	if stub.calls.Load() != 0 {
+		t.Errorf("worker ran %d jobs without the token, want 0", stub.calls.Load())
+	}
+
+	unset := httptest.NewServer(NewWorkerServer(&config.Config{MaxWorkers: 1}, stub))
+	defer unset.Close()
+	r := NewRemoteRunner(&config.Config{WorkerEndpoints: []string{unset.URL}})
+	if _, err := r.PredictParent(context.Background()); !errors.Is(err, ErrInternal) {
+		t.Errorf("PredictParent to a worker without a token err = %v, want rejected", err)
+	}
+}
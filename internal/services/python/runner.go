@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"os/exec"
 	"time"
@@ -16,8 +18,10 @@ import (
 type Runner struct {
 	pythonPath string
 	scriptPath string
-	timeout    time.Duration
 	env        []string
+	archive    *Archive
+	policies   Policies
+	grace      time.Duration
 }
 
 // NewRunner creates a new Python CLI runner
@@ -27,13 +31,13 @@ func NewRunner(cfg *config.Config) *Runner {
 
 	// Add/override specific env vars
 	envVars := map[string]string{
-		"MLFLOW_TRACKING_URI":  cfg.MLflowURI,
-		"QDRANT_HOST":          cfg.QdrantHost,
-		"QDRANT_PORT":          cfg.QdrantPort,
-		"LLAMA_CPP_BASE_URL":   cfg.LlamaCppURL,
-		"LLAMA_CPP_EMBED_URL":  cfg.LlamaCppEmbedURL,
-		"FMI_API_KEY":          cfg.FinnhubKey,
-		"LLM_MODEL":            cfg.LLMModel,
+		"MLFLOW_TRACKING_URI": cfg.MLflowURI,
+		"QDRANT_HOST":         cfg.QdrantHost,
+		"QDRANT_PORT":         cfg.QdrantPort,
+		"LLAMA_CPP_BASE_URL":  cfg.LlamaCppURL,
+		"LLAMA_CPP_EMBED_URL": cfg.LlamaCppEmbedURL,
+		"FMI_API_KEY":         cfg.FinnhubKey,
+		"LLM_MODEL":           cfg.LLMModel,
 	}
 
 	for k, v := range envVars {
@@ -42,59 +46,137 @@ func NewRunner(cfg *config.Config) *Runner {
 		}
 	}
 
+	policies, err := LoadPolicies(cfg)
+	if err != nil {
+		log.Printf("Warning: %v; using default execution policies", err)
+	}
+
 	return &Runner{
 		pythonPath: cfg.PythonPath,
 		scriptPath: cfg.ScriptPath,
-		timeout:    time.Duration(cfg.PythonTimeout) * time.Second,
 		env:        env,
+		archive:    NewArchive(cfg),
+		policies:   policies,
+		grace:      time.Duration(cfg.KillGrace) * time.Second,
 	}
 }
 
 // Result represents the result of a Python CLI execution
 type Result struct {
-	Data  map[string]interface{}
-	Error string
+	Data        map[string]interface{}
+	Error       string
+	ExecutionID string
+}
+
+// Archive returns where this runner stores execution records
+func (r *Runner) Archive() *Archive {
+	return r.archive
 }
 
-// Execute runs a Python CLI command and returns the result
+// Execute runs a Python CLI command under its policy and returns the
+// result. Every attempt is archived with its full stdout/stderr under an
+// execution ID.
 func (r *Runner) Execute(ctx context.Context, args ...string) (*Result, error) {
+	policy := r.policies.For(command(args))
+	return withRetries(ctx, policy, command(args), func() (*Result, error) {
+		return r.attempt(ctx, policy, args)
+	})
+}
+
+// attempt runs the command once and archives the run
+func (r *Runner) attempt(ctx context.Context, policy Policy, args []string) (*Result, error) {
+	record := newExecution(args)
+
+	result, err := r.execute(ctx, policy, record, args)
+
+	record.finish(err)
+	if result != nil {
+		result.ExecutionID = record.ID
+	}
+	if saveErr := r.archive.Save(record); saveErr != nil {
+		log.Printf("Failed to archive execution %s: %v", record.ID, saveErr)
+	}
+
+	return result, err
+}
+
+// execute runs the command, filling in the record's output and exit code
+func (r *Runner) execute(ctx context.Context, policy Policy, record *Execution, args []string) (*Result, error) {
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	ctx, cancel := context.WithTimeout(ctx, policy.Timeout)
 	defer cancel()
 
-	// Build command
-	cmdArgs := append([]string{r.scriptPath}, args...)
+	// Build command in its own process group, under its resource limits from
+	// the start; on timeout or cancel the whole group gets SIGTERM, then
+	// SIGKILL after the grace period
+	cmdArgs := limitedArgs(policy.Limits, append([]string{r.scriptPath}, args...))
 	cmd := exec.CommandContext(ctx, r.pythonPath, cmdArgs...)
-	cmd.Env = r.env
-
-	// Capture output
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
+	cmd.Env = policy.environ(r.env)
+	setProcessGroup(cmd)
+	terminator := newGroupTerminator(cmd, r.grace)
+	cmd.Cancel = terminator.terminate
+	cmd.WaitDelay = r.grace + time.Second
+
+	// Capture output, forwarding progress lines as they arrive
+	stdout := &progressWriter{fn: progressFrom(ctx), max: policy.MaxStdout}
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
 	cmd.Stderr = &stderr
 
 	// Run command
-	err := cmd.Run()
+	err := cmd.Start()
+	if err == nil {
+		// Kill stragglers the main process left behind before reaping it
+		terminator.reapStragglers()
+		err = cmd.Wait()
+		terminator.exited()
+		if errors.Is(err, exec.ErrWaitDelay) {
+			err = nil
+		}
+	}
+
+	record.Stdout = capOutput(stdout.Raw())
+	record.Stderr = capOutput(stderr.Bytes())
+	if cmd.ProcessState != nil {
+		record.ExitCode = cmd.ProcessState.ExitCode()
+	}
 
 	// Parse output
 	var result Result
-	if stdout.Len() > 0 {
-		if jsonErr := json.Unmarshal(stdout.Bytes(), &result.Data); jsonErr != nil {
+	if output := stdout.Bytes(); len(output) > 0 {
+		if jsonErr := json.Unmarshal(output, &result.Data); jsonErr != nil {
 			// If output isn't valid JSON, include it as error
-			result.Error = fmt.Sprintf("Invalid JSON output: %s", stdout.String())
+			result.Error = fmt.Sprintf("Invalid JSON output: %s", output)
 		}
 	}
 
 	// Check for error
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return nil, fmt.Errorf("command timed out after %v", r.timeout)
+			return nil, newError(CodeTimeout, fmt.Sprintf("command timed out after %v", policy.Timeout), "")
+		}
+		if ctx.Err() == context.Canceled {
+			return nil, fmt.Errorf("command cancelled: %w", context.Canceled)
 		}
 
 		// Check if we got JSON error output
-		if result.Data != nil {
-			if errMsg, ok := result.Data["error"].(string); ok {
-				return nil, fmt.Errorf("%s", errMsg)
+		cliErr := errorFromData(result.Data)
+
+		// Explain failures caused by resource limits
+		var evidence []byte
+		evidence = append(evidence, stderr.Bytes()...)
+		if cliErr != nil {
+			evidence = append(evidence, cliErr.Details...)
+		}
+		if violation := limitViolation(cmd.ProcessState, evidence, policy.Limits); violation != "" {
+			if cliErr != nil {
+				return nil, newError(CodeResourceLimit, cliErr.Message+" ("+violation+")", cliErr.Details)
 			}
+			return nil, newError(CodeResourceLimit, "command failed: "+violation, stderr.String())
+		}
+
+		if cliErr != nil {
+			return nil, cliErr
 		}
 
 		// Include stderr in error
@@ -102,19 +184,33 @@ func (r *Runner) Execute(ctx context.Context, args ...string) (*Result, error) {
 		if errMsg == "" {
 			errMsg = err.Error()
 		}
-		return nil, fmt.Errorf("command failed: %s", errMsg)
+		return nil, newError(CodeInternal, "command failed: "+errMsg, "")
+	}
+
+	// A truncated result cannot be trusted even if it happens to parse
+	if stdout.Truncated() {
+		return nil, newError(CodeInternal, fmt.Sprintf("command output exceeded %d bytes and was truncated", policy.MaxStdout), "")
 	}
 
 	// Check for error in JSON output
-	if result.Data != nil {
-		if errMsg, ok := result.Data["error"].(string); ok {
-			return nil, fmt.Errorf("%s", errMsg)
-		}
+	if cliErr := errorFromData(result.Data); cliErr != nil {
+		return nil, cliErr
 	}
 
 	return &result, nil
 }
 
+// errorFromData converts an ml_cli error response into an *Error
+func errorFromData(data map[string]interface{}) *Error {
+	errMsg, ok := data["error"].(string)
+	if !ok {
+		return nil
+	}
+	code, _ := data["code"].(string)
+	details, _ := data["details"].(string)
+	return newError(code, errMsg, details)
+}
+
 // TrainParent runs the train-parent command
 func (r *Runner) TrainParent(ctx context.Context) (*Result, error) {
 	return r.Execute(ctx, "train-parent")
@@ -2,6 +2,7 @@ package python
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -21,8 +22,9 @@ func fakeRunner(t *testing.T, script string) *Runner {
 	return &Runner{
 		pythonPath: "python3",
 		scriptPath: scriptPath,
-		timeout:    5 * time.Second,
 		env:        os.Environ(),
+		archive:    &Archive{dir: filepath.Join(dir, "executions"), max: 10},
+		policies:   Policies{"default": {Timeout: 5 * time.Second}},
 	}
 }
 
@@ -59,15 +61,40 @@ func TestExecute_JSONErrorField(t *testing.T) {
 	}
 }
 
+func TestExecute_TypedErrorCode(t *testing.T) {
+	r := fakeRunner(t, `import json, sys; print(json.dumps({"error": "Missing model for AAPL", "code": "model_missing", "details": "Traceback"})); sys.exit(1)`)
+
+	_, err := r.Execute(context.Background())
+	if !errors.Is(err, ErrModelMissing) {
+		t.Fatalf("Execute(model_missing) err = %v, want errors.Is ErrModelMissing", err)
+	}
+	var cliErr *Error
+	if !errors.As(err, &cliErr) || cliErr.Details != "Traceback" {
+		t.Errorf("Execute(model_missing) details = %+v, want \"Traceback\"", cliErr)
+	}
+}
+
+func TestExecute_UnknownCodeIsInternal(t *testing.T) {
+	r := fakeRunner(t, `import json, sys; print(json.dumps({"error": "boom", "code": "nope"})); sys.exit(1)`)
+
+	_, err := r.Execute(context.Background())
+	if !errors.Is(err, ErrInternal) {
+		t.Errorf("Execute(unknown code) err = %v, want errors.Is ErrInternal", err)
+	}
+}
+
 func TestExecute_Timeout(t *testing.T) {
 	r := fakeRunner(t, `import time; time.sleep(30)`)
-	r.timeout = 100 * time.Millisecond // very short timeout
+	r.policies["default"] = Policy{Timeout: 100 * time.Millisecond} // very short timeout
 
 	ctx := context.Background()
 	_, err := r.Execute(ctx)
 	if err == nil {
 		t.Fatal("Execute(timeout) err = nil, want timeout error")
 	}
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Execute(timeout) err = %v, want errors.Is ErrTimeout", err)
+	}
 }
 
 func TestExecute_InvalidJSONOutput(t *testing.T) {
@@ -96,3 +123,62 @@ func TestExecute_PassesArgs(t *testing.T) {
 		t.Errorf("Execute(args) first arg = %v, want \"predict-child\"", result.Data["arg"])
 	}
 }
+
+func TestExecute_ProgressEvents(t *testing.T) {
+	r := fakeRunner(t, `import json
+print(json.dumps({"event": "progress", "phase": "ingest"}), flush=True)
+print(json.dumps({"event": "progress", "phase": "train", "epoch": 2, "total_epochs": 4, "loss": 0.5}), flush=True)
+print(json.dumps({"status": "completed"}))`)
+
+	var events []Progress
+	ctx := WithProgress(context.Background(), func(p Progress) {
+		events = append(events, p)
+	})
+
+	result, err := r.Execute(ctx, "train-child", "--ticker", "AAPL")
+	if err != nil {
+		t.Fatalf("Execute(progress) err = %v", err)
+	}
+	if result.Data["status"] != "completed" {
+		t.Errorf("Execute(progress) data = %v, want status=completed", result.Data)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Execute(progress) got %d events, want 2", len(events))
+	}
+	if events[1].Phase != "train" || events[1].Epoch != 2 || events[1].Loss != 0.5 {
+		t.Errorf("Execute(progress) event = %+v, want train epoch 2 loss 0.5", events[1])
+	}
+	if events[1].Percent != 50 {
+		t.Errorf("Execute(progress) percent = %v, want 50", events[1].Percent)
+	}
+}
+
+func TestExecute_ArchivesOutput(t *testing.T) {
+	r := fakeRunner(t, `import json, sys
+sys.stderr.write("loading model\n")
+print(json.dumps({"error": "model missing", "code": "model_missing"}))
+sys.exit(2)`)
+
+	_, err := r.Execute(context.Background(), "predict-child", "--ticker", "AAPL")
+	var cliErr *Error
+	if !errors.As(err, &cliErr) || cliErr.ExecutionID == "" {
+		t.Fatalf("Execute err = %v, want *Error with an execution ID", err)
+	}
+
+	record, err := r.Archive().Get(cliErr.ExecutionID)
+	if err != nil {
+		t.Fatalf("Archive().Get(%s) err = %v", cliErr.ExecutionID, err)
+	}
+	if record.ExitCode != 2 {
+		t.Errorf("record.ExitCode = %d, want 2", record.ExitCode)
+	}
+	if record.Stderr != "loading model\n" {
+		t.Errorf("record.Stderr = %q, want \"loading model\\n\"", record.Stderr)
+	}
+	if record.Code != CodeModelMissing {
+		t.Errorf("record.Code = %q, want %q", record.Code, CodeModelMissing)
+	}
+	if len(record.Args) != 3 || record.Args[2] != "AAPL" {
+		t.Errorf("record.Args = %v, want [predict-child --ticker AAPL]", record.Args)
+	}
+}
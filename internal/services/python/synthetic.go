@@ -0,0 +1,397 @@
+package python
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+)
+
+// syntheticEpochs is how many progress events a synthetic training run emits
+const syntheticEpochs = 10
+
+// Forecast horizons in trading days, matching src/inference.py
+var syntheticHorizons = map[string]int{"week": 5, "month": 21, "quarter": 63}
+
+// SyntheticRunner returns realistic, deterministic results without Python,
+// for frontend work and integration tests. The same ticker on the same day
+// always yields the same prices. Latency and failures are simulated.
+type SyntheticRunner struct {
+	latency     time.Duration
+	failureRate float64
+	parent      string
+	now         func() time.Time
+
+	mu   sync.Mutex
+	rand *rand.Rand // decides failures only, so results stay deterministic
+}
+
+// NewSyntheticRunner creates a synthetic runner from SYNTHETIC_* settings
+func NewSyntheticRunner(cfg *config.Config) *SyntheticRunner {
+	return &SyntheticRunner{
+		latency:     time.Duration(cfg.SyntheticLatencyMs) * time.Millisecond,
+		failureRate: cfg.SyntheticFailureRate,
+		parent:      cfg.ParentTicker,
+		now:         time.Now,
+		rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// TrainParent simulates training the parent model
+func (s *SyntheticRunner) TrainParent(ctx context.Context) (*Result, error) {
+	result, err := s.train(ctx, s.parent)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Data: map[string]interface{}{"status": "completed", "result": result}}, nil
+}
+
+// TrainChild simulates training a child model
+func (s *SyntheticRunner) TrainChild(ctx context.Context, ticker string) (*Result, error) {
+	ticker = strings.ToUpper(ticker)
+	result, err := s.train(ctx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Data: map[string]interface{}{"status": "completed", "ticker": ticker, "result": result}}, nil
+}
+
+// PredictParent returns a synthetic parent forecast
+func (s *SyntheticRunner) PredictParent(ctx context.Context) (*Result, error) {
+	if err := s.simulate(ctx, "predict-parent", s.parent); err != nil {
+		return nil, err
+	}
+	return &Result{Data: s.forecast(s.parent)}, nil
+}
+
+// PredictChild returns a synthetic child forecast
+func (s *SyntheticRunner) PredictChild(ctx context.Context, ticker string) (*Result, error) {
+	ticker = strings.ToUpper(ticker)
+	if err := s.simulate(ctx, "predict-child", ticker); err != nil {
+		return nil, err
+	}
+	return &Result{Data: s.forecast(ticker)}, nil
+}
+
+// Analyze returns a synthetic agent report built on the synthetic forecast
+func (s *SyntheticRunner) Analyze(ctx context.Context, ticker string, threadID string) (*Result, error) {
+	ticker = strings.ToUpper(ticker)
+	if err := s.simulate(ctx, "analyze", ticker); err != nil {
+		return nil, err
+	}
+	return &Result{Data: s.analysis(ticker)}, nil
+}
+
+// MonitorParent returns synthetic drift and agent evaluation for the parent
+func (s *SyntheticRunner) MonitorParent(ctx context.Context) (*Result, error) {
+	if err := s.simulate(ctx, "monitor-parent", s.parent); err != nil {
+		return nil, err
+	}
+	return &Result{Data: map[string]interface{}{
+		"ticker":     s.parent,
+		"type":       "Parent Model (Market Index)",
+		"drift":      s.drift(s.parent),
+		"agent_eval": s.agentEval(s.parent),
+	}}, nil
+}
+
+// MonitorTicker returns synthetic monitoring; drift is parent-only, as in ml_cli.py
+func (s *SyntheticRunner) MonitorTicker(ctx context.Context, ticker string) (*Result, error) {
+	ticker = strings.ToUpper(strings.TrimSpace(ticker))
+	if err := s.simulate(ctx, "monitor-ticker", ticker); err != nil {
+		return nil, err
+	}
+
+	isParent := ticker == s.parent
+	drift := map[string]interface{}{"status": "skipped", "detail": "Drift calculation reserved for parent model."}
+	if isParent {
+		drift = s.drift(ticker)
+	}
+	return &Result{Data: map[string]interface{}{
+		"ticker":     ticker,
+		"is_parent":  isParent,
+		"drift":      drift,
+		"agent_eval": s.agentEval(ticker),
+	}}, nil
+}
+
+// simulate waits out the configured latency and maybe fails
+func (s *SyntheticRunner) simulate(ctx context.Context, command, ticker string) error {
+	if err := s.sleep(ctx, s.latency); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	fail := s.rand.Float64() < s.failureRate
+	s.mu.Unlock()
+	if fail {
+		return newError(CodeDataUnavailable, fmt.Sprintf("%s failed for %s: synthetic market data outage", command, ticker), "")
+	}
+	return nil
+}
+
+// sleep waits for d unless ctx ends first
+func (s *SyntheticRunner) sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return newError(CodeTimeout, "command timed out", "")
+		}
+		return fmt.Errorf("command cancelled: %w", context.Canceled)
+	}
+}
+
+// train emits the same progress phases as the real pipeline, one epoch per latency
+func (s *SyntheticRunner) train(ctx context.Context, ticker string) (map[string]interface{}, error) {
+	report := progressFrom(ctx)
+	if report == nil {
+		report = func(Progress) {}
+	}
+	rng := s.seeded("train", ticker)
+
+	report(Progress{Phase: "ingest", Percent: 0})
+	loss := 0.05 + rng.Float64()*0.05
+	for epoch := 1; epoch <= syntheticEpochs; epoch++ {
+		if err := s.sleep(ctx, s.latency); err != nil {
+			return nil, err
+		}
+		loss *= 0.8 + rng.Float64()*0.1
+		report(Progress{
+			Phase:       "train",
+			Epoch:       epoch,
+			TotalEpochs: syntheticEpochs,
+			Loss:        loss,
+			ValMSE:      loss * (1.05 + rng.Float64()*0.1),
+			Percent:     10 + 80*float64(epoch)/syntheticEpochs,
+		})
+	}
+	report(Progress{Phase: "evaluate", Percent: 90})
+	if err := s.simulate(ctx, "train", ticker); err != nil {
+		return nil, err
+	}
+
+	mse := loss * (1 + rng.Float64()*0.2)
+	return map[string]interface{}{
+		"ticker": ticker,
+		"run_id": fmt.Sprintf("synthetic-%016x", rng.Uint64()),
+		"metrics": map[string]interface{}{
+			"MSE":  roundTo(mse, 6),
+			"RMSE": roundTo(math.Sqrt(mse), 6),
+			"R2":   roundTo(0.7+rng.Float64()*0.25, 4),
+		},
+	}, nil
+}
+
+// forecast builds a predict_multi_horizon-shaped result plus 90 days of history
+func (s *SyntheticRunner) forecast(ticker string) map[string]interface{} {
+	rng := s.seeded("prices", ticker)
+	today := s.now().UTC().Truncate(24 * time.Hour)
+
+	// History: 90 business days ending on the last business day before today
+	histDays := businessDays(today.AddDate(0, 0, -140), 200)
+	for len(histDays) > 0 && !histDays[len(histDays)-1].Before(today) {
+		histDays = histDays[:len(histDays)-1]
+	}
+	histDays = histDays[len(histDays)-90:]
+
+	price := 20 + rng.Float64()*480
+	history := make([]map[string]interface{}, 0, len(histDays))
+	for _, d := range histDays {
+		price *= 1 + rng.NormFloat64()*0.015
+		history = append(history, map[string]interface{}{"date": d.Format("2006-01-02"), "close": roundTo(price, 4)})
+	}
+	lastDate := histDays[len(histDays)-1]
+
+	// Forecast: a gentle drift from the last close
+	drift := rng.NormFloat64() * 0.002
+	futureDays := businessDays(lastDate.AddDate(0, 0, 1), syntheticHorizons["quarter"])
+	full := make([]map[string]interface{}, 0, len(futureDays))
+	for _, d := range futureDays {
+		open := price
+		price *= 1 + drift + rng.NormFloat64()*0.01
+		high := math.Max(open, price) * (1 + rng.Float64()*0.01)
+		low := math.Min(open, price) * (1 - rng.Float64()*0.01)
+		full = append(full, map[string]interface{}{
+			"date":   d.Format("2006-01-02"),
+			"open":   roundTo(open, 4),
+			"high":   roundTo(high, 4),
+			"low":    roundTo(low, 4),
+			"close":  roundTo(price, 4),
+			"volume": math.Round(1e6 + rng.Float64()*4e7),
+		})
+	}
+
+	week := full[:syntheticHorizons["week"]]
+	nextDays := make([]string, 0, len(week))
+	for _, row := range week {
+		nextDays = append(nextDays, row["date"].(string))
+	}
+
+	return map[string]interface{}{
+		"ticker":             ticker,
+		"last_date":          lastDate.Format("2006-01-02"),
+		"future_window_days": syntheticHorizons["quarter"],
+		"next_business_days": nextDays,
+		"predictions": map[string]interface{}{
+			"next_day":      full[0],
+			"week":          week,
+			"month":         full[:syntheticHorizons["month"]],
+			"quarter":       full,
+			"full_forecast": full,
+		},
+		"history": history,
+	}
+}
+
+// analysis mirrors analyze_stock in src/agents/graph.py
+func (s *SyntheticRunner) analysis(ticker string) map[string]interface{} {
+	forecast := s.forecast(ticker)
+	preds := forecast["predictions"].(map[string]interface{})
+	history := forecast["history"].([]map[string]interface{})
+	week := preds["week"].([]map[string]interface{})
+
+	lastClose := history[len(history)-1]["close"].(float64)
+	weekClose := week[len(week)-1]["close"].(float64)
+	change := (weekClose - lastClose) / lastClose * 100
+
+	stance, confidence := "NEUTRAL", "Medium"
+	switch {
+	case change > 1:
+		stance, confidence = "BULLISH", "High"
+	case change < -1:
+		stance, confidence = "BEARISH", "High"
+	}
+
+	var lines []string
+	for _, row := range week {
+		lines = append(lines, fmt.Sprintf("  %s: $%.2f", row["date"], row["close"]))
+	}
+	report := fmt.Sprintf(`## %s Analysis
+
+### Price Forecast
+5-Day Price Forecast for %s:
+%s
+
+The model projects a %+.2f%% move over the next five trading days from a last close of $%.2f.
+
+### News Summary
+Synthetic data: no news was fetched.
+
+**Market Stance:** %s
+**Confidence:** %s`, ticker, ticker, strings.Join(lines, "\n"), change, lastClose, stance, confidence)
+
+	predictions := map[string]interface{}{"history": history}
+	for k, v := range preds {
+		predictions[k] = v
+	}
+
+	return map[string]interface{}{
+		"ticker":         ticker,
+		"final_report":   report,
+		"recommendation": stance,
+		"confidence":     confidence,
+		"predictions":    predictions,
+		"news_sentiment": "Synthetic data: no news was fetched.",
+	}
+}
+
+// drift mirrors calculate_custom_drift in src/monitoring/drift.py
+func (s *SyntheticRunner) drift(ticker string) map[string]interface{} {
+	rng := s.seeded("drift", ticker)
+
+	features := map[string]interface{}{}
+	var total float64
+	for _, col := range []string{"Open", "High", "Low", "Close", "Volume", "RSI14", "MACD"} {
+		refMean := 50 + rng.Float64()*450
+		shift := rng.Float64() * 1.5
+		total += shift
+		features[col] = map[string]interface{}{
+			"ref_mean":    roundTo(refMean, 2),
+			"curr_mean":   roundTo(refMean*(1+shift*0.02), 2),
+			"shift_score": roundTo(shift, 4),
+		}
+	}
+	avg := total / float64(len(features))
+	vol := 0.6 + rng.Float64()*0.9
+
+	health := "Healthy"
+	switch {
+	case avg > 2.0 || vol > 2.5 || vol < 0.4:
+		health = "Critical (Drift Detected)"
+	case avg > 1.0 || vol > 1.5 || vol < 0.6:
+		health = "Degraded (Warning)"
+	}
+
+	return map[string]interface{}{
+		"health":           health,
+		"drift_score":      roundTo(avg, 4),
+		"volatility_index": roundTo(vol, 4),
+		"feature_metrics":  features,
+		"timestamp":        s.now().Format("2006-01-02T15:04:05"),
+		"status":           "success",
+		"ticker":           ticker,
+	}
+}
+
+// agentEval mirrors AgentEvaluator.evaluate_live in src/monitoring/agent_eval.py
+func (s *SyntheticRunner) agentEval(ticker string) map[string]interface{} {
+	report := s.analysis(ticker)["final_report"].(string)
+	preview := report
+	if len(preview) > 1000 {
+		preview = preview[:1000]
+	}
+
+	return map[string]interface{}{
+		"ticker": ticker,
+		"metrics": map[string]interface{}{
+			"checks": map[string]interface{}{
+				"relevance":          true,
+				"trustworthiness":    true,
+				"has_recommendation": true,
+			},
+			"overall_score":    1.0,
+			"status":           "Trustworthy",
+			"duration_seconds": roundTo(s.latency.Seconds(), 2),
+			"timestamp":        s.now().Format("2006-01-02T15:04:05"),
+		},
+		"output_preview_text": preview,
+	}
+}
+
+// seeded returns a generator fixed by kind, ticker and the current day
+func (s *SyntheticRunner) seeded(kind, ticker string) *rand.Rand {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%s|%s", kind, ticker, s.now().UTC().Format("2006-01-02"))
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+// businessDays returns n weekdays starting at from
+func businessDays(from time.Time, n int) []time.Time {
+	days := make([]time.Time, 0, n)
+	for d := from; len(days) < n; d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// roundTo rounds v to the given number of decimal places
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,120 @@
+package python
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+)
+
+// syntheticRunner builds a zero-latency SyntheticRunner fixed at a Wednesday
+func syntheticRunner(failureRate float64) *SyntheticRunner {
+	s := NewSyntheticRunner(&config.Config{ParentTicker: "^GSPC", SyntheticFailureRate: failureRate})
+	s.now = func() time.Time { return time.Date(2026, 3, 11, 15, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func TestSynthetic_PredictIsDeterministic(t *testing.T) {
+	first, err := syntheticRunner(0).PredictChild(context.Background(), "aapl")
+	if err != nil {
+		t.Fatalf("PredictChild err = %v", err)
+	}
+	second, _ := syntheticRunner(0).PredictChild(context.Background(), "AAPL")
+	if !reflect.DeepEqual(first.Data, second.Data) {
+		t.Error("PredictChild(AAPL) differs between runs on the same day")
+	}
+
+	other, _ := syntheticRunner(0).PredictChild(context.Background(), "MSFT")
+	if reflect.DeepEqual(first.Data["history"], other.Data["history"]) {
+		t.Error("PredictChild(MSFT) history equals AAPL's, want per-ticker prices")
+	}
+}
+
+func TestSynthetic_PredictShape(t *testing.T) {
+	result, err := syntheticRunner(0).PredictParent(context.Background())
+	if err != nil {
+		t.Fatalf("PredictParent err = %v", err)
+	}
+
+	preds := result.Data["predictions"].(map[string]interface{})
+	for horizon, want := range map[string]int{"week": 5, "month": 21, "quarter": 63, "full_forecast": 63} {
+		if got := len(preds[horizon].([]map[string]interface{})); got != want {
+			t.Errorf("predictions.%s has %d days, want %d", horizon, got, want)
+		}
+	}
+	if got := len(result.Data["history"].([]map[string]interface{})); got != 90 {
+		t.Errorf("history has %d days, want 90", got)
+	}
+	if result.Data["last_date"] != "2026-03-10" {
+		t.Errorf("last_date = %v, want the previous business day 2026-03-10", result.Data["last_date"])
+	}
+
+	next := result.Data["next_business_days"].([]string)
+	want := []string{"2026-03-11", "2026-03-12", "2026-03-13", "2026-03-16", "2026-03-17"}
+	if !reflect.DeepEqual(next, want) {
+		t.Errorf("next_business_days = %v, want %v", next, want)
+	}
+
+	day := preds["next_day"].(map[string]interface{})
+	for _, key := range []string{"date", "open", "high", "low", "close", "volume"} {
+		if _, ok := day[key]; !ok {
+			t.Errorf("next_day missing %q", key)
+		}
+	}
+	if day["high"].(float64) < day["low"].(float64) {
+		t.Errorf("next_day high %v < low %v", day["high"], day["low"])
+	}
+}
+
+func TestSynthetic_TrainReportsProgress(t *testing.T) {
+	var phases []string
+	ctx := WithProgress(context.Background(), func(p Progress) { phases = append(phases, p.Phase) })
+
+	result, err := syntheticRunner(0).TrainChild(ctx, "nvda")
+	if err != nil {
+		t.Fatalf("TrainChild err = %v", err)
+	}
+	if result.Data["ticker"] != "NVDA" || result.Data["status"] != "completed" {
+		t.Errorf("TrainChild data = %v, want completed NVDA", result.Data)
+	}
+	if len(phases) != syntheticEpochs+2 || phases[0] != "ingest" || phases[len(phases)-1] != "evaluate" {
+		t.Errorf("progress phases = %v, want ingest, %d train epochs, evaluate", phases, syntheticEpochs)
+	}
+}
+
+func TestSynthetic_FailureRate(t *testing.T) {
+	_, err := syntheticRunner(1).Analyze(context.Background(), "AAPL", "")
+	if !errors.Is(err, ErrDataUnavailable) {
+		t.Fatalf("Analyze with failure rate 1 err = %v, want ErrDataUnavailable", err)
+	}
+}
+
+func TestSynthetic_Cancel(t *testing.T) {
+	s := syntheticRunner(0)
+	s.latency = time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.TrainParent(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("TrainParent(cancelled) err = %v, want context.Canceled", err)
+	}
+}
+
+func TestSynthetic_MonitorTickerSkipsChildDrift(t *testing.T) {
+	result, err := syntheticRunner(0).MonitorTicker(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("MonitorTicker err = %v", err)
+	}
+	drift := result.Data["drift"].(map[string]interface{})
+	if drift["status"] != "skipped" {
+		t.Errorf("MonitorTicker(AAPL) drift = %v, want skipped", drift)
+	}
+
+	parent, _ := syntheticRunner(0).MonitorParent(context.Background())
+	if parent.Data["drift"].(map[string]interface{})["status"] != "success" {
+		t.Errorf("MonitorParent drift = %v, want success", parent.Data["drift"])
+	}
+}
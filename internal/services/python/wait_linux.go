@@ -0,0 +1,21 @@
+//go:build linux
+
+package python
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// waitExited blocks until process pid has exited but leaves it unreaped,
+// for cmd.Wait to collect
+func waitExited(pid int) error {
+	var info unix.Siginfo
+	for {
+		err := unix.Waitid(unix.P_PID, pid, &info, unix.WEXITED|unix.WNOWAIT, nil)
+		if !errors.Is(err, unix.EINTR) {
+			return err
+		}
+	}
+}
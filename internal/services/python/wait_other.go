@@ -0,0 +1,10 @@
+//go:build unix && !linux
+
+package python
+
+import "errors"
+
+// waitExited is unavailable here; stragglers outlive their leader
+func waitExited(pid int) error {
+	return errors.New("waiting without reaping is not supported on this platform")
+}
@@ -0,0 +1,169 @@
+package python
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+)
+
+// WorkerServer is the HTTP side of remote execution, run by cmd/worker. It
+// accepts jobs from a RemoteRunner, executes them with a local runner and
+// streams progress and the final result back as NDJSON. It also serves the
+// execution records its runner archived, which only exist on this host.
+// Every endpoint but /health requires cfg.WorkerToken as a bearer token.
+type WorkerServer struct {
+	runner  RunnerInterface
+	archive *Archive
+	token   string
+	slots   chan struct{}
+	mux     *http.ServeMux
+}
+
+// NewWorkerServer creates a server that runs at most cfg.MaxWorkers jobs at
+// once; further jobs get 503 so the caller can fail over to another worker.
+// Without cfg.WorkerToken it refuses every job.
+func NewWorkerServer(cfg *config.Config, runner RunnerInterface) *WorkerServer {
+	maxJobs := cfg.MaxWorkers
+	if maxJobs < 1 {
+		maxJobs = 1
+	}
+
+	s := &WorkerServer{
+		runner:  runner,
+		archive: NewArchive(cfg),
+		token:   cfg.WorkerToken,
+		slots:   make(chan struct{}, maxJobs),
+		mux:     http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /execute", s.authorized(s.execute))
+	s.mux.HandleFunc("GET /executions/{id}", s.authorized(s.execution))
+	s.mux.HandleFunc("GET /health", s.health)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *WorkerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// authorized rejects requests that do not carry the shared worker token
+func (s *WorkerServer) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// health handles GET /health
+func (s *WorkerServer) health(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "healthy",
+		"active":   len(s.slots),
+		"capacity": cap(s.slots),
+	})
+}
+
+// execution handles GET /executions/{id}
+func (s *WorkerServer) execution(w http.ResponseWriter, r *http.Request) {
+	execution, err := s.archive.Get(r.PathValue("id"))
+	if errors.Is(err, ErrExecutionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to read execution: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(execution)
+}
+
+// execute handles POST /execute
+func (s *WorkerServer) execute(w http.ResponseWriter, r *http.Request) {
+	var job remoteJob
+	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+		http.Error(w, "invalid job: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	run, ok := s.dispatch(job)
+	if !ok {
+		http.Error(w, "unknown command: "+job.Command, http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	default:
+		http.Error(w, "worker busy", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	// Progress arrives from the runner's output goroutine, so serialize writes
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	send := func(event remoteEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		enc.Encode(event)
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+	send(remoteEvent{Event: "accepted"})
+
+	// The request context ends if the API side cancels or disconnects
+	ctx := WithProgress(r.Context(), func(p Progress) {
+		send(remoteEvent{Event: "progress", Progress: &p})
+	})
+
+	result, err := run(ctx)
+	if err != nil {
+		event := remoteEvent{Event: "error", Error: err.Error(), Code: CodeInternal}
+		var cliErr *Error
+		if errors.As(err, &cliErr) {
+			event.Code = cliErr.Code
+			event.Details = cliErr.Details
+			event.ExecutionID = cliErr.ExecutionID
+		}
+		send(event)
+		return
+	}
+	send(remoteEvent{Event: "result", Data: result.Data, ExecutionID: result.ExecutionID})
+}
+
+// dispatch maps a job onto the local runner
+func (s *WorkerServer) dispatch(job remoteJob) (func(context.Context) (*Result, error), bool) {
+	switch job.Command {
+	case "train-parent":
+		return s.runner.TrainParent, true
+	case "train-child":
+		return func(ctx context.Context) (*Result, error) { return s.runner.TrainChild(ctx, job.Ticker) }, true
+	case "predict-parent":
+		return s.runner.PredictParent, true
+	case "predict-child":
+		return func(ctx context.Context) (*Result, error) { return s.runner.PredictChild(ctx, job.Ticker) }, true
+	case "analyze":
+		return func(ctx context.Context) (*Result, error) { return s.runner.Analyze(ctx, job.Ticker, job.ThreadID) }, true
+	case "monitor-parent":
+		return s.runner.MonitorParent, true
+	case "monitor-ticker":
+		return func(ctx context.Context) (*Result, error) { return s.runner.MonitorTicker(ctx, job.Ticker) }, true
+	}
+	return nil, false
+}
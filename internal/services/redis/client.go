@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -107,6 +108,112 @@ func (c *Client) Del(ctx context.Context, keys ...string) error {
 	return c.client.Del(ctx, keys...).Err()
 }
 
+// ZAdd adds a member to a sorted set, leaving its score alone if it is
+// already present
+func (c *Client) ZAdd(ctx context.Context, key string, score float64, member string) error {
+	return c.client.ZAddNX(ctx, key, redis.Z{Score: score, Member: member}).Err()
+}
+
+// ZPopMin removes and returns the lowest-scored member of a sorted set.
+// ok is false when the set is empty.
+func (c *Client) ZPopMin(ctx context.Context, key string) (member string, ok bool, err error) {
+	popped, err := c.client.ZPopMin(ctx, key, 1).Result()
+	if err != nil || len(popped) == 0 {
+		return "", false, err
+	}
+	member, _ = popped[0].Member.(string)
+	return member, true, nil
+}
+
+// ZRank returns the zero-based rank of a member, or redis.Nil if absent
+func (c *Client) ZRank(ctx context.Context, key, member string) (int64, error) {
+	return c.client.ZRank(ctx, key, member).Result()
+}
+
+// ZRem removes a member from a sorted set and reports whether it was there
+func (c *Client) ZRem(ctx context.Context, key, member string) (bool, error) {
+	n, err := c.client.ZRem(ctx, key, member).Result()
+	return n > 0, err
+}
+
+// ZCard returns the number of members in a sorted set
+func (c *Client) ZCard(ctx context.Context, key string) (int64, error) {
+	return c.client.ZCard(ctx, key).Result()
+}
+
+// ZRangeByScore returns members scored within [min, max], lowest first
+func (c *Client) ZRangeByScore(ctx context.Context, key string, min, max float64) ([]string, error) {
+	return c.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: strconv.FormatFloat(min, 'f', -1, 64),
+		Max: strconv.FormatFloat(max, 'f', -1, 64),
+	}).Result()
+}
+
+// ZRevRangeByScore returns members scored within [min, max], highest first
+func (c *Client) ZRevRangeByScore(ctx context.Context, key string, min, max float64) ([]string, error) {
+	return c.client.ZRevRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: strconv.FormatFloat(min, 'f', -1, 64),
+		Max: strconv.FormatFloat(max, 'f', -1, 64),
+	}).Result()
+}
+
+// ZRemRangeByScore removes members scored within [min, max]
+func (c *Client) ZRemRangeByScore(ctx context.Context, key string, min, max float64) error {
+	return c.client.ZRemRangeByScore(ctx, key,
+		strconv.FormatFloat(min, 'f', -1, 64),
+		strconv.FormatFloat(max, 'f', -1, 64),
+	).Err()
+}
+
+// LPush prepends a value to a list and trims the list to its newest max
+// entries
+func (c *Client) LPush(ctx context.Context, key string, value interface{}, max int64) error {
+	pipe := c.client.TxPipeline()
+	pipe.LPush(ctx, key, value)
+	pipe.LTrim(ctx, key, 0, max-1)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// LRange returns the list entries from start to stop inclusive; -1 is the
+// last entry
+func (c *Client) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return c.client.LRange(ctx, key, start, stop).Result()
+}
+
+// Publish sends a message to every subscriber of a channel
+func (c *Client) Publish(ctx context.Context, channel, message string) error {
+	return c.client.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe calls handle with each message published on a channel until
+// ctx is done. It returns once the subscription is confirmed; a dropped
+// connection is re-established, and messages sent meanwhile are lost.
+func (c *Client) Subscribe(ctx context.Context, channel string, handle func(message string)) error {
+	sub := c.client.Subscribe(ctx, channel)
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return err
+	}
+
+	go func() {
+		defer sub.Close()
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				handle(msg.Payload)
+			}
+		}
+	}()
+	return nil
+}
+
 // Close closes the Redis connection
 func (c *Client) Close() error {
 	return c.client.Close()
@@ -133,11 +240,99 @@ func TaskKey(taskID string) string {
 	return fmt.Sprintf("task_status:%s", taskID)
 }
 
+// TaskQueueKey is the Redis sorted set of queued training task IDs
+const TaskQueueKey = "task_queue"
+
+// TaskQueueJobKey returns the Redis key holding a queued training job
+func TaskQueueJobKey(taskID string) string {
+	return fmt.Sprintf("task_queue_job:%s", taskID)
+}
+
+// TaskRunKey returns the Redis key for one training run's record
+func TaskRunKey(runID string) string {
+	return fmt.Sprintf("task_run:%s", runID)
+}
+
+// TaskRunsKey is the Redis sorted set of run IDs scored by creation time
+const TaskRunsKey = "task_runs"
+
+// TaskBatchKey returns the Redis key for a batch of child trainings
+func TaskBatchKey(batchID string) string {
+	return fmt.Sprintf("task_batch:%s", batchID)
+}
+
+// TaskRetryKey is the Redis sorted set of failed task IDs scored by when
+// their next attempt is due
+const TaskRetryKey = "task_retry"
+
+// TaskRetryJobKey returns the Redis key holding a failed task's next attempt
+func TaskRetryJobKey(taskID string) string {
+	return fmt.Sprintf("task_retry_job:%s", taskID)
+}
+
+// TaskDeadLetterKey returns the Redis key holding a task that failed on
+// every attempt
+func TaskDeadLetterKey(taskID string) string {
+	return fmt.Sprintf("task_dead_letter:%s", taskID)
+}
+
+// TaskLockKey returns the Redis key leased by whoever runs a task
+func TaskLockKey(taskID string) string {
+	return fmt.Sprintf("task_lock:%s", taskID)
+}
+
+// TaskCancelKey returns the Redis key asking the instance running a task to
+// cancel it
+func TaskCancelKey(taskID string) string {
+	return fmt.Sprintf("task_cancel:%s", taskID)
+}
+
+// TaskCancelChannel is the Redis pub/sub channel on which instances ask
+// whoever runs a task to cancel it
+const TaskCancelChannel = "task_cancel"
+
+// InstanceKey returns the Redis key an API instance heartbeats to
+func InstanceKey(instanceID string) string {
+	return fmt.Sprintf("instance_heartbeat:%s", instanceID)
+}
+
+// ScheduleKey returns the Redis key holding a recurring job schedule
+func ScheduleKey(id string) string {
+	return fmt.Sprintf("schedule:%s", id)
+}
+
+// ScheduleLockKey returns the Redis key leased by the instance running a
+// schedule
+func ScheduleLockKey(id string) string {
+	return fmt.Sprintf("schedule_lock:%s", id)
+}
+
+// WebhookKey returns the Redis key holding a webhook subscription
+func WebhookKey(id string) string {
+	return fmt.Sprintf("webhook:%s", id)
+}
+
+// WebhookDeliveriesKey returns the Redis list logging a webhook
+// subscription's delivery attempts, newest first
+func WebhookDeliveriesKey(id string) string {
+	return fmt.Sprintf("webhook_deliveries:%s", id)
+}
+
 // CacheKey returns the Redis key for a prediction cache
 func CacheKey(ticker string) string {
 	return fmt.Sprintf("predict_child_%s", ticker)
 }
 
+// PredictLockKey returns the Redis key leased by the instance making a
+// ticker's prediction, so others wait for its result
+func PredictLockKey(ticker string) string {
+	return fmt.Sprintf("predict_lock:%s", ticker)
+}
+
+// CacheInvalidateChannel is the Redis pub/sub channel on which instances
+// announce prediction cache changes, so others drop their local copies
+const CacheInvalidateChannel = "cache_invalidate"
+
 // RateLimitKey returns the Redis key for rate limiting
 func RateLimitKey(prefix string, window int64) string {
 	return fmt.Sprintf("rate_limit:%s:%d", prefix, window)
@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// renewScript extends a lease only if the caller still owns it
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes a lease only if the caller still owns it
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Lock is a lease on a key held by a single owner until it is released or
+// its TTL lapses without renewal
+type Lock struct {
+	client *Client
+	key    string
+	owner  string
+	ttl    time.Duration
+}
+
+// AcquireLock takes the lease on key for owner. It returns nil, nil if
+// another owner holds it.
+func (c *Client) AcquireLock(ctx context.Context, key, owner string, ttl time.Duration) (*Lock, error) {
+	ok, err := c.client.SetNX(ctx, key, owner, ttl).Result()
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &Lock{client: c, key: key, owner: owner, ttl: ttl}, nil
+}
+
+// LockOwner returns who holds the lease on key, or "" if nobody does
+func (c *Client) LockOwner(ctx context.Context, key string) (string, error) {
+	owner, err := c.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return owner, err
+}
+
+// Owner returns the lock's owner ID
+func (l *Lock) Owner() string {
+	return l.owner
+}
+
+// Renew extends the lease by its TTL. It returns false if the lease was
+// lost, i.e. it expired and may now belong to someone else.
+func (l *Lock) Renew(ctx context.Context) (bool, error) {
+	n, err := renewScript.Run(ctx, l.client.client, []string{l.key}, l.owner, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// Release gives up the lease if it is still held by this lock's owner
+func (l *Lock) Release(ctx context.Context) error {
+	return l.client.ReleaseLock(ctx, l.key, l.owner)
+}
+
+// ReleaseLock deletes the lease on key if owner still holds it, e.g. to
+// clear a lease left behind by a crashed process
+func (c *Client) ReleaseLock(ctx context.Context, key, owner string) error {
+	if err := releaseScript.Run(ctx, c.client, []string{key}, owner).Err(); err != nil {
+		return fmt.Errorf("release %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCron_Next(t *testing.T) {
+	// Friday 2026-10-16 10:30 UTC
+	from := time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 16, 10, 31, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 10, 16, 11, 0, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 16, 10, 45, 0, 0, time.UTC)},
+		{"0 2 * * mon-fri", time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)},
+		{"30 10 * * 5", time.Date(2026, 10, 23, 10, 30, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 9 1,15 * *", time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches
+		{"0 0 20 * 1", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			cron, err := ParseCron(tc.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) err = %v", tc.expr, err)
+			}
+			if got := cron.Next(from); !got.Equal(tc.want) {
+				t.Errorf("Next(%s) = %s, want %s", from, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCron_NextInTimezone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no tz database: %v", err)
+	}
+	cron, _ := ParseCron("0 18 * * *")
+	from := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC).In(ny)
+
+	want := time.Date(2026, 10, 16, 22, 0, 0, 0, time.UTC) // 18:00 EDT
+	if got := cron.Next(from); !got.Equal(want) {
+		t.Errorf("Next = %s, want %s", got.UTC(), want)
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "* * * * funday"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) err = nil, want error", expr)
+		}
+	}
+
+	cron, err := ParseCron("0 0 30 feb *")
+	if err != nil {
+		t.Fatalf("ParseCron(feb 30) err = %v", err)
+	}
+	if next := cron.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Next(feb 30) = %s, want never", next)
+	}
+}
@@ -0,0 +1,472 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
+	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
+)
+
+// Schedule actions
+const (
+	ActionTrainParent   = tasks.ActionTrainParent
+	ActionTrainChild    = tasks.ActionTrainChild
+	ActionMonitorParent = "monitor-parent"
+	ActionMonitorTicker = tasks.ActionMonitorTicker
+)
+
+// tickInterval is how often schedules are checked for a due run
+const tickInterval = 15 * time.Second
+
+// Schedule errors
+var (
+	ErrNotFound = errors.New("schedule not found")
+	ErrExists   = errors.New("schedule already exists")
+	ErrInvalid  = errors.New("invalid schedule")
+)
+
+// Schedule runs an action on a cron schedule: training is submitted to the
+// task manager, monitoring runs in the scheduler itself
+type Schedule struct {
+	ID       string   `json:"id"`
+	Action   string   `json:"action"`
+	Tickers  []string `json:"tickers,omitempty"`
+	Cron     string   `json:"cron"`
+	Timezone string   `json:"timezone"`
+	Priority string   `json:"priority,omitempty"`
+	Enabled  bool     `json:"enabled"`
+
+	CreatedAt  string            `json:"created_at"`
+	UpdatedAt  string            `json:"updated_at"`
+	NextRun    string            `json:"next_run,omitempty"`
+	LastRun    string            `json:"last_run,omitempty"`
+	LastRunBy  string            `json:"last_run_by,omitempty"` // instance that ran it
+	LastStatus string            `json:"last_status,omitempty"` // running, ok, partial, failed
+	LastResult map[string]string `json:"last_result,omitempty"` // outcome per ticker, or under "parent"
+}
+
+// Spec is what a client sets on a schedule; the scheduler fills in the rest
+type Spec struct {
+	ID       string   `json:"id"`
+	Action   string   `json:"action"`
+	Tickers  []string `json:"tickers"`
+	Cron     string   `json:"cron"`
+	Timezone string   `json:"timezone"`
+	Priority string   `json:"priority"`
+	Enabled  *bool    `json:"enabled"` // default true
+}
+
+// Scheduler runs recurring training and monitoring. Every instance checks
+// the shared schedules, but a schedule only runs on the instance holding
+// its lease.
+type Scheduler struct {
+	cfg         *config.Config
+	redis       *redisclient.Client
+	store       store
+	taskManager tasks.ManagerInterface
+	runner      python.RunnerInterface
+	instanceID  string
+	lockTTL     time.Duration
+	enabled     bool
+	now         func() time.Time
+
+	mu      sync.Mutex
+	running map[string]bool // schedules running on this instance
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+}
+
+// New creates a scheduler. Schedules are kept in Redis, or in memory
+// without it.
+func New(cfg *config.Config, redis *redisclient.Client, taskManager tasks.ManagerInterface, runner python.RunnerInterface) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cfg:         cfg,
+		redis:       redis,
+		store:       newStore(redis),
+		taskManager: taskManager,
+		runner:      runner,
+		instanceID:  cfg.InstanceID,
+		lockTTL:     time.Duration(cfg.TaskLockTTL) * time.Second,
+		enabled:     cfg.SchedulerEnabled,
+		now:         time.Now,
+		running:     make(map[string]bool),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start begins running due schedules, unless SCHEDULER_ENABLED is off on
+// this instance
+func (s *Scheduler) Start() {
+	if !s.enabled {
+		log.Printf("Scheduler disabled on this instance")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(tickInterval)
+		defer ticker.Stop()
+		for {
+			s.tick()
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops the scheduler and any monitoring it is running
+func (s *Scheduler) Close() {
+	s.closeOnce.Do(s.cancel)
+}
+
+// List returns every schedule, ordered by ID
+func (s *Scheduler) List() ([]*Schedule, error) {
+	return s.store.List()
+}
+
+// Get returns a schedule, or nil if it is unknown
+func (s *Scheduler) Get(id string) (*Schedule, error) {
+	return s.store.Get(id)
+}
+
+// Create adds a schedule. Without an ID one is generated from the action.
+func (s *Scheduler) Create(spec Spec) (*Schedule, error) {
+	if spec.ID == "" {
+		suffix := make([]byte, 4)
+		rand.Read(suffix)
+		spec.ID = spec.Action + "-" + hex.EncodeToString(suffix)
+	}
+
+	now := s.now()
+	schedule := &Schedule{CreatedAt: timestamp(now)}
+	if err := s.apply(schedule, spec, now); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.store.Get(schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: %s", ErrExists, schedule.ID)
+	}
+	if err := s.store.Save(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Update replaces a schedule's definition, keeping its run history
+func (s *Scheduler) Update(id string, spec Spec) (*Schedule, error) {
+	schedule, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	spec.ID = id
+	if err := s.apply(schedule, spec, s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.store.Save(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Delete removes a schedule. It returns false if there was none.
+func (s *Scheduler) Delete(id string) (bool, error) {
+	return s.store.Delete(id)
+}
+
+// scheduleIDPattern matches IDs that are safe in URLs and Redis keys
+var scheduleIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// apply validates spec and sets it on schedule, computing the next run
+func (s *Scheduler) apply(schedule *Schedule, spec Spec, now time.Time) error {
+	id := strings.ToLower(strings.TrimSpace(spec.ID))
+	if !scheduleIDPattern.MatchString(id) {
+		return fmt.Errorf("%w: id %q must be lowercase letters, digits, - or _", ErrInvalid, spec.ID)
+	}
+
+	action := strings.ToLower(strings.TrimSpace(spec.Action))
+	var tickers []string
+	for _, ticker := range spec.Tickers {
+		if ticker = strings.ToUpper(strings.TrimSpace(ticker)); ticker != "" {
+			tickers = append(tickers, ticker)
+		}
+	}
+	switch action {
+	case ActionTrainChild, ActionMonitorTicker:
+		if len(tickers) == 0 {
+			return fmt.Errorf("%w: %s needs at least one ticker", ErrInvalid, action)
+		}
+	case ActionTrainParent, ActionMonitorParent:
+		if len(tickers) > 0 {
+			return fmt.Errorf("%w: %s takes no tickers", ErrInvalid, action)
+		}
+	default:
+		return fmt.Errorf("%w: unknown action %q (want %s, %s, %s or %s)", ErrInvalid, spec.Action,
+			ActionTrainParent, ActionTrainChild, ActionMonitorParent, ActionMonitorTicker)
+	}
+
+	if _, err := ParseCron(spec.Cron); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	timezone := strings.TrimSpace(spec.Timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("%w: timezone %q: %v", ErrInvalid, spec.Timezone, err)
+	}
+	priority, err := tasks.ParsePriority(spec.Priority)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	schedule.ID = id
+	schedule.Action = action
+	schedule.Tickers = tickers
+	schedule.Cron = strings.TrimSpace(spec.Cron)
+	schedule.Timezone = timezone
+	schedule.Priority = priority.String()
+	schedule.Enabled = spec.Enabled == nil || *spec.Enabled
+	schedule.UpdatedAt = timestamp(now)
+	schedule.NextRun = ""
+	if schedule.Enabled {
+		schedule.NextRun = nextRun(schedule, now)
+	}
+	return nil
+}
+
+// nextRun returns when a schedule next runs after t, or "" if never
+func nextRun(schedule *Schedule, t time.Time) string {
+	cron, err := ParseCron(schedule.Cron)
+	if err != nil {
+		return ""
+	}
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	next := cron.Next(t.In(loc))
+	if next.IsZero() {
+		return ""
+	}
+	return timestamp(next)
+}
+
+// due reports whether a schedule should run at now
+func due(schedule *Schedule, now time.Time) bool {
+	if !schedule.Enabled || schedule.NextRun == "" {
+		return false
+	}
+	next, err := time.Parse(time.RFC3339, schedule.NextRun)
+	return err == nil && !next.After(now)
+}
+
+// tick starts every due schedule not already running here
+func (s *Scheduler) tick() {
+	schedules, err := s.store.List()
+	if err != nil {
+		log.Printf("Failed to list schedules: %v", err)
+		return
+	}
+
+	now := s.now()
+	for _, schedule := range schedules {
+		if !due(schedule, now) {
+			continue
+		}
+		s.mu.Lock()
+		busy := s.running[schedule.ID]
+		s.running[schedule.ID] = true
+		s.mu.Unlock()
+		if busy {
+			continue
+		}
+
+		go func(id string) {
+			defer func() {
+				s.mu.Lock()
+				delete(s.running, id)
+				s.mu.Unlock()
+			}()
+			s.fire(id)
+		}(schedule.ID)
+	}
+}
+
+// fire runs one due schedule if this instance wins its lease. A run missed
+// while no instance was up fires once, then the schedule carries on from now.
+func (s *Scheduler) fire(id string) {
+	release, ok := s.acquire(id)
+	if !ok {
+		return // another instance is running it
+	}
+	defer release()
+
+	// Another instance may have run it between our check and the lease
+	schedule, err := s.store.Get(id)
+	now := s.now()
+	if err != nil || schedule == nil || !due(schedule, now) {
+		return
+	}
+
+	schedule.LastRun = timestamp(now)
+	schedule.LastRunBy = s.instanceID
+	schedule.LastStatus = "running"
+	schedule.LastResult = nil
+	schedule.NextRun = nextRun(schedule, now)
+	if err := s.store.Save(schedule); err != nil {
+		log.Printf("Failed to save schedule %s: %v", id, err)
+		return
+	}
+	log.Printf("Schedule %s: running %s %v", id, schedule.Action, schedule.Tickers)
+
+	results, status := s.run(schedule)
+
+	// Keep any edit made while it ran; only record the outcome
+	latest, err := s.store.Get(id)
+	if err != nil || latest == nil || latest.LastRun != schedule.LastRun {
+		return
+	}
+	latest.LastStatus = status
+	latest.LastResult = results
+	if err := s.store.Save(latest); err != nil {
+		log.Printf("Failed to save schedule %s: %v", id, err)
+	}
+	log.Printf("Schedule %s: %s", id, status)
+}
+
+// run performs a schedule's action and returns the outcome per ticker and
+// overall
+func (s *Scheduler) run(schedule *Schedule) (map[string]string, string) {
+	priority, _ := tasks.ParsePriority(schedule.Priority)
+	results := make(map[string]string)
+	failed := 0
+
+	record := func(key, outcome string, err error) {
+		if err != nil {
+			results[key] = "error: " + err.Error()
+			failed++
+			return
+		}
+		results[key] = outcome
+	}
+
+	switch schedule.Action {
+	case ActionTrainParent:
+		outcome, err := s.taskManager.StartTrainParent(priority)
+		record("parent", describe(outcome), err)
+	case ActionTrainChild:
+		parentMissing := !s.parentModelExists()
+		for _, ticker := range schedule.Tickers {
+			outcome, err := s.taskManager.StartTrainChild(strings.ToLower(ticker), priority, tasks.ChildPipeline(parentMissing))
+			record(ticker, describe(outcome), err)
+		}
+	case ActionMonitorParent:
+		_, err := s.runner.MonitorParent(s.ctx)
+		record("parent", "ok", err)
+	case ActionMonitorTicker:
+		for _, ticker := range schedule.Tickers {
+			_, err := s.runner.MonitorTicker(s.ctx, ticker)
+			record(ticker, "ok", err)
+		}
+	}
+
+	switch {
+	case failed == 0:
+		return results, "ok"
+	case failed == len(results):
+		return results, "failed"
+	}
+	return results, "partial"
+}
+
+// describe names a task manager outcome
+func describe(outcome tasks.Outcome) string {
+	switch outcome {
+	case tasks.OutcomeQueued:
+		return "queued"
+	case tasks.OutcomeActive:
+		return "already running"
+	}
+	return "started"
+}
+
+// parentModelExists reports whether the parent model is on disk, so child
+// training only trains the parent first when it must
+func (s *Scheduler) parentModelExists() bool {
+	_, err := os.Stat(filepath.Join(s.cfg.ParentDir, s.cfg.ParentTicker+"_parent_model.pt"))
+	return err == nil
+}
+
+// acquire takes a schedule's lease, renewed until release is called, so no
+// other instance runs it meanwhile. Without Redis, the running set in tick
+// is the only guard needed; if Redis fails, the schedule is skipped so it
+// never runs twice.
+func (s *Scheduler) acquire(id string) (release func(), ok bool) {
+	if s.redis == nil {
+		return func() {}, true
+	}
+
+	lock, err := s.redis.AcquireLock(context.Background(), redisclient.ScheduleLockKey(id), s.instanceID, s.lockTTL)
+	if err != nil {
+		log.Printf("Failed to take lease on schedule %s: %v", id, err)
+		return nil, false
+	}
+	if lock == nil {
+		return nil, false
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(s.lockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, err := lock.Renew(context.Background()); err != nil {
+					log.Printf("Failed to renew lease on schedule %s: %v", id, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		if err := lock.Release(context.Background()); err != nil {
+			log.Printf("Failed to release lease on schedule %s: %v", id, err)
+		}
+	}, true
+}
+
+// timestamp formats times in schedules
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
+)
+
+// fakeManager is a tasks.ManagerInterface that records training requests
+type fakeManager struct {
+	mu      sync.Mutex
+	started []string
+	outcome tasks.Outcome
+}
+
+func (m *fakeManager) GetStatus(string) *tasks.TaskStatus { return nil }
+func (m *fakeManager) IsRunning(string) bool              { return false }
+func (m *fakeManager) StartTrainParent(tasks.Priority) (tasks.Outcome, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.started = append(m.started, tasks.ParentTaskID)
+	return m.outcome, nil
+}
+func (m *fakeManager) StartTrainChild(ticker string, _ tasks.Priority, _ []tasks.Step) (tasks.Outcome, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.started = append(m.started, ticker)
+	return m.outcome, nil
+}
+func (m *fakeManager) StartTrainBatch(tasks.BatchSpec) (*tasks.Batch, error) { return nil, nil }
+func (m *fakeManager) GetBatch(string) *tasks.Batch                          { return nil }
+func (m *fakeManager) Cancel(string, string) bool                            { return false }
+func (m *fakeManager) GetRun(string) *tasks.Run                              { return nil }
+func (m *fakeManager) Resume(string) (tasks.Outcome, error)                  { return tasks.OutcomeStarted, nil }
+func (m *fakeManager) ListRuns(tasks.RunFilter) ([]*tasks.Run, int, error)   { return nil, 0, nil }
+func (m *fakeManager) ListDeadLetters() ([]*tasks.DeadLetter, error)         { return nil, nil }
+func (m *fakeManager) RetryDeadLetter(string) (tasks.Outcome, error) {
+	return tasks.OutcomeStarted, nil
+}
+func (m *fakeManager) DeleteDeadLetter(string) (bool, error) { return false, nil }
+
+func testScheduler(t *testing.T, manager tasks.ManagerInterface, now time.Time) *Scheduler {
+	t.Helper()
+	cfg := config.Load()
+	cfg.ParentDir = t.TempDir()
+	s := New(cfg, nil, manager, nil)
+	s.now = func() time.Time { return now }
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestCreate_Validates(t *testing.T) {
+	s := testScheduler(t, &fakeManager{}, time.Now())
+
+	for name, spec := range map[string]Spec{
+		"unknown action":    {Action: "deploy", Cron: "@daily"},
+		"missing tickers":   {Action: ActionTrainChild, Cron: "@daily"},
+		"tickers on parent": {Action: ActionTrainParent, Tickers: []string{"AAPL"}, Cron: "@daily"},
+		"bad cron":          {Action: ActionTrainParent, Cron: "every night"},
+		"bad timezone":      {Action: ActionTrainParent, Cron: "@daily", Timezone: "Mars/Olympus"},
+		"bad priority":      {Action: ActionTrainParent, Cron: "@daily", Priority: "urgent"},
+		"bad id":            {ID: "Nightly Train!", Action: ActionTrainParent, Cron: "@daily"},
+	} {
+		if _, err := s.Create(spec); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Create(%s) err = %v, want ErrInvalid", name, err)
+		}
+	}
+
+	if _, err := s.Create(Spec{ID: "nightly", Action: ActionTrainParent, Cron: "@daily"}); err != nil {
+		t.Fatalf("Create(nightly) err = %v", err)
+	}
+	if _, err := s.Create(Spec{ID: "nightly", Action: ActionTrainParent, Cron: "@daily"}); !errors.Is(err, ErrExists) {
+		t.Errorf("Create(nightly) again err = %v, want ErrExists", err)
+	}
+}
+
+func TestFire_RunsOnceAndSchedulesNext(t *testing.T) {
+	manager := &fakeManager{}
+	created := time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC)
+	s := testScheduler(t, manager, created)
+
+	schedule, err := s.Create(Spec{ID: "nightly", Action: ActionTrainChild, Tickers: []string{"aapl", "msft"}, Cron: "0 2 * * *"})
+	if err != nil {
+		t.Fatalf("Create err = %v", err)
+	}
+	if schedule.NextRun != "2026-10-17T02:00:00Z" || schedule.Timezone != "UTC" || strings.Join(schedule.Tickers, ",") != "AAPL,MSFT" {
+		t.Fatalf("Create = %+v, want AAPL,MSFT next at 2026-10-17T02:00:00Z UTC", schedule)
+	}
+
+	// Not due yet
+	s.fire("nightly")
+	if len(manager.started) != 0 {
+		t.Fatalf("fire before next run started %v", manager.started)
+	}
+
+	due := time.Date(2026, 10, 17, 2, 0, 5, 0, time.UTC)
+	s.now = func() time.Time { return due }
+	s.fire("nightly")
+	s.fire("nightly") // already ran for this slot
+
+	if strings.Join(manager.started, ",") != "aapl,msft" {
+		t.Errorf("fire started %v, want aapl,msft once", manager.started)
+	}
+	got, _ := s.Get("nightly")
+	if got.LastRun != "2026-10-17T02:00:05Z" || got.NextRun != "2026-10-18T02:00:00Z" {
+		t.Errorf("after fire last/next = %s/%s, want 2026-10-17T02:00:05Z/2026-10-18T02:00:00Z", got.LastRun, got.NextRun)
+	}
+	if got.LastStatus != "ok" || got.LastResult["AAPL"] != "started" {
+		t.Errorf("after fire status = %s %v, want ok with AAPL started", got.LastStatus, got.LastResult)
+	}
+}
+
+func TestUpdate_DisableClearsNextRun(t *testing.T) {
+	s := testScheduler(t, &fakeManager{}, time.Now())
+	s.Create(Spec{ID: "hourly-drift", Action: ActionMonitorTicker, Tickers: []string{"AAPL"}, Cron: "@hourly"})
+
+	disabled := false
+	schedule, err := s.Update("hourly-drift", Spec{Action: ActionMonitorTicker, Tickers: []string{"AAPL"}, Cron: "@hourly", Enabled: &disabled})
+	if err != nil {
+		t.Fatalf("Update err = %v", err)
+	}
+	if schedule.Enabled || schedule.NextRun != "" || schedule.CreatedAt == "" {
+		t.Errorf("Update(disabled) = %+v, want disabled with no next run", schedule)
+	}
+
+	if _, err := s.Update("ghost", Spec{Action: ActionTrainParent, Cron: "@daily"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update(ghost) err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
+)
+
+// store keeps schedule definitions and their last and next run times
+type store interface {
+	Save(schedule *Schedule) error
+	Get(id string) (*Schedule, error) // nil when unknown
+	List() ([]*Schedule, error)       // ordered by ID
+	Delete(id string) (bool, error)
+}
+
+// newStore returns a Redis-backed store, or an in-memory one without Redis
+func newStore(redis *redisclient.Client) store {
+	if redis == nil {
+		return &memoryStore{schedules: make(map[string]*Schedule)}
+	}
+	return &redisStore{redis: redis}
+}
+
+// memoryStore keeps schedules in this process only
+type memoryStore struct {
+	mu        sync.Mutex
+	schedules map[string]*Schedule
+}
+
+func (s *memoryStore) Save(schedule *Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *schedule
+	s.schedules[schedule.ID] = &copied
+	return nil
+}
+
+func (s *memoryStore) Get(id string) (*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedule, ok := s.schedules[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *schedule
+	return &copied, nil
+}
+
+func (s *memoryStore) List() ([]*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedules := make([]*Schedule, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		copied := *schedule
+		schedules = append(schedules, &copied)
+	}
+	sort.Slice(schedules, func(a, b int) bool { return schedules[a].ID < schedules[b].ID })
+	return schedules, nil
+}
+
+func (s *memoryStore) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.schedules[id]
+	delete(s.schedules, id)
+	return ok, nil
+}
+
+// redisStore keeps each schedule as JSON under its own key, shared by
+// every instance using the same Redis
+type redisStore struct {
+	redis *redisclient.Client
+}
+
+func (s *redisStore) Save(schedule *Schedule) error {
+	data, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+	return s.redis.Set(context.Background(), redisclient.ScheduleKey(schedule.ID), string(data), 0)
+}
+
+func (s *redisStore) Get(id string) (*Schedule, error) {
+	val, err := s.redis.Get(context.Background(), redisclient.ScheduleKey(id))
+	if err != nil {
+		return nil, nil
+	}
+	var schedule Schedule
+	if err := json.Unmarshal([]byte(val), &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (s *redisStore) List() ([]*Schedule, error) {
+	keys, err := s.redis.Keys(context.Background(), redisclient.ScheduleKey("*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+
+	schedules := make([]*Schedule, 0, len(keys))
+	for _, key := range keys {
+		schedule, err := s.Get(strings.TrimPrefix(key, redisclient.ScheduleKey("")))
+		if err != nil || schedule == nil {
+			continue
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+func (s *redisStore) Delete(id string) (bool, error) {
+	key := redisclient.ScheduleKey(id)
+	if _, err := s.redis.Get(context.Background(), key); err != nil {
+		return false, nil
+	}
+	return true, s.redis.Del(context.Background(), key)
+}
@@ -0,0 +1,340 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
+)
+
+// MaxBatchSize bounds the tickers of one batch
+const MaxBatchSize = 100
+
+// Batch item statuses besides those of a run
+const (
+	BatchItemExists = "exists" // the child model was already trained
+	BatchItemFailed = "failed"
+)
+
+// ErrInvalidBatch is returned when a batch request cannot be submitted
+var ErrInvalidBatch = errors.New("invalid batch")
+
+// BatchSpec is a request to train many child models at once
+type BatchSpec struct {
+	Tickers     []string // tickers to train
+	Existing    []string // tickers whose model already exists; not trained
+	Priority    Priority
+	TrainParent bool     // the parent model is missing
+	Then        []string // follow-up actions of every child pipeline
+}
+
+// BatchItem is one ticker of a batch and the run training it
+type BatchItem struct {
+	Ticker string `json:"ticker"`
+	TaskID string `json:"task_id"`
+	RunID  string `json:"run_id,omitempty"`
+	Status string `json:"status"` // a run status, retrying, exists, or failed when it could not be submitted
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// finished reports whether the item's run has ended
+func (i BatchItem) finished() bool {
+	switch i.Status {
+	case "queued", "running", "retrying":
+		return false
+	}
+	return true
+}
+
+// Batch is a set of child trainings submitted together. Its status and
+// counts are derived from the runs of its items whenever it is read.
+type Batch struct {
+	BatchID     string         `json:"batch_id"`
+	Status      string         `json:"status"` // running, completed, partial, failed
+	Priority    string         `json:"priority"`
+	CreatedAt   string         `json:"created_at"`
+	FinishedAt  string         `json:"finished_at,omitempty"`
+	ParentRunID string         `json:"parent_run_id,omitempty"` // parent training started for the batch
+	Counts      map[string]int `json:"counts"`
+	Items       []BatchItem    `json:"items"`
+}
+
+// summarize sets a batch's counts and overall status from its items
+func (b *Batch) summarize(now time.Time) {
+	b.Counts = make(map[string]int)
+	ok, failed := 0, 0
+	for _, item := range b.Items {
+		b.Counts[item.Status]++
+		switch {
+		case !item.finished():
+		case item.Status == "completed" || item.Status == BatchItemExists:
+			ok++
+		default:
+			failed++
+		}
+	}
+
+	switch {
+	case ok+failed < len(b.Items):
+		b.Status = "running"
+		b.FinishedAt = ""
+		return
+	case failed == 0:
+		b.Status = "completed"
+	case ok == 0:
+		b.Status = "failed"
+	default:
+		b.Status = "partial"
+	}
+	if b.FinishedAt == "" {
+		b.FinishedAt = timestamp(now)
+	}
+}
+
+// batchStore keeps batches
+type batchStore interface {
+	Save(batch *Batch) error
+	Get(batchID string) (*Batch, error) // nil when unknown
+}
+
+// newBatchStore returns a Redis-backed store, or an in-memory one without Redis
+func newBatchStore(redis *redisclient.Client, retention time.Duration) batchStore {
+	if redis == nil {
+		return &memoryBatchStore{batches: make(map[string]*Batch)}
+	}
+	return &redisBatchStore{redis: redis, retention: retention}
+}
+
+// memoryBatchesMax bounds the in-memory batches
+const memoryBatchesMax = 100
+
+// memoryBatchStore keeps the newest batches in this process only
+type memoryBatchStore struct {
+	mu      sync.Mutex
+	batches map[string]*Batch
+	order   []string // batch IDs, oldest first
+}
+
+func (s *memoryBatchStore) Save(batch *Batch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.batches[batch.BatchID]; !ok {
+		s.order = append(s.order, batch.BatchID)
+	}
+	s.batches[batch.BatchID] = copyBatch(batch)
+
+	for len(s.order) > memoryBatchesMax {
+		delete(s.batches, s.order[0])
+		s.order = s.order[1:]
+	}
+	return nil
+}
+
+func (s *memoryBatchStore) Get(batchID string) (*Batch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch, ok := s.batches[batchID]
+	if !ok {
+		return nil, nil
+	}
+	return copyBatch(batch), nil
+}
+
+// copyBatch copies a batch and its items
+func copyBatch(batch *Batch) *Batch {
+	copied := *batch
+	copied.Items = append([]BatchItem(nil), batch.Items...)
+	return &copied
+}
+
+// redisBatchStore keeps each batch under its own key for as long as the
+// run history
+type redisBatchStore struct {
+	redis     *redisclient.Client
+	retention time.Duration
+}
+
+func (s *redisBatchStore) Save(batch *Batch) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	return s.redis.Set(context.Background(), redisclient.TaskBatchKey(batch.BatchID), string(data), s.retention)
+}
+
+func (s *redisBatchStore) Get(batchID string) (*Batch, error) {
+	val, err := s.redis.Get(context.Background(), redisclient.TaskBatchKey(batchID))
+	if err != nil {
+		return nil, nil
+	}
+
+	var batch Batch
+	if err := json.Unmarshal([]byte(val), &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// StartTrainBatch submits child training for every ticker of spec as one
+// batch. When the parent model is missing, parent training is submitted
+// once, ahead of the children, whose train-parent step then joins it
+// instead of training the parent again. Each child goes through the
+// training queue, so the batch never uses more than MAX_WORKERS workers.
+func (m *Manager) StartTrainBatch(spec BatchSpec) (*Batch, error) {
+	total := len(spec.Tickers) + len(spec.Existing)
+	if total == 0 {
+		return nil, fmt.Errorf("%w: no tickers", ErrInvalidBatch)
+	}
+	if total > MaxBatchSize {
+		return nil, fmt.Errorf("%w: %d tickers, at most %d allowed", ErrInvalidBatch, total, MaxBatchSize)
+	}
+	if err := ValidateFollowUps(spec.Then); err != nil {
+		return nil, err
+	}
+	if _, err := m.validatePipeline(ChildPipeline(spec.TrainParent, spec.Then...)); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPipeline, err)
+	}
+
+	now := time.Now()
+	batch := &Batch{
+		BatchID:   newRunID("batch", now),
+		Priority:  spec.Priority.String(),
+		CreatedAt: timestamp(now),
+	}
+
+	if spec.TrainParent && len(spec.Tickers) > 0 {
+		_, runID, err := m.submitRun(Job{
+			TaskID:   ParentTaskID,
+			Kind:     JobTrainParent,
+			Priority: spec.Priority,
+			Steps:    ParentPipeline(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to start parent training: %w", err)
+		}
+		batch.ParentRunID = runID
+	}
+
+	for _, ticker := range spec.Existing {
+		batch.Items = append(batch.Items, BatchItem{
+			Ticker: ticker,
+			TaskID: taskIDFor(ticker),
+			Status: BatchItemExists,
+			Detail: "Model already exists",
+		})
+	}
+	for _, ticker := range spec.Tickers {
+		taskID := taskIDFor(ticker)
+		item := BatchItem{Ticker: ticker, TaskID: taskID}
+
+		outcome, runID, err := m.submitRun(Job{
+			TaskID:   taskID,
+			Kind:     JobTrainChild,
+			Ticker:   taskID,
+			Priority: spec.Priority,
+			Steps:    ChildPipeline(spec.TrainParent, spec.Then...),
+		})
+		if err != nil {
+			item.Status = BatchItemFailed
+			item.Error = err.Error()
+		} else {
+			item.RunID = runID
+			item.Status = "queued" // until refreshed from its run
+			if outcome == OutcomeActive {
+				item.Detail = "Training already in progress"
+			}
+		}
+		batch.Items = append(batch.Items, item)
+	}
+
+	m.refreshBatch(batch)
+	if err := m.batches.Save(batch); err != nil {
+		log.Printf("Failed to save batch %s: %v", batch.BatchID, err)
+	}
+	log.Printf("Batch %s: %d tickers submitted, %d already trained", batch.BatchID, len(spec.Tickers), len(spec.Existing))
+	return batch, nil
+}
+
+// GetBatch returns a batch with the current state of each ticker, or nil
+// if it is unknown or expired
+func (m *Manager) GetBatch(batchID string) *Batch {
+	batch, err := m.batches.Get(batchID)
+	if err != nil {
+		log.Printf("Failed to read batch %s: %v", batchID, err)
+		return nil
+	}
+	if batch == nil {
+		return nil
+	}
+
+	wasFinished := batch.FinishedAt != ""
+	if m.refreshBatch(batch) || (!wasFinished && batch.FinishedAt != "") {
+		if err := m.batches.Save(batch); err != nil {
+			log.Printf("Failed to save batch %s: %v", batchID, err)
+		}
+	}
+	return batch
+}
+
+// refreshBatch updates each unfinished item from its run and summarizes the
+// batch. It reports whether any item changed.
+func (m *Manager) refreshBatch(batch *Batch) bool {
+	changed := false
+	for i := range batch.Items {
+		item := &batch.Items[i]
+		if item.finished() {
+			continue
+		}
+
+		// An item joined to a run whose ID was not known yet adopts the
+		// task's current run
+		if item.RunID == "" {
+			if current := m.GetStatus(item.TaskID); current != nil && current.RunID != "" {
+				item.RunID = current.RunID
+				changed = true
+			}
+		}
+
+		status, errText := item.Status, item.Error
+		if run := m.GetRun(item.RunID); run != nil {
+			status, errText = run.Status, run.Error
+		} else if current := m.GetStatus(item.TaskID); current != nil && current.RunID == item.RunID {
+			status, errText = current.Status, current.Error
+		}
+
+		// A failed run may be followed by another attempt: pending, or a
+		// newer run resumed from it
+		for status == "failed" {
+			if next := m.latestRun(item.TaskID); next != nil && next.ResumedFrom == item.RunID {
+				item.RunID = next.RunID
+				status, errText = next.Status, next.Error
+				changed = true
+				continue
+			}
+			if m.retries.Has(item.TaskID) {
+				status = "retrying"
+			}
+			break
+		}
+		if status != item.Status || errText != item.Error {
+			item.Status, item.Error = status, errText
+			changed = true
+		}
+	}
+	batch.summarize(time.Now())
+	return changed
+}
+
+// taskIDFor returns the task ID training a ticker's child model
+func taskIDFor(ticker string) string {
+	return strings.ToLower(ticker)
+}
@@ -0,0 +1,76 @@
+package tasks
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitBatch waits for a batch to finish
+func waitBatch(t *testing.T, m *Manager, batchID string) *Batch {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if batch := m.GetBatch(batchID); batch != nil && batch.Status != "running" {
+			return batch
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("batch %s did not finish: %+v", batchID, m.GetBatch(batchID))
+	return nil
+}
+
+func TestBatch_TrainsParentOnceAndAggregates(t *testing.T) {
+	runner := newScriptedRunner()
+	runner.parentGate = make(chan struct{})
+	m := testManager(t, runner, 2)
+	m.parentPollInterval = 5 * time.Millisecond
+
+	batch, err := m.StartTrainBatch(BatchSpec{
+		Tickers:     []string{"AAPL", "MSFT", "NVDA"},
+		Existing:    []string{"TSLA"},
+		Priority:    PriorityNormal,
+		TrainParent: true,
+	})
+	if err != nil {
+		t.Fatalf("StartTrainBatch = %v", err)
+	}
+	if batch.Status != "running" || batch.ParentRunID == "" {
+		t.Errorf("new batch = %+v, want running with a parent run", batch)
+	}
+	// The parent holds one of the two workers, so only one child can start
+	if batch.Counts["queued"] == 0 {
+		t.Errorf("new batch counts = %v, want some children queued", batch.Counts)
+	}
+	close(runner.parentGate)
+
+	batch = waitBatch(t, m, batch.BatchID)
+	if batch.Status != "completed" || batch.Counts["completed"] != 3 || batch.Counts[BatchItemExists] != 1 {
+		t.Errorf("finished batch = %s %v, want 3 completed and 1 existing", batch.Status, batch.Counts)
+	}
+	if n := runner.called("train-parent"); n != 1 {
+		t.Errorf("parent trained %d times, want once", n)
+	}
+	for _, item := range batch.Items {
+		if item.Status != BatchItemExists && item.RunID == "" {
+			t.Errorf("item %s has no run ID", item.Ticker)
+		}
+	}
+}
+
+func TestBatch_Invalid(t *testing.T) {
+	m := testManager(t, newScriptedRunner(), 1)
+
+	if _, err := m.StartTrainBatch(BatchSpec{}); !errors.Is(err, ErrInvalidBatch) {
+		t.Errorf("StartTrainBatch(no tickers) = %v, want ErrInvalidBatch", err)
+	}
+	if _, err := m.StartTrainBatch(BatchSpec{Tickers: []string{"AAPL"}, Then: []string{"nope"}}); !errors.Is(err, ErrInvalidPipeline) {
+		t.Errorf("StartTrainBatch(unknown follow-up) = %v, want ErrInvalidPipeline", err)
+	}
+	if _, err := m.StartTrainBatch(BatchSpec{Tickers: []string{"AAPL"}, Then: []string{ActionTrainParent}}); !errors.Is(err, ErrInvalidPipeline) {
+		t.Errorf("StartTrainBatch(train-parent follow-up) = %v, want ErrInvalidPipeline", err)
+	}
+	if m.GetBatch("missing") != nil {
+		t.Error("GetBatch(missing) != nil")
+	}
+}
@@ -0,0 +1,97 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+
+	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
+)
+
+// cancelRequest asks the instance running a task to cancel it. It names the
+// run, so a request never cancels a later run of the same task.
+type cancelRequest struct {
+	TaskID      string `json:"task_id"`
+	RunID       string `json:"run_id"`
+	CancelledBy string `json:"cancelled_by"`
+}
+
+// cancelRemote asks the instance holding a task's lease to cancel it. The
+// request is broadcast and also left in Redis, where the lease holder finds
+// it at its next renewal should the broadcast be missed. It returns false
+// if no other instance holds the lease.
+func (m *Manager) cancelRemote(taskID, cancelledBy string) bool {
+	owner := m.lockOwner(taskID)
+	i := strings.LastIndex(owner, "/")
+	if i < 0 || owner[:i] == m.instanceID {
+		return false // not running, or it just ended here
+	}
+	runID := owner[i+1:]
+	req := cancelRequest{TaskID: taskID, RunID: runID, CancelledBy: cancelledBy}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		log.Printf("Failed to encode cancel request for %s: %v", taskID, err)
+		return false
+	}
+	ctx := context.Background()
+	if err := m.redis.Set(ctx, redisclient.TaskCancelKey(taskID), data, 2*m.lockTTL); err != nil {
+		log.Printf("Failed to store cancel request for %s: %v", taskID, err)
+		return false
+	}
+	if err := m.redis.Publish(ctx, redisclient.TaskCancelChannel, string(data)); err != nil {
+		log.Printf("Failed to broadcast cancel request for %s, its lease holder will find it on renewal: %v", taskID, err)
+	}
+	log.Printf("Training task %s: asked %s to cancel it", taskID, owner)
+	return true
+}
+
+// listenForCancels handles the cancel requests other instances broadcast
+// until the manager is closed
+func (m *Manager) listenForCancels() {
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := m.redis.Subscribe(ctx, redisclient.TaskCancelChannel, m.handleCancelRequest); err != nil {
+		cancel()
+		log.Printf("Task cancel subscription failed, cancel requests are picked up on lease renewal: %v", err)
+		return
+	}
+	go func() {
+		<-m.stop
+		cancel()
+	}()
+}
+
+// handleCancelRequest cancels the requested run if it runs on this instance
+func (m *Manager) handleCancelRequest(message string) {
+	var req cancelRequest
+	if err := json.Unmarshal([]byte(message), &req); err != nil {
+		log.Printf("Ignoring malformed cancel request %q: %v", message, err)
+		return
+	}
+
+	m.mu.Lock()
+	task := m.active[req.TaskID]
+	m.mu.Unlock()
+	if task == nil || task.job.RunID != req.RunID {
+		return
+	}
+
+	m.cancelTask(task, req.CancelledBy)
+	if m.redis != nil {
+		m.redis.Del(context.Background(), redisclient.TaskCancelKey(req.TaskID))
+	}
+}
+
+// checkCancelRequest cancels a running task if another instance left a
+// request for it in Redis
+func (m *Manager) checkCancelRequest(task *activeTask) {
+	if m.redis == nil || task.ctx.Err() != nil {
+		return
+	}
+	message, err := m.redis.Get(context.Background(), redisclient.TaskCancelKey(task.job.TaskID))
+	if err != nil {
+		return // no request, or Redis is unreachable and the renewal will say so
+	}
+	m.handleCancelRequest(message)
+}
@@ -0,0 +1,54 @@
+package tasks
+
+import (
+	"strings"
+	"time"
+)
+
+// Task lifecycle events
+const (
+	EventStarted   = "task.started"
+	EventCompleted = "task.completed"
+	EventFailed    = "task.failed" // Status tells whether another attempt is due
+	EventCancelled = "task.cancelled"
+)
+
+// Event is a change in a task's lifecycle on this instance
+type Event struct {
+	Type   string
+	TaskID string
+	RunID  string
+	Ticker string // uppercase; empty for parent training
+	Time   time.Time
+	Status TaskStatus // the status saved with the change
+}
+
+// OnEvent adds a listener called with every task lifecycle event. Listeners
+// are called on the task's goroutine, so they must not block.
+func (m *Manager) OnEvent(listener func(Event)) {
+	m.listenersMu.Lock()
+	defer m.listenersMu.Unlock()
+	m.listeners = append(m.listeners, listener)
+}
+
+// emit publishes a lifecycle event of a job to the listeners
+func (m *Manager) emit(eventType string, job Job, status TaskStatus) {
+	m.listenersMu.RLock()
+	listeners := m.listeners
+	m.listenersMu.RUnlock()
+	if len(listeners) == 0 {
+		return
+	}
+
+	event := Event{
+		Type:   eventType,
+		TaskID: job.TaskID,
+		RunID:  status.RunID,
+		Ticker: strings.ToUpper(job.Ticker),
+		Time:   time.Now(),
+		Status: status,
+	}
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
@@ -0,0 +1,116 @@
+package tasks
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventLog records the events a manager emits
+type eventLog struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (l *eventLog) record(event Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+// types returns "type task_id" of each event so far
+func (l *eventLog) types() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var types []string
+	for _, event := range l.events {
+		types = append(types, event.Type+" "+event.TaskID)
+	}
+	return types
+}
+
+// wait waits until n events were emitted and returns their types
+func (l *eventLog) wait(t *testing.T, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got := l.types()
+		if len(got) >= n {
+			return got
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("events = %v, want %d of them", got, n)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestEvents_Lifecycle(t *testing.T) {
+	runner := newBlockingRunner()
+	m := testManager(t, runner, 1)
+	events := &eventLog{}
+	m.OnEvent(events.record)
+
+	m.StartTrainChild("aapl", PriorityNormal, nil)
+	<-runner.started
+	m.StartTrainChild("msft", PriorityNormal, nil)
+	m.Cancel("msft", "tester")
+	m.Cancel("aapl", "tester")
+
+	want := []string{EventStarted + " aapl", EventCancelled + " msft", EventCancelled + " aapl"}
+	got := events.types()
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	events.mu.Lock()
+	started := events.events[0]
+	events.mu.Unlock()
+	if started.Ticker != "AAPL" || started.RunID == "" || started.Status.Status != "running" {
+		t.Errorf("started event = %+v, want AAPL running with a run ID", started)
+	}
+}
+
+func TestEvents_FailedAndCompleted(t *testing.T) {
+	runner := &failingRunner{scriptedRunner: newScriptedRunner(), err: errors.New("mlflow hiccup")}
+	m := retryManager(t, runner)
+	m.maxAttempts = 2
+	events := &eventLog{}
+	m.OnEvent(events.record)
+
+	m.StartTrainChild("aapl", PriorityNormal, nil)
+	runRetries(t, m, "aapl")
+
+	runner.mu.Lock()
+	runner.err = nil
+	runner.mu.Unlock()
+	m.StartTrainChild("msft", PriorityNormal, nil)
+
+	want := []string{
+		EventStarted + " aapl", EventFailed + " aapl",
+		EventStarted + " aapl", EventFailed + " aapl",
+		EventStarted + " msft", EventCompleted + " msft",
+	}
+	got := events.wait(t, len(want))
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	events.mu.Lock()
+	retrying, last := events.events[1].Status, events.events[3].Status
+	events.mu.Unlock()
+	if retrying.Status != "retrying" || retrying.Error == "" {
+		t.Errorf("first failure status = %+v, want retrying with the error", retrying)
+	}
+	if last.Status != "failed" || !last.DeadLettered {
+		t.Errorf("last failure status = %+v, want failed and dead-lettered", last)
+	}
+}
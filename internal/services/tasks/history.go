@@ -0,0 +1,253 @@
+package tasks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"regexp"
+	"sync"
+	"time"
+
+	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
+)
+
+// Run is the record of one training run. A task such as "aapl" has many
+// runs over time; its TaskStatus only describes the latest.
+type Run struct {
+	RunID           string                 `json:"run_id"`
+	TaskID          string                 `json:"task_id"`
+	Kind            string                 `json:"kind"`
+	Ticker          string                 `json:"ticker,omitempty"`
+	Priority        string                 `json:"priority"`
+	Status          string                 `json:"status"` // queued, running, completed, failed, cancelled, interrupted
+	CreatedAt       string                 `json:"created_at"`
+	StartedAt       string                 `json:"started_at,omitempty"`
+	FinishedAt      string                 `json:"finished_at,omitempty"`
+	DurationSeconds float64                `json:"duration_seconds,omitempty"`
+	Error           string                 `json:"error,omitempty"`
+	CancelledBy     string                 `json:"cancelled_by,omitempty"`
+	ExecutionID     string                 `json:"execution_id,omitempty"`
+	Metrics         map[string]float64     `json:"metrics,omitempty"`
+	Result          map[string]interface{} `json:"result,omitempty"`
+	Pipeline        []Step                 `json:"pipeline,omitempty"`
+	ResumedFrom     string                 `json:"resumed_from,omitempty"`
+	Attempt         int                    `json:"attempt,omitempty"`
+}
+
+// created parses CreatedAt, which orders runs in the history
+func (r *Run) created() time.Time {
+	t, _ := time.Parse(time.RFC3339, r.CreatedAt)
+	return t
+}
+
+// finished parses FinishedAt; it is zero while the run has not finished
+func (r *Run) finished() time.Time {
+	t, _ := time.Parse(time.RFC3339, r.FinishedAt)
+	return t
+}
+
+// RunFilter selects runs for ListRuns. Zero fields match everything.
+type RunFilter struct {
+	TaskID string
+	Status string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+	Offset int
+}
+
+// matches reports whether a run passes the task and status filters
+func (f RunFilter) matches(r *Run) bool {
+	return (f.TaskID == "" || r.TaskID == f.TaskID) && (f.Status == "" || r.Status == f.Status)
+}
+
+// inRange reports whether t falls within [Since, Until]
+func (f RunFilter) inRange(t time.Time) bool {
+	return (f.Since.IsZero() || !t.Before(f.Since)) && (f.Until.IsZero() || !t.After(f.Until))
+}
+
+// page returns the requested slice of runs
+func (f RunFilter) page(runs []*Run) []*Run {
+	if f.Offset >= len(runs) {
+		return []*Run{}
+	}
+	runs = runs[f.Offset:]
+	if f.Limit > 0 && f.Limit < len(runs) {
+		runs = runs[:f.Limit]
+	}
+	return runs
+}
+
+// runStore keeps the run history
+type runStore interface {
+	Save(run *Run) error
+	Get(runID string) (*Run, error) // nil when unknown
+	List(filter RunFilter) (runs []*Run, total int, err error)
+}
+
+// newRunStore returns a Redis-backed history, or an in-memory one without Redis
+func newRunStore(redis *redisclient.Client, retention time.Duration) runStore {
+	if redis == nil {
+		return &memoryRunStore{max: memoryRunsMax, runs: make(map[string]*Run)}
+	}
+	return &redisRunStore{redis: redis, retention: retention}
+}
+
+// memoryRunsMax bounds the in-memory history
+const memoryRunsMax = 1000
+
+// timestamp formats times in run records and task statuses
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// runIDUnsafe matches characters not allowed in a run ID
+var runIDUnsafe = regexp.MustCompile(`[^0-9A-Za-z_-]+`)
+
+// newRunID returns a unique, URL-safe ID such as aapl-20261016T120000Z-1a2b3c4d
+func newRunID(taskID string, now time.Time) string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return runIDUnsafe.ReplaceAllString(taskID, "_") + "-" + now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
+
+// runMetrics picks the numeric training metrics out of a result, e.g. the
+// "metrics" object returned by the training pipeline
+func runMetrics(data map[string]interface{}) map[string]float64 {
+	metrics := make(map[string]float64)
+	collect := func(m map[string]interface{}) {
+		for k, v := range m {
+			if f, ok := v.(float64); ok && !math.IsNaN(f) && !math.IsInf(f, 0) {
+				metrics[k] = f
+			}
+		}
+	}
+	collect(data)
+	if nested, ok := data["metrics"].(map[string]interface{}); ok {
+		collect(nested)
+	}
+	if len(metrics) == 0 {
+		return nil
+	}
+	return metrics
+}
+
+// memoryRunStore is a history local to this process, newest runs kept
+type memoryRunStore struct {
+	mu    sync.Mutex
+	max   int
+	runs  map[string]*Run
+	order []string // run IDs, oldest first
+}
+
+func (s *memoryRunStore) Save(run *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *run
+	if _, ok := s.runs[run.RunID]; !ok {
+		s.order = append(s.order, run.RunID)
+	}
+	s.runs[run.RunID] = &copied
+
+	for len(s.order) > s.max {
+		delete(s.runs, s.order[0])
+		s.order = s.order[1:]
+	}
+	return nil
+}
+
+func (s *memoryRunStore) Get(runID string) (*Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.runs[runID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *run
+	return &copied, nil
+}
+
+func (s *memoryRunStore) List(filter RunFilter) ([]*Run, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []*Run
+	for i := len(s.order) - 1; i >= 0; i-- {
+		run := s.runs[s.order[i]]
+		if filter.matches(run) && filter.inRange(run.created()) {
+			copied := *run
+			matched = append(matched, &copied)
+		}
+	}
+	return filter.page(matched), len(matched), nil
+}
+
+// redisRunStore keeps each run under its own key, expiring after the
+// retention period, and indexes run IDs by creation time in a sorted set
+type redisRunStore struct {
+	redis     *redisclient.Client
+	retention time.Duration
+}
+
+func (s *redisRunStore) Save(run *Run) error {
+	ctx := context.Background()
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	if err := s.redis.Set(ctx, redisclient.TaskRunKey(run.RunID), string(data), s.retention); err != nil {
+		return err
+	}
+	if err := s.redis.ZAdd(ctx, redisclient.TaskRunsKey, float64(run.created().UnixMilli()), run.RunID); err != nil {
+		return err
+	}
+
+	// Drop index entries whose records have expired
+	cutoff := time.Now().Add(-s.retention).UnixMilli()
+	return s.redis.ZRemRangeByScore(ctx, redisclient.TaskRunsKey, math.Inf(-1), float64(cutoff))
+}
+
+func (s *redisRunStore) Get(runID string) (*Run, error) {
+	val, err := s.redis.Get(context.Background(), redisclient.TaskRunKey(runID))
+	if err != nil {
+		return nil, nil
+	}
+
+	var run Run
+	if err := json.Unmarshal([]byte(val), &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (s *redisRunStore) List(filter RunFilter) ([]*Run, int, error) {
+	min, max := math.Inf(-1), math.Inf(1)
+	if !filter.Since.IsZero() {
+		min = float64(filter.Since.UnixMilli())
+	}
+	if !filter.Until.IsZero() {
+		max = float64(filter.Until.UnixMilli())
+	}
+
+	ids, err := s.redis.ZRevRangeByScore(context.Background(), redisclient.TaskRunsKey, min, max)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var matched []*Run
+	for _, id := range ids {
+		run, err := s.Get(id)
+		if err != nil || run == nil {
+			continue
+		}
+		if filter.matches(run) {
+			matched = append(matched, run)
+		}
+	}
+	return filter.page(matched), len(matched), nil
+}
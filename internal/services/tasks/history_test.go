@@ -0,0 +1,64 @@
+package tasks
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestMemoryRunStore_ListFiltersAndPages(t *testing.T) {
+	s := &memoryRunStore{max: 3, runs: make(map[string]*Run)}
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, r := range []struct{ id, task, status string }{
+		{"r0", "aapl", "completed"}, // evicted by max
+		{"r1", "aapl", "failed"},
+		{"r2", "msft", "completed"},
+		{"r3", "aapl", "completed"},
+	} {
+		s.Save(&Run{RunID: r.id, TaskID: r.task, Status: r.status, CreatedAt: timestamp(base.Add(time.Duration(i) * time.Hour))})
+	}
+
+	if run, _ := s.Get("r0"); run != nil {
+		t.Error("Get(r0) found the oldest run, want it evicted")
+	}
+
+	runs, total, _ := s.List(RunFilter{TaskID: "aapl"})
+	if total != 2 || runs[0].RunID != "r3" || runs[1].RunID != "r1" {
+		t.Errorf("List(aapl) = %d runs starting %v, want [r3 r1]", total, runs)
+	}
+
+	runs, total, _ = s.List(RunFilter{Since: base.Add(2 * time.Hour), Limit: 1, Offset: 1})
+	if total != 2 || len(runs) != 1 || runs[0].RunID != "r2" {
+		t.Errorf("List(since 02:00, page 2 of 1) = %d total, %v; want r2 of 2", total, runs)
+	}
+
+	runs, total, _ = s.List(RunFilter{Status: "failed", Until: base.Add(time.Hour)})
+	if total != 1 || runs[0].RunID != "r1" {
+		t.Errorf("List(failed until 01:00) = %v, want [r1]", runs)
+	}
+}
+
+func TestRunMetrics(t *testing.T) {
+	got := runMetrics(map[string]interface{}{
+		"ticker":  "AAPL",
+		"run_id":  "abc",
+		"metrics": map[string]interface{}{"MSE": 0.01, "R2": 0.9, "note": "x"},
+	})
+	if len(got) != 2 || got["MSE"] != 0.01 || got["R2"] != 0.9 {
+		t.Errorf("runMetrics = %v, want MSE and R2", got)
+	}
+	if runMetrics(map[string]interface{}{"ticker": "AAPL"}) != nil {
+		t.Error("runMetrics without numbers should be nil")
+	}
+}
+
+func TestNewRunID_URLSafe(t *testing.T) {
+	id := newRunID("brk.b", time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+	if !regexp.MustCompile(`^[0-9A-Za-z_-]+$`).MatchString(id) {
+		t.Errorf("newRunID = %q, want only URL-safe characters", id)
+	}
+	if id[:len("brk_b-20261016T120000Z-")] != "brk_b-20261016T120000Z-" {
+		t.Errorf("newRunID = %q, want prefix brk_b-20261016T120000Z-", id)
+	}
+}
@@ -5,6 +5,15 @@ package tasks
 type ManagerInterface interface {
 	GetStatus(taskID string) *TaskStatus
 	IsRunning(taskID string) bool
-	StartTrainParent() (bool, error)
-	StartTrainChild(ticker string, chainFn func()) (bool, error)
+	StartTrainParent(priority Priority) (Outcome, error)
+	StartTrainChild(ticker string, priority Priority, steps []Step) (Outcome, error)
+	StartTrainBatch(spec BatchSpec) (*Batch, error)
+	GetBatch(batchID string) *Batch
+	Resume(runID string) (Outcome, error)
+	Cancel(taskID, cancelledBy string) bool
+	GetRun(runID string) *Run
+	ListRuns(filter RunFilter) ([]*Run, int, error)
+	ListDeadLetters() ([]*DeadLetter, error)
+	RetryDeadLetter(taskID string) (Outcome, error)
+	DeleteDeadLetter(taskID string) (bool, error)
 }
@@ -2,7 +2,8 @@ package tasks
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"sync"
 	"time"
@@ -15,74 +16,274 @@ import (
 
 // TaskStatus represents the status of a background task
 type TaskStatus struct {
-	Status      string                 `json:"status"` // running, completed, failed
-	StartTime   string                 `json:"start_time,omitempty"`
-	CompletedAt string                 `json:"completed_at,omitempty"`
-	FailedAt    string                 `json:"failed_at,omitempty"`
-	Result      map[string]interface{} `json:"result,omitempty"`
-	Error       string                 `json:"error,omitempty"`
+	Status        string                 `json:"status"` // queued, running, completed, failed, retrying, cancelled, interrupted
+	RunID         string                 `json:"run_id,omitempty"`
+	Owner         string                 `json:"owner,omitempty"` // instance running the task
+	QueuedAt      string                 `json:"queued_at,omitempty"`
+	Priority      string                 `json:"priority,omitempty"`
+	QueuePosition int                    `json:"queue_position,omitempty"`
+	StartTime     string                 `json:"start_time,omitempty"`
+	CompletedAt   string                 `json:"completed_at,omitempty"`
+	FailedAt      string                 `json:"failed_at,omitempty"`
+	CancelledAt   string                 `json:"cancelled_at,omitempty"`
+	CancelledBy   string                 `json:"cancelled_by,omitempty"`
+	InterruptedAt string                 `json:"interrupted_at,omitempty"`
+	Result        map[string]interface{} `json:"result,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+	Progress      *python.Progress       `json:"progress,omitempty"`
+	ExecutionID   string                 `json:"execution_id,omitempty"`
+	Pipeline      []Step                 `json:"pipeline,omitempty"`
+	Attempt       int                    `json:"attempt,omitempty"`
+	MaxAttempts   int                    `json:"max_attempts,omitempty"`
+	NextAttemptAt string                 `json:"next_attempt_at,omitempty"`
+	DeadLettered  bool                   `json:"dead_lettered,omitempty"`
 }
 
+// Outcome reports what StartTrainParent or StartTrainChild did
+type Outcome int
+
+const (
+	// OutcomeStarted means the task took a free worker and is running
+	OutcomeStarted Outcome = iota
+	// OutcomeQueued means every worker was busy and the task is queued
+	OutcomeQueued
+	// OutcomeActive means the task was already running or queued
+	OutcomeActive
+)
+
+// queuePollInterval is how often the queue is checked for jobs that a free
+// slot could run, e.g. ones left in Redis by a restarted instance
+const queuePollInterval = 5 * time.Second
+
+// queuedTTL bounds how long a "queued" status outlives a lost job
+const queuedTTL = 24 * time.Hour
+
 // Manager manages background training tasks
 type Manager struct {
-	runner     *python.Runner
+	runner     python.RunnerInterface
 	redis      *redisclient.Client
 	metrics    *metrics.Metrics
 	maxWorkers int
 	sem        chan struct{}
 	mu         sync.Mutex
+	active     map[string]*activeTask
+
+	queue      queue
+	statuses   statusStore
+	runs       runStore
+	batches    batchStore
+	retries    retryStore
+	dead       deadLetterStore
+	dispatchMu sync.Mutex // serializes slot checks with queue pops
+	stop       chan struct{}
+	closeOnce  sync.Once
+
+	actions            map[string]Action // pipeline step actions, by name
+	stepRetryDelay     time.Duration
+	parentPollInterval time.Duration
+
+	maxAttempts  int
+	retryBackoff time.Duration
+
+	listenersMu sync.RWMutex
+	listeners   []func(Event)
+
+	instanceID        string
+	orphanAction      string
+	reconcileInterval time.Duration
+	lockTTL           time.Duration
+}
+
+// activeTask tracks a task running on this instance so it can be cancelled
+type activeTask struct {
+	ctx     context.Context
+	job     Job
+	started time.Time
+	cancel  context.CancelFunc
+	lock    *redisclient.Lock // lease on the task; nil without Redis
+	release func()            // frees the lease and any semaphore slot; safe to call more than once
+	done    chan struct{}     // closed by release
+	nested  bool              // run by a child pipeline's train-parent step, which retries it
+
+	mu       sync.Mutex // guards job.Steps and progress
+	progress *python.Progress
+}
+
+// steps returns a copy of the task's pipeline
+func (t *activeTask) steps() []Step {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Step(nil), t.job.Steps...)
+}
+
+// step returns a copy of one step of the task's pipeline
+func (t *activeTask) step(i int) Step {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.job.Steps[i]
+}
+
+// inputs returns the results of the steps that step depends on. ok is
+// false unless all of them have completed.
+func (t *activeTask) inputs(step Step) (inputs map[string]*python.Result, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	inputs = make(map[string]*python.Result, len(step.DependsOn))
+	for _, dep := range step.DependsOn {
+		for _, s := range t.job.Steps {
+			if s.Name == dep && s.Status == StepCompleted {
+				inputs[dep] = &python.Result{Data: s.Result, ExecutionID: s.ExecutionID}
+			}
+		}
+		if inputs[dep] == nil {
+			return nil, false
+		}
+	}
+	return inputs, true
 }
 
 // NewManager creates a new task manager
-func NewManager(cfg *config.Config, runner *python.Runner, redis *redisclient.Client, m *metrics.Metrics) *Manager {
-	return &Manager{
+func NewManager(cfg *config.Config, runner python.RunnerInterface, redis *redisclient.Client, m *metrics.Metrics) *Manager {
+	manager := &Manager{
 		runner:     runner,
 		redis:      redis,
 		metrics:    m,
 		maxWorkers: cfg.MaxWorkers,
 		sem:        make(chan struct{}, cfg.MaxWorkers),
+		active:     make(map[string]*activeTask),
+		queue:      newQueue(redis),
+		statuses:   newStatusStore(redis, cfg.TaskStateDir),
+		runs:       newRunStore(redis, time.Duration(cfg.TaskHistoryDays)*24*time.Hour),
+		batches:    newBatchStore(redis, time.Duration(cfg.TaskHistoryDays)*24*time.Hour),
+		retries:    newRetryStore(redis),
+		dead:       newDeadLetterStore(redis),
+		stop:       make(chan struct{}),
+
+		stepRetryDelay:     stepRetryDelay,
+		parentPollInterval: parentPollInterval,
+
+		maxAttempts:  max(cfg.TaskMaxAttempts, 1),
+		retryBackoff: time.Duration(cfg.TaskRetryBackoff) * time.Second,
+
+		instanceID:        cfg.InstanceID,
+		orphanAction:      cfg.OrphanAction,
+		reconcileInterval: time.Duration(cfg.ReconcileInterval) * time.Second,
+		lockTTL:           time.Duration(cfg.TaskLockTTL) * time.Second,
 	}
+	manager.actions = manager.builtinActions()
+	return manager
 }
 
-// GetStatus retrieves the status of a task from Redis
-func (m *Manager) GetStatus(taskID string) *TaskStatus {
-	if m.redis == nil {
-		return nil
+// Start reconciles tasks orphaned by this instance's previous run, then
+// begins heartbeating and polling the queue so jobs left over from a
+// previous run, or queued by another instance, start as soon as a worker
+// here is free. Failed tasks are resubmitted from the same loop once their
+// retry is due.
+func (m *Manager) Start() {
+	m.heartbeat()
+	m.reconcile()
+	m.updateDeadLetterMetric()
+	if m.redis != nil {
+		go m.livenessLoop()
+		m.listenForCancels()
 	}
 
-	ctx := context.Background()
-	key := redisclient.TaskKey(taskID)
+	go func() {
+		ticker := time.NewTicker(queuePollInterval)
+		defer ticker.Stop()
+		for {
+			m.promoteRetries()
+			m.dispatch()
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
 
-	val, err := m.redis.Get(ctx, key)
-	if err != nil {
-		return nil
+// Close stops polling the queue and heartbeating, so other instances can
+// reconcile this one's tasks at once. Queued jobs stay in Redis.
+func (m *Manager) Close() {
+	m.closeOnce.Do(func() {
+		close(m.stop)
+		if m.redis != nil {
+			m.redis.Del(context.Background(), redisclient.InstanceKey(m.instanceID))
+		}
+	})
+}
+
+// GetStatus retrieves the status of a task. Queued tasks include
+// their current position in the queue.
+func (m *Manager) GetStatus(taskID string) *TaskStatus {
+	status := m.loadStatus(taskID)
+
+	if status == nil || status.Status == "queued" {
+		if position := m.queue.Position(taskID); position > 0 {
+			if status == nil {
+				status = &TaskStatus{Status: "queued"}
+			}
+			status.QueuePosition = position
+		}
 	}
 
-	var status TaskStatus
-	if err := json.Unmarshal([]byte(val), &status); err != nil {
+	return status
+}
+
+// loadStatus reads a task's saved status
+func (m *Manager) loadStatus(taskID string) *TaskStatus {
+	status, err := m.statuses.Get(taskID)
+	if err != nil {
+		log.Printf("Failed to read task status for %s: %v", taskID, err)
 		return nil
 	}
-
-	return &status
+	return status
 }
 
-// saveStatus saves task status to Redis
+// saveStatus saves a task's status
 func (m *Manager) saveStatus(taskID string, status TaskStatus, ttl time.Duration) {
-	if m.redis == nil {
-		return
+	if err := m.statuses.Save(taskID, status, ttl); err != nil {
+		log.Printf("Failed to save task status: %v", err)
 	}
+}
 
-	ctx := context.Background()
-	key := redisclient.TaskKey(taskID)
-
-	data, err := json.Marshal(status)
+// GetRun returns one run from the history, or nil if it is unknown or expired
+func (m *Manager) GetRun(runID string) *Run {
+	run, err := m.runs.Get(runID)
 	if err != nil {
-		log.Printf("Failed to marshal task status: %v", err)
-		return
+		log.Printf("Failed to read run %s: %v", runID, err)
+		return nil
 	}
+	return run
+}
 
-	if err := m.redis.Set(ctx, key, string(data), ttl); err != nil {
-		log.Printf("Failed to save task status: %v", err)
+// ListRuns returns the page of runs matching filter, newest first, and the
+// total number of matches
+func (m *Manager) ListRuns(filter RunFilter) ([]*Run, int, error) {
+	return m.runs.List(filter)
+}
+
+// updateRun applies update to a job's run record, creating it if needed
+func (m *Manager) updateRun(job Job, update func(*Run)) {
+	run, err := m.runs.Get(job.RunID)
+	if err != nil || run == nil {
+		run = &Run{
+			RunID:       job.RunID,
+			TaskID:      job.TaskID,
+			Kind:        job.Kind,
+			Ticker:      job.Ticker,
+			Priority:    job.Priority.String(),
+			CreatedAt:   timestamp(job.QueuedAt),
+			ResumedFrom: job.ResumedFrom,
+			Pipeline:    job.Steps,
+			Attempt:     job.attempt(),
+		}
+	}
+	update(run)
+	if err := m.runs.Save(run); err != nil {
+		log.Printf("Failed to save run %s: %v", run.RunID, err)
 	}
 }
 
@@ -92,157 +293,591 @@ func (m *Manager) IsRunning(taskID string) bool {
 	return status != nil && status.Status == "running"
 }
 
-// StartTrainParent starts parent model training in the background
-func (m *Manager) StartTrainParent() (bool, error) {
-	taskID := "parent_training"
+// progressRecorder returns a callback that stores the latest progress event
+// of a running task so /status can report percent complete and metrics
+func (m *Manager) progressRecorder(task *activeTask) python.ProgressFunc {
+	return func(p python.Progress) {
+		task.mu.Lock()
+		task.progress = &p
+		task.mu.Unlock()
+		m.saveRunning(task)
+
+		if m.metrics != nil && task.ctx.Err() == nil {
+			m.metrics.TrainingProgress.WithLabelValues(task.job.TaskID).Set(p.Percent)
+		}
+	}
+}
 
-	// Check if already running
-	if m.IsRunning(taskID) {
-		return false, nil
+// saveRunning publishes a running task's status: its latest progress and
+// the state of each pipeline step
+func (m *Manager) saveRunning(task *activeTask) {
+	if task.ctx.Err() != nil {
+		return // don't overwrite a cancelled status
 	}
 
-	// Acquire semaphore slot
-	select {
-	case m.sem <- struct{}{}:
-		// Got a slot
-	default:
-		return false, nil // All workers busy
+	m.saveStatus(task.job.TaskID, m.runningStatus(task), 2*time.Hour)
+}
+
+// runningStatus returns the status of a running task
+func (m *Manager) runningStatus(task *activeTask) TaskStatus {
+	task.mu.Lock()
+	defer task.mu.Unlock()
+
+	return TaskStatus{
+		Status:      "running",
+		RunID:       task.job.RunID,
+		Owner:       m.instanceID,
+		StartTime:   timestamp(task.started),
+		Progress:    task.progress,
+		Pipeline:    stepsView(task.job.Steps),
+		Attempt:     task.job.attempt(),
+		MaxAttempts: m.maxAttempts,
 	}
+}
 
-	// Set running status
-	m.saveStatus(taskID, TaskStatus{
-		Status:    "running",
-		StartTime: time.Now().Format("2006-01-02 15:04:05"),
-	}, 2*time.Hour)
+// track registers a task as running on this instance. The caller must
+// already hold, with Redis, the task's lease and, if slot is set, a
+// semaphore slot that the task frees when it ends.
+func (m *Manager) track(job Job, started time.Time, lock *redisclient.Lock, slot bool) *activeTask {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	task := &activeTask{
+		ctx:     ctx,
+		job:     job,
+		started: started,
+		cancel:  cancel,
+		lock:    lock,
+		done:    done,
+		release: sync.OnceFunc(func() {
+			close(done)
+			if lock != nil {
+				if err := lock.Release(context.Background()); err != nil {
+					log.Printf("Failed to release lease on %s: %v", job.TaskID, err)
+				}
+			}
+			if slot {
+				<-m.sem
+			}
+		}),
+	}
 
-	if m.metrics != nil {
-		m.metrics.TrainingStatus.WithLabelValues(taskID).Set(1)
+	m.mu.Lock()
+	m.active[job.TaskID] = task
+	m.mu.Unlock()
+
+	return task
+}
+
+// forget removes a task from the active map, unless a newer run of the
+// same task has already replaced it
+func (m *Manager) forget(task *activeTask) {
+	m.mu.Lock()
+	if m.active[task.job.TaskID] == task {
+		delete(m.active, task.job.TaskID)
 	}
+	m.mu.Unlock()
+}
 
-	// Run in background
-	go func() {
-		defer func() { <-m.sem }()
-		m.runTrainParent(taskID)
-	}()
+// untrack removes a finished task, frees its lease and semaphore slot and
+// starts the next queued job
+func (m *Manager) untrack(task *activeTask) {
+	m.forget(task)
+	task.cancel()
+	task.release()
+	m.dispatch()
+}
 
-	return true, nil
+// runsLocally reports whether this instance is running the given run
+func (m *Manager) runsLocally(taskID, runID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task := m.active[taskID]
+	return task != nil && (runID == "" || task.job.RunID == runID)
 }
 
-func (m *Manager) runTrainParent(taskID string) {
-	start := time.Now()
-	ctx := context.Background()
+// lockOwner returns who holds a task's lease, or "" if nobody does
+func (m *Manager) lockOwner(taskID string) string {
+	if m.redis == nil {
+		return ""
+	}
+	owner, err := m.redis.LockOwner(context.Background(), redisclient.TaskLockKey(taskID))
+	if err != nil {
+		log.Printf("Failed to read lease on %s: %v", taskID, err)
+	}
+	return owner
+}
 
-	result, err := m.runner.TrainParent(ctx)
+// isActive reports whether a task is running here or elsewhere, or waiting
+// in the queue or for its next attempt
+func (m *Manager) isActive(taskID string) bool {
+	return m.runsLocally(taskID, "") || m.lockOwner(taskID) != "" ||
+		m.IsRunning(taskID) || m.queue.Position(taskID) > 0 || m.retries.Has(taskID)
+}
 
+// acquire takes a job's lease so no other instance runs the same task
+// meanwhile. ok is false if another process holds it. Without Redis the
+// active map is the only guard needed; if Redis fails, the job runs
+// unleased rather than not at all.
+func (m *Manager) acquire(job Job) (lock *redisclient.Lock, ok bool) {
+	if m.redis == nil {
+		return nil, true
+	}
+	lock, err := m.redis.AcquireLock(context.Background(), redisclient.TaskLockKey(job.TaskID), m.instanceID+"/"+job.RunID, m.lockTTL)
 	if err != nil {
-		m.saveStatus(taskID, TaskStatus{
-			Status:   "failed",
-			Error:    err.Error(),
-			FailedAt: time.Now().Format("2006-01-02 15:04:05"),
-		}, time.Hour)
+		log.Printf("Failed to take lease on %s, running without it: %v", job.TaskID, err)
+		return nil, true
+	}
+	return lock, lock != nil
+}
 
-		if m.metrics != nil {
-			m.metrics.TrainingStatus.WithLabelValues(taskID).Set(0)
+// keepLease renews a running task's lease until the task is untracked,
+// which a cancelled task only is once its process has exited. If the
+// lease is lost, e.g. Redis was unreachable for longer than its TTL,
+// another instance may already be running the task, so this run stops.
+func (m *Manager) keepLease(task *activeTask) {
+	ticker := time.NewTicker(m.lockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-task.done:
+			return
+		case <-ticker.C:
 		}
-		log.Printf("Training task %s failed: %v", taskID, err)
+
+		// A cancel request whose broadcast was missed is found here
+		m.checkCancelRequest(task)
+
+		held, err := task.lock.Renew(context.Background())
+		if err != nil {
+			log.Printf("Failed to renew lease on %s: %v", task.job.TaskID, err)
+			continue
+		}
+		if held {
+			continue
+		}
+
+		log.Printf("Training task %s lost its lease; stopping this run", task.job.TaskID)
+		m.updateRun(task.job, func(run *Run) {
+			run.Status = "interrupted"
+			run.Error = "lost the task lease"
+			run.FinishedAt = timestamp(time.Now())
+		})
+		task.cancel()
 		return
 	}
+}
 
-	duration := time.Since(start)
-	m.saveStatus(taskID, TaskStatus{
-		Status:      "completed",
-		Result:      result.Data,
-		CompletedAt: time.Now().Format("2006-01-02 15:04:05"),
-	}, time.Hour)
+// ErrInvalidPipeline is returned when a job's steps do not form a valid
+// pipeline
+var ErrInvalidPipeline = errors.New("invalid pipeline")
 
-	if m.metrics != nil {
-		m.metrics.TrainingStatus.WithLabelValues(taskID).Set(2)
-		m.metrics.TrainingDuration.WithLabelValues(taskID).Observe(duration.Seconds())
+// defaultSteps returns the pipeline of a job submitted without one, e.g.
+// one queued before jobs had pipelines
+func defaultSteps(kind string) []Step {
+	if kind == JobTrainParent {
+		return ParentPipeline()
+	}
+	return ChildPipeline(false)
+}
 
-		// Update MSE if available
-		if result.Data != nil {
-			if mse, ok := result.Data["mse"].(float64); ok {
-				m.metrics.TrainingMSE.Set(mse)
+// submit starts a job on a free worker, or queues it behind the jobs
+// already waiting when there is none
+func (m *Manager) submit(job Job) (Outcome, error) {
+	outcome, _, err := m.submitRun(job)
+	return outcome, err
+}
+
+// submitRun is submit that also returns the ID of the run that the task now
+// has: the job's new run, or the one already running or queued
+func (m *Manager) submitRun(job Job) (Outcome, string, error) {
+	if len(job.Steps) == 0 {
+		job.Steps = defaultSteps(job.Kind)
+	}
+	steps, err := m.validatePipeline(job.Steps)
+	if err != nil {
+		return OutcomeStarted, "", fmt.Errorf("%w: %v", ErrInvalidPipeline, err)
+	}
+	job.Steps = steps
+
+	m.dispatchMu.Lock()
+	defer m.dispatchMu.Unlock()
+
+	if m.isActive(job.TaskID) {
+		return OutcomeActive, m.activeRunID(job.TaskID), nil
+	}
+
+	now := time.Now()
+	job.RunID = newRunID(job.TaskID, now)
+	job.QueuedAt = now
+
+	// Only jump straight to a worker when nobody is waiting for one
+	if m.queue.Len() == 0 {
+		select {
+		case m.sem <- struct{}{}:
+			if m.launch(job) {
+				return OutcomeStarted, job.RunID, nil
 			}
+			<-m.sem
+			return OutcomeActive, m.activeRunID(job.TaskID), nil
+		default:
 		}
 	}
 
-	log.Printf("Training task %s completed in %v", taskID, duration)
+	if err := m.queue.Push(job); err != nil {
+		return OutcomeQueued, "", fmt.Errorf("failed to queue %s: %w", job.TaskID, err)
+	}
+	m.saveStatus(job.TaskID, TaskStatus{
+		Status:      "queued",
+		RunID:       job.RunID,
+		QueuedAt:    timestamp(job.QueuedAt),
+		Priority:    job.Priority.String(),
+		Pipeline:    stepsView(job.Steps),
+		Attempt:     job.attempt(),
+		MaxAttempts: m.maxAttempts,
+	}, queuedTTL)
+	m.updateRun(job, func(run *Run) { run.Status = "queued" })
+	log.Printf("Training task %s queued at %s priority (position %d)", job.TaskID, job.Priority, m.queue.Position(job.TaskID))
+
+	m.dispatchLocked()
+	m.updateQueueMetric()
+
+	if m.queue.Position(job.TaskID) == 0 {
+		return OutcomeStarted, job.RunID, nil
+	}
+	return OutcomeQueued, job.RunID, nil
+}
+
+// dispatch starts queued jobs while there are free worker slots
+func (m *Manager) dispatch() {
+	m.dispatchMu.Lock()
+	defer m.dispatchMu.Unlock()
+
+	m.dispatchLocked()
+	m.updateQueueMetric()
 }
 
-// StartTrainChild starts child model training in the background
-func (m *Manager) StartTrainChild(ticker string, chainFn func()) (bool, error) {
-	taskID := ticker
+// dispatchLocked is dispatch for callers already holding dispatchMu
+func (m *Manager) dispatchLocked() {
+	for {
+		select {
+		case m.sem <- struct{}{}:
+		default:
+			return
+		}
+
+		job, err := m.queue.Pop()
+		if err != nil || job == nil {
+			<-m.sem
+			if err != nil {
+				log.Printf("Failed to read training queue: %v", err)
+			}
+			return
+		}
 
-	// Check if already running
-	if m.IsRunning(taskID) {
-		return false, nil
+		if len(job.Steps) == 0 {
+			job.Steps = defaultSteps(job.Kind)
+		}
+		if !m.launch(*job) {
+			<-m.sem
+			m.updateRun(*job, func(run *Run) {
+				run.Status = "cancelled"
+				run.CancelledBy = "deduplicated"
+				run.Error = "already running on another instance"
+				run.FinishedAt = timestamp(time.Now())
+			})
+			log.Printf("Queued training task %s dropped: already running on another instance", job.TaskID)
+			continue
+		}
+		log.Printf("Training task %s dequeued after %v", job.TaskID, time.Since(job.QueuedAt).Round(time.Second))
 	}
+}
 
-	// Acquire semaphore slot
-	select {
-	case m.sem <- struct{}{}:
-		// Got a slot
-	default:
-		return false, nil
+// launch takes the job's lease and runs it in the background. The caller
+// must already hold a semaphore slot, which is freed when the job
+// finishes. It returns false, leaving the slot to the caller, if another
+// instance holds the lease.
+func (m *Manager) launch(job Job) bool {
+	lock, ok := m.acquire(job)
+	if !ok {
+		return false
 	}
 
-	// Set running status
-	m.saveStatus(taskID, TaskStatus{
-		Status:    "running",
-		StartTime: time.Now().Format("2006-01-02 15:04:05"),
-	}, 2*time.Hour)
+	// Track before publishing the running status, so it is never seen
+	// owned by this instance without a live task behind it
+	task := m.track(job, time.Now(), lock, true)
+	m.begin(task)
+
+	// Run in background
+	go m.execute(task)
+	return true
+}
+
+// begin publishes that a tracked task is running and keeps its lease
+func (m *Manager) begin(task *activeTask) {
+	if task.lock != nil {
+		go m.keepLease(task)
+	}
+
+	m.saveRunning(task)
+	m.updateRun(task.job, func(run *Run) {
+		run.Status = "running"
+		run.StartedAt = timestamp(task.started)
+	})
 
 	if m.metrics != nil {
-		m.metrics.TrainingStatus.WithLabelValues(taskID).Set(1)
+		m.metrics.TrainingStatus.WithLabelValues(task.job.TaskID).Set(1)
 	}
+	m.emit(EventStarted, task.job, m.runningStatus(task))
+}
 
-	// Run in background
-	go func() {
-		defer func() { <-m.sem }()
-		m.runTrainChild(taskID, ticker, chainFn)
-	}()
+// execute runs a tracked task's pipeline, records the outcome unless the
+// task was cancelled, and untracks it
+func (m *Manager) execute(task *activeTask) (*python.Result, error) {
+	defer m.untrack(task)
+
+	ctx := python.WithProgress(task.ctx, m.progressRecorder(task))
+	result, err := m.runPipeline(ctx, task)
+
+	if task.ctx.Err() == context.Canceled {
+		log.Printf("Training task %s cancelled", task.job.TaskID)
+		return nil, task.ctx.Err()
+	}
+
+	m.finish(task, result, err)
+	return result, err
+}
+
+// updateQueueMetric publishes the current queue depth
+func (m *Manager) updateQueueMetric() {
+	if m.metrics != nil {
+		m.metrics.TrainingQueued.Set(float64(m.queue.Len()))
+	}
+}
+
+// Cancel stops a task: the Python process group is killed and the status
+// becomes "cancelled" at once; the lease and worker slot are freed once the
+// process has exited. A task running on another instance is cancelled by
+// that instance, which is asked to over Redis. A queued task is simply
+// removed from the queue, and a failed one waiting to be retried loses its
+// retry. It returns false if the task is neither running, queued nor
+// retrying.
+func (m *Manager) Cancel(taskID, cancelledBy string) bool {
+	m.mu.Lock()
+	task := m.active[taskID]
+	m.mu.Unlock()
+
+	if task == nil {
+		return m.cancelQueued(taskID, cancelledBy) || m.cancelRetry(taskID, cancelledBy) ||
+			m.cancelRemote(taskID, cancelledBy)
+	}
+	m.cancelTask(task, cancelledBy)
+	return true
+}
+
+// cancelTask stops a task running on this instance. The task stays tracked,
+// holding its lease and worker slot, until its process has exited and
+// execute untracks it, so no retry or new submission runs alongside it.
+func (m *Manager) cancelTask(task *activeTask, cancelledBy string) {
+	if task.ctx.Err() != nil {
+		return // already cancelled and on its way out
+	}
+	taskID := task.job.TaskID
+	now := time.Now()
+	steps := task.steps()
+	status := TaskStatus{
+		Status:      "cancelled",
+		RunID:       task.job.RunID,
+		StartTime:   timestamp(task.started),
+		CancelledAt: timestamp(now),
+		CancelledBy: cancelledBy,
+		Pipeline:    stepsView(steps),
+	}
+	m.saveStatus(taskID, status, time.Hour)
+	m.updateRun(task.job, func(run *Run) {
+		run.Status = "cancelled"
+		run.CancelledBy = cancelledBy
+		run.FinishedAt = timestamp(now)
+		run.DurationSeconds = now.Sub(task.started).Seconds()
+		run.Pipeline = steps
+	})
+
+	// Stop the run only after its status leaves "running"; execute frees
+	// the lease and slot when the process is gone
+	task.cancel()
+
+	if m.metrics != nil {
+		m.metrics.TrainingStatus.WithLabelValues(taskID).Set(0)
+	}
+
+	log.Printf("Training task %s cancelled by %s", taskID, cancelledBy)
+	m.emit(EventCancelled, task.job, status)
+}
+
+// cancelQueued removes a task from the queue before it starts
+func (m *Manager) cancelQueued(taskID, cancelledBy string) bool {
+	m.dispatchMu.Lock()
+	defer m.dispatchMu.Unlock()
+
+	job, err := m.queue.Remove(taskID)
+	if err != nil {
+		log.Printf("Failed to remove %s from training queue: %v", taskID, err)
+	}
+	if job == nil {
+		return false
+	}
+	m.updateQueueMetric()
+
+	now := time.Now()
+	status := TaskStatus{
+		Status:      "cancelled",
+		RunID:       job.RunID,
+		CancelledAt: timestamp(now),
+		CancelledBy: cancelledBy,
+		Pipeline:    stepsView(job.Steps),
+	}
+	m.saveStatus(taskID, status, time.Hour)
+	if job.RunID != "" {
+		m.updateRun(*job, func(run *Run) {
+			run.Status = "cancelled"
+			run.CancelledBy = cancelledBy
+			run.FinishedAt = timestamp(now)
+		})
+	}
 
-	return true, nil
+	log.Printf("Queued training task %s cancelled by %s", taskID, cancelledBy)
+	m.emit(EventCancelled, *job, status)
+	return true
 }
 
-func (m *Manager) runTrainChild(taskID, ticker string, chainFn func()) {
-	start := time.Now()
-	ctx := context.Background()
+// StartTrainParent starts parent model training in the background, or
+// queues it at the given priority when every worker is busy
+func (m *Manager) StartTrainParent(priority Priority) (Outcome, error) {
+	return m.submit(Job{
+		TaskID:   ParentTaskID,
+		Kind:     JobTrainParent,
+		Priority: priority,
+		Steps:    ParentPipeline(),
+	})
+}
+
+// StartTrainChild runs a child training pipeline in the background, or
+// queues it at the given priority when every worker is busy. Without
+// steps, the pipeline only trains the child model; see ChildPipeline.
+func (m *Manager) StartTrainChild(ticker string, priority Priority, steps []Step) (Outcome, error) {
+	return m.submit(Job{
+		TaskID:   ticker,
+		Kind:     JobTrainChild,
+		Ticker:   ticker,
+		Priority: priority,
+		Steps:    steps,
+	})
+}
+
+// Resume errors
+var (
+	ErrRunNotFound  = errors.New("run not found")
+	ErrNotResumable = errors.New("run cannot be resumed")
+)
+
+// Resume runs a failed, cancelled or interrupted run's pipeline again as a
+// new run, from the steps that did not complete, with a fresh set of
+// attempts. Like any job it queues when every worker is busy.
+func (m *Manager) Resume(runID string) (Outcome, error) {
+	run := m.GetRun(runID)
+	if run == nil {
+		return OutcomeStarted, ErrRunNotFound
+	}
+	switch run.Status {
+	case "failed", "cancelled", "interrupted":
+	default:
+		return OutcomeStarted, fmt.Errorf("%w: it is %s", ErrNotResumable, run.Status)
+	}
 
-	result, err := m.runner.TrainChild(ctx, ticker)
+	// Resuming by hand replaces a pending automatic retry of the task
+	if _, err := m.retries.Remove(run.TaskID); err != nil {
+		log.Printf("Failed to drop pending retry of %s: %v", run.TaskID, err)
+	}
+
+	job := m.jobFor(run.TaskID, run.RunID)
+	job.Steps = resumable(job.Steps)
+	job.ResumedFrom = run.RunID
+	return m.submit(job)
+}
+
+// finish records the outcome of a training run in its task status, its run
+// record and the training metrics
+func (m *Manager) finish(task *activeTask, result *python.Result, err error) {
+	job := task.job
+	taskID := job.TaskID
+	now := time.Now()
+	duration := now.Sub(task.started)
+	steps := task.steps()
 
 	if err != nil {
-		m.saveStatus(taskID, TaskStatus{
-			Status:   "failed",
-			Error:    err.Error(),
-			FailedAt: time.Now().Format("2006-01-02 15:04:05"),
-		}, time.Hour)
+		// A parent run nested in a child pipeline is retried by the child
+		status := TaskStatus{
+			Status:      "failed",
+			RunID:       job.RunID,
+			Error:       err.Error(),
+			FailedAt:    timestamp(now),
+			ExecutionID: executionID(nil, err),
+			Pipeline:    stepsView(steps),
+		}
+		ttl := time.Hour
+		if !task.nested {
+			status = m.retryOrBury(job, steps, err, now)
+		}
+		if status.Status == "retrying" {
+			ttl = queuedTTL
+		}
+		m.saveStatus(taskID, status, ttl)
+		m.updateRun(job, func(run *Run) {
+			run.Status = "failed"
+			run.Error = err.Error()
+			run.FinishedAt = timestamp(now)
+			run.DurationSeconds = duration.Seconds()
+			run.ExecutionID = executionID(nil, err)
+			run.Pipeline = steps
+		})
 
 		if m.metrics != nil {
 			m.metrics.TrainingStatus.WithLabelValues(taskID).Set(0)
 		}
 		log.Printf("Training task %s failed: %v", taskID, err)
+		m.emit(EventFailed, job, status)
 		return
 	}
 
-	// Run chain function if provided
-	if chainFn != nil {
-		log.Printf("Task %s: Running chained function...", taskID)
-		chainFn()
+	if result == nil {
+		result = &python.Result{}
 	}
-
-	duration := time.Since(start)
-	m.saveStatus(taskID, TaskStatus{
+	status := TaskStatus{
 		Status:      "completed",
+		RunID:       job.RunID,
 		Result:      result.Data,
-		CompletedAt: time.Now().Format("2006-01-02 15:04:05"),
-	}, time.Hour)
+		CompletedAt: timestamp(now),
+		ExecutionID: result.ExecutionID,
+		Pipeline:    stepsView(steps),
+	}
+	m.saveStatus(taskID, status, time.Hour)
+	m.updateRun(job, func(run *Run) {
+		run.Status = "completed"
+		run.Result = result.Data
+		run.Metrics = runMetrics(result.Data)
+		run.FinishedAt = timestamp(now)
+		run.DurationSeconds = duration.Seconds()
+		run.ExecutionID = result.ExecutionID
+		run.Pipeline = steps
+	})
 
 	if m.metrics != nil {
 		m.metrics.TrainingStatus.WithLabelValues(taskID).Set(2)
+		m.metrics.TrainingProgress.WithLabelValues(taskID).Set(100)
 		m.metrics.TrainingDuration.WithLabelValues(taskID).Observe(duration.Seconds())
 
+		// Update MSE if available
 		if result.Data != nil {
 			if mse, ok := result.Data["mse"].(float64); ok {
 				m.metrics.TrainingMSE.Set(mse)
@@ -251,4 +886,17 @@ func (m *Manager) runTrainChild(taskID, ticker string, chainFn func()) {
 	}
 
 	log.Printf("Training task %s completed in %v", taskID, duration)
+	m.emit(EventCompleted, job, status)
+}
+
+// executionID returns the archived execution behind a runner outcome
+func executionID(result *python.Result, err error) string {
+	if result != nil && result.ExecutionID != "" {
+		return result.ExecutionID
+	}
+	var cliErr *python.Error
+	if errors.As(err, &cliErr) {
+		return cliErr.ExecutionID
+	}
+	return ""
 }
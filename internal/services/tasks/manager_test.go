@@ -0,0 +1,240 @@
+package tasks
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
+)
+
+// blockingRunner is a python.RunnerInterface whose training blocks until
+// its context is cancelled and, if exit is set, then until exit is closed,
+// like a process finishing its SIGTERM grace period.
+type blockingRunner struct {
+	started chan string
+	exit    chan struct{}
+}
+
+func newBlockingRunner() *blockingRunner {
+	return &blockingRunner{started: make(chan string, 8)}
+}
+
+func (r *blockingRunner) block(ctx context.Context, name string) (*python.Result, error) {
+	r.started <- name
+	<-ctx.Done()
+	if r.exit != nil {
+		<-r.exit
+	}
+	return nil, ctx.Err()
+}
+
+func (r *blockingRunner) TrainParent(ctx context.Context) (*python.Result, error) {
+	return r.block(ctx, "parent")
+}
+func (r *blockingRunner) TrainChild(ctx context.Context, ticker string) (*python.Result, error) {
+	return r.block(ctx, ticker)
+}
+func (r *blockingRunner) PredictParent(context.Context) (*python.Result, error) { return nil, nil }
+func (r *blockingRunner) PredictChild(context.Context, string) (*python.Result, error) {
+	return nil, nil
+}
+func (r *blockingRunner) Analyze(context.Context, string, string) (*python.Result, error) {
+	return nil, nil
+}
+func (r *blockingRunner) MonitorParent(context.Context) (*python.Result, error) { return nil, nil }
+func (r *blockingRunner) MonitorTicker(context.Context, string) (*python.Result, error) {
+	return nil, nil
+}
+
+func testManager(t *testing.T, runner python.RunnerInterface, workers int) *Manager {
+	t.Helper()
+	cfg := config.Load()
+	cfg.MaxWorkers = workers
+	return NewManager(cfg, runner, nil, nil)
+}
+
+// nextStarted waits for the runner to start a training task
+func nextStarted(t *testing.T, runner *blockingRunner) string {
+	t.Helper()
+	select {
+	case name := <-runner.started:
+		return name
+	case <-time.After(time.Second):
+		t.Fatal("no training task started")
+		return ""
+	}
+}
+
+// waitIdle waits for every cancelled task to exit and free its slot
+func waitIdle(t *testing.T, m *Manager) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for len(m.sem) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("semaphore still in use = %d", len(m.sem))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCancel_FreesSlotOnceProcessExits(t *testing.T) {
+	runner := newBlockingRunner()
+	runner.exit = make(chan struct{})
+	m := testManager(t, runner, 1)
+
+	if outcome, _ := m.StartTrainChild("aapl", PriorityNormal, nil); outcome != OutcomeStarted {
+		t.Fatalf("StartTrainChild(aapl) = %v, want OutcomeStarted", outcome)
+	}
+	<-runner.started
+
+	if !m.Cancel("aapl", "tester") {
+		t.Fatal("Cancel(aapl) = false, want true")
+	}
+	if status := m.GetStatus("aapl"); status == nil || status.Status != "cancelled" {
+		t.Errorf("GetStatus(aapl) after Cancel = %+v, want cancelled", status)
+	}
+
+	// While the cancelled process is still exiting, its slot stays taken
+	// and the task cannot be started again
+	if len(m.sem) != 1 {
+		t.Errorf("semaphore in use while exiting = %d, want 1", len(m.sem))
+	}
+	if outcome, _ := m.StartTrainChild("aapl", PriorityNormal, nil); outcome != OutcomeActive {
+		t.Errorf("StartTrainChild(aapl) while exiting = %v, want OutcomeActive", outcome)
+	}
+	if outcome, _ := m.StartTrainChild("msft", PriorityNormal, nil); outcome != OutcomeQueued {
+		t.Fatalf("StartTrainChild(msft) while exiting = %v, want OutcomeQueued", outcome)
+	}
+
+	close(runner.exit)
+	if name := nextStarted(t, runner); name != "msft" {
+		t.Errorf("runner started %q, want msft", name)
+	}
+	m.Cancel("msft", "tester")
+}
+
+func TestCancelRequest_CancelsOnlyTheNamedRun(t *testing.T) {
+	runner := newBlockingRunner()
+	m := testManager(t, runner, 1)
+
+	m.StartTrainChild("aapl", PriorityNormal, nil)
+	<-runner.started
+	runID := m.GetStatus("aapl").RunID
+
+	m.handleCancelRequest(`{"task_id":"aapl","run_id":"an-earlier-run","cancelled_by":"tester"}`)
+	if status := m.GetStatus("aapl"); status.Status != "running" {
+		t.Fatalf("status after a request for another run = %q, want running", status.Status)
+	}
+
+	m.handleCancelRequest(`{"task_id":"aapl","run_id":"` + runID + `","cancelled_by":"tester"}`)
+	status := m.GetStatus("aapl")
+	if status.Status != "cancelled" || status.CancelledBy != "tester" {
+		t.Errorf("status after the request = %+v, want cancelled by tester", status)
+	}
+}
+
+func TestQueue_StartsWhenWorkerFrees(t *testing.T) {
+	runner := newBlockingRunner()
+	m := testManager(t, runner, 1)
+
+	m.StartTrainChild("aapl", PriorityNormal, nil)
+	<-runner.started
+
+	for _, job := range []struct {
+		ticker   string
+		priority Priority
+	}{
+		{"low", PriorityLow},
+		{"first", PriorityNormal},
+		{"second", PriorityNormal},
+		{"urgent", PriorityHigh},
+	} {
+		if outcome, _ := m.StartTrainChild(job.ticker, job.priority, nil); outcome != OutcomeQueued {
+			t.Fatalf("StartTrainChild(%s) with no free worker = %v, want OutcomeQueued", job.ticker, outcome)
+		}
+	}
+
+	if outcome, _ := m.StartTrainChild("first", PriorityHigh, nil); outcome != OutcomeActive {
+		t.Errorf("StartTrainChild(first) again = %v, want OutcomeActive", outcome)
+	}
+	if status := m.GetStatus("second"); status == nil || status.Status != "queued" || status.QueuePosition != 3 {
+		t.Errorf("GetStatus(second) = %+v, want queued at position 3", status)
+	}
+
+	// Each finished task hands its worker to the next job by priority, then FIFO
+	m.Cancel("aapl", "tester")
+	for _, want := range []string{"urgent", "first", "second", "low"} {
+		if name := nextStarted(t, runner); name != want {
+			t.Fatalf("runner started %q, want %q", name, want)
+		}
+		m.Cancel(want, "tester")
+	}
+}
+
+func TestCancel_QueuedTask(t *testing.T) {
+	runner := newBlockingRunner()
+	m := testManager(t, runner, 1)
+
+	m.StartTrainChild("aapl", PriorityNormal, nil)
+	<-runner.started
+	m.StartTrainChild("msft", PriorityNormal, nil)
+
+	if !m.Cancel("msft", "tester") {
+		t.Fatal("Cancel(queued msft) = false, want true")
+	}
+	if status := m.GetStatus("msft"); status == nil || status.Status != "cancelled" || status.QueuePosition != 0 {
+		t.Errorf("GetStatus(msft) after cancel = %+v, want cancelled, no longer queued", status)
+	}
+
+	m.Cancel("aapl", "tester")
+	select {
+	case name := <-runner.started:
+		t.Errorf("runner started %q after the queue was emptied", name)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestCancel_UnknownTask(t *testing.T) {
+	m := testManager(t, newBlockingRunner(), 1)
+	if m.Cancel("ghost", "tester") {
+		t.Error("Cancel(ghost) = true, want false")
+	}
+}
+
+func TestRuns_EachRunKeepsItsOwnRecord(t *testing.T) {
+	runner := newBlockingRunner()
+	m := testManager(t, runner, 1)
+
+	m.StartTrainChild("aapl", PriorityNormal, nil)
+	<-runner.started
+	m.Cancel("aapl", "tester")
+	waitIdle(t, m)
+
+	m.StartTrainChild("aapl", PriorityHigh, nil)
+	<-runner.started
+	defer m.Cancel("aapl", "tester")
+
+	runs, total, err := m.ListRuns(RunFilter{TaskID: "aapl"})
+	if err != nil || total != 2 {
+		t.Fatalf("ListRuns(aapl) = %d runs, %v; want 2", total, err)
+	}
+	latest, first := runs[0], runs[1]
+	if latest.RunID == first.RunID {
+		t.Fatalf("both runs share ID %q", latest.RunID)
+	}
+	if latest.Status != "running" || latest.Priority != "high" || latest.StartedAt == "" {
+		t.Errorf("latest run = %+v, want running at high priority", latest)
+	}
+	if first.Status != "cancelled" || first.CancelledBy != "tester" || first.FinishedAt == "" {
+		t.Errorf("first run = %+v, want cancelled by tester", first)
+	}
+	if _, err := time.Parse(time.RFC3339, first.CreatedAt); err != nil || !strings.HasSuffix(first.CreatedAt, "Z") {
+		t.Errorf("CreatedAt = %q, want RFC3339 UTC", first.CreatedAt)
+	}
+	if got := m.GetRun(first.RunID); got == nil || got.Status != "cancelled" {
+		t.Errorf("GetRun(%s) = %+v, want the cancelled run", first.RunID, got)
+	}
+}
@@ -0,0 +1,508 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
+)
+
+// ParentTaskID is the task that trains the shared parent model
+const ParentTaskID = "parent_training"
+
+// Step actions. Others can be added with RegisterAction.
+const (
+	ActionTrainParent   = "train-parent"
+	ActionTrainChild    = "train-child"
+	ActionPredictChild  = "predict-child"
+	ActionWarmCache     = "warm-cache"
+	ActionMonitorTicker = "monitor-ticker"
+)
+
+// followUpActions are the actions a child pipeline may run after training
+var followUpActions = map[string]bool{
+	ActionPredictChild:  true,
+	ActionWarmCache:     true,
+	ActionMonitorTicker: true,
+}
+
+// Step statuses
+const (
+	StepPending   = "pending"
+	StepRunning   = "running"
+	StepCompleted = "completed"
+	StepFailed    = "failed"
+	StepSkipped   = "skipped" // a step it depends on failed
+)
+
+// stepRetryDelay is the wait before a failed step's first retry; it doubles
+// with each further attempt
+const stepRetryDelay = 5 * time.Second
+
+// parentPollInterval is how often a pipeline waiting on a parent training
+// run elsewhere checks whether it has finished
+const parentPollInterval = 2 * time.Second
+
+// Step is one node of a pipeline: an action that runs once every step it
+// depends on has completed
+type Step struct {
+	Name      string   `json:"name"`
+	Action    string   `json:"action"`
+	DependsOn []string `json:"depends_on,omitempty"`
+	Retries   int      `json:"retries,omitempty"` // attempts after the first failure
+
+	Status      string                 `json:"status"`
+	Attempts    int                    `json:"attempts,omitempty"`
+	StartedAt   string                 `json:"started_at,omitempty"`
+	FinishedAt  string                 `json:"finished_at,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	ExecutionID string                 `json:"execution_id,omitempty"`
+	Result      map[string]interface{} `json:"result,omitempty"`
+}
+
+// Action performs a pipeline step for a ticker ("" for the parent model).
+// inputs holds the results of the steps it depends on, by step name.
+type Action func(ctx context.Context, ticker string, inputs map[string]*python.Result) (*python.Result, error)
+
+// ParentPipeline returns the steps of a parent training job
+func ParentPipeline() []Step {
+	return []Step{{Name: ActionTrainParent, Action: ActionTrainParent}}
+}
+
+// ChildPipeline returns the steps of a child training job: train-parent
+// first when trainParent is set, then train-child, then each follow-up
+// action in order, e.g. ActionPredictChild then ActionWarmCache. Every
+// step depends on the one before it.
+func ChildPipeline(trainParent bool, followUps ...string) []Step {
+	actions := []string{ActionTrainChild}
+	if trainParent {
+		actions = append([]string{ActionTrainParent}, actions...)
+	}
+	actions = append(actions, followUps...)
+
+	steps := make([]Step, 0, len(actions))
+	for i, action := range actions {
+		step := Step{Name: action, Action: action}
+		if i > 0 {
+			step.DependsOn = []string{actions[i-1]}
+		}
+		// Training already retries through its execution policy; the
+		// follow-ups are cheap enough to try again
+		if action != ActionTrainParent && action != ActionTrainChild {
+			step.Retries = 1
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// ValidateFollowUps checks the follow-up actions requested for a child
+// pipeline, e.g. a request's "then" list, against the actions allowed after
+// training
+func ValidateFollowUps(actions []string) error {
+	for _, action := range actions {
+		if !followUpActions[action] {
+			return fmt.Errorf("%w: %q is not a follow-up action; use %s, %s or %s",
+				ErrInvalidPipeline, action, ActionPredictChild, ActionWarmCache, ActionMonitorTicker)
+		}
+	}
+	return nil
+}
+
+// RegisterAction makes an action available to pipeline steps, replacing any
+// action of the same name. Register actions before Start, so queued jobs
+// that use them can run.
+func (m *Manager) RegisterAction(name string, action Action) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.actions[name] = action
+}
+
+// action returns a registered action, or nil
+func (m *Manager) action(name string) Action {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.actions[name]
+}
+
+// builtinActions returns the actions every manager can run
+func (m *Manager) builtinActions() map[string]Action {
+	return map[string]Action{
+		ActionTrainParent: func(ctx context.Context, _ string, _ map[string]*python.Result) (*python.Result, error) {
+			return m.runner.TrainParent(ctx)
+		},
+		ActionTrainChild: func(ctx context.Context, ticker string, _ map[string]*python.Result) (*python.Result, error) {
+			return m.runner.TrainChild(ctx, ticker)
+		},
+		ActionPredictChild: func(ctx context.Context, ticker string, _ map[string]*python.Result) (*python.Result, error) {
+			return m.runner.PredictChild(ctx, strings.ToUpper(ticker))
+		},
+		ActionMonitorTicker: func(ctx context.Context, ticker string, _ map[string]*python.Result) (*python.Result, error) {
+			return m.runner.MonitorTicker(ctx, strings.ToUpper(ticker))
+		},
+	}
+}
+
+// validatePipeline checks that steps form a graph of known actions and
+// returns them in an order that runs every step after its dependencies.
+// Steps keep their declared order where dependencies allow; a step without
+// a status is pending.
+func (m *Manager) validatePipeline(steps []Step) ([]Step, error) {
+	if len(steps) == 0 {
+		return nil, errors.New("pipeline has no steps")
+	}
+
+	byName := make(map[string]int, len(steps))
+	for i, step := range steps {
+		if step.Name == "" {
+			return nil, fmt.Errorf("step %d has no name", i+1)
+		}
+		if _, dup := byName[step.Name]; dup {
+			return nil, fmt.Errorf("duplicate step %q", step.Name)
+		}
+		if m.action(step.Action) == nil {
+			return nil, fmt.Errorf("step %q: unknown action %q", step.Name, step.Action)
+		}
+		if step.Retries < 0 {
+			return nil, fmt.Errorf("step %q: retries must not be negative", step.Name)
+		}
+		byName[step.Name] = i
+	}
+	for _, step := range steps {
+		for _, dep := range step.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				return nil, fmt.Errorf("step %q depends on unknown step %q", step.Name, dep)
+			}
+		}
+		// warm-cache caches whatever its inputs hold, so they must be
+		// predictions
+		if step.Action == ActionWarmCache {
+			if len(step.DependsOn) == 0 {
+				return nil, fmt.Errorf("step %q: %s must depend on a %s step", step.Name, ActionWarmCache, ActionPredictChild)
+			}
+			for _, dep := range step.DependsOn {
+				if steps[byName[dep]].Action != ActionPredictChild {
+					return nil, fmt.Errorf("step %q: %s must depend on %s steps only, not %q", step.Name, ActionWarmCache, ActionPredictChild, dep)
+				}
+			}
+		}
+	}
+
+	// Repeatedly take the first step whose dependencies are all placed
+	ordered := make([]Step, 0, len(steps))
+	placed := make(map[string]bool, len(steps))
+	for len(ordered) < len(steps) {
+		progressed := false
+		for _, step := range steps {
+			if placed[step.Name] || !dependenciesMet(step, placed) {
+				continue
+			}
+			copied := step
+			copied.DependsOn = append([]string(nil), step.DependsOn...)
+			if copied.Status == "" {
+				copied.Status = StepPending
+			}
+			ordered = append(ordered, copied)
+			placed[step.Name] = true
+			progressed = true
+			break
+		}
+		if !progressed {
+			return nil, errors.New("pipeline has a dependency cycle")
+		}
+	}
+	return ordered, nil
+}
+
+// dependenciesMet reports whether every dependency of step is in done
+func dependenciesMet(step Step, done map[string]bool) bool {
+	for _, dep := range step.DependsOn {
+		if !done[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// resumable returns a copy of a run's steps to run again: completed steps
+// keep their results and are not repeated, every other step starts afresh
+func resumable(steps []Step) []Step {
+	resumed := make([]Step, len(steps))
+	for i, step := range steps {
+		if step.Status != StepCompleted {
+			step = Step{Name: step.Name, Action: step.Action, DependsOn: step.DependsOn, Retries: step.Retries, Status: StepPending}
+		}
+		resumed[i] = step
+	}
+	return resumed
+}
+
+// stepsView returns steps for a task status, without their results, which
+// the status and run record already carry
+func stepsView(steps []Step) []Step {
+	view := make([]Step, len(steps))
+	for i, step := range steps {
+		step.Result = nil
+		view[i] = step
+	}
+	return view
+}
+
+// mainResult picks the result that stands for the whole pipeline: that of
+// the last completed training step, or else of the last completed step
+func mainResult(steps []Step) *python.Result {
+	var last, training *Step
+	for i := range steps {
+		step := &steps[i]
+		if step.Status != StepCompleted {
+			continue
+		}
+		last = step
+		if step.Action == ActionTrainParent || step.Action == ActionTrainChild {
+			training = step
+		}
+	}
+	if training != nil {
+		last = training
+	}
+	if last == nil {
+		return nil
+	}
+	return &python.Result{Data: last.Result, ExecutionID: last.ExecutionID}
+}
+
+// runPipeline runs a task's steps in order. Completed steps, e.g. of the
+// run being resumed, are not repeated. A failed step skips the steps that
+// depend on it; independent steps still run. It returns the pipeline's
+// main result and the first step error.
+func (m *Manager) runPipeline(ctx context.Context, task *activeTask) (*python.Result, error) {
+	var firstErr error
+
+	for i := range task.steps() {
+		step := task.step(i)
+		if step.Status == StepCompleted {
+			continue
+		}
+
+		inputs, ok := task.inputs(step)
+		if !ok {
+			m.setStep(task, i, func(s *Step) {
+				s.Status = StepSkipped
+				s.Error = "a step it depends on did not complete"
+			})
+			continue
+		}
+
+		if err := m.runStep(ctx, task, i, inputs); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("step %s: %w", step.Name, err)
+			}
+		}
+	}
+
+	return mainResult(task.steps()), firstErr
+}
+
+// runStep runs one step, retrying with backoff until it succeeds or has
+// used its retries
+func (m *Manager) runStep(ctx context.Context, task *activeTask, i int, inputs map[string]*python.Result) error {
+	step := task.step(i)
+	delay := m.stepRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		m.setStep(task, i, func(s *Step) {
+			s.Status = StepRunning
+			s.Attempts++
+			s.Error = ""
+			if s.StartedAt == "" {
+				s.StartedAt = timestamp(time.Now())
+			}
+		})
+
+		var result *python.Result
+		var err error
+		action := m.action(step.Action)
+		switch {
+		case step.Action == ActionTrainParent && task.job.TaskID != ParentTaskID:
+			result, err = m.trainParentFor(ctx, task)
+		case action == nil:
+			err = fmt.Errorf("unknown action %q", step.Action)
+		default:
+			result, err = action(ctx, task.job.Ticker, inputs)
+		}
+
+		if err == nil {
+			m.setStep(task, i, func(s *Step) {
+				s.Status = StepCompleted
+				s.FinishedAt = timestamp(time.Now())
+				if result != nil {
+					s.Result = result.Data
+					s.ExecutionID = result.ExecutionID
+				}
+			})
+			return nil
+		}
+
+		m.setStep(task, i, func(s *Step) {
+			s.Status = StepFailed
+			s.Error = err.Error()
+			s.FinishedAt = timestamp(time.Now())
+			s.ExecutionID = executionID(result, err)
+		})
+		if ctx.Err() != nil || attempt > step.Retries {
+			return err
+		}
+
+		log.Printf("Task %s: step %s failed (attempt %d of %d), retrying in %v: %v", task.job.TaskID, step.Name, attempt, step.Retries+1, delay, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// setStep updates a step of a running task and publishes the change
+func (m *Manager) setStep(task *activeTask, i int, update func(*Step)) {
+	task.mu.Lock()
+	update(&task.job.Steps[i])
+	task.mu.Unlock()
+
+	if task.ctx.Err() != nil {
+		return // the cancelled run's record is already final
+	}
+	m.saveRunning(task)
+	steps := task.steps()
+	m.updateRun(task.job, func(run *Run) { run.Pipeline = steps })
+}
+
+// trainParentFor runs the train-parent step of a child pipeline. The
+// parent model is shared, so the step joins a parent training already
+// running rather than starting another. A queued one is taken out of the
+// queue and run here, on the worker this pipeline already holds, so that
+// pipelines never wait on a job that waits for their worker.
+func (m *Manager) trainParentFor(ctx context.Context, child *activeTask) (*python.Result, error) {
+	// The parent may have finished training since this job was queued, e.g.
+	// the run a batch started ahead of its children. Run times are kept to
+	// the second.
+	if run := m.latestRun(ParentTaskID); run != nil && run.Status == "completed" && !run.finished().Before(child.job.QueuedAt.Truncate(time.Second)) {
+		return &python.Result{Data: run.Result, ExecutionID: run.ExecutionID}, nil
+	}
+
+	for {
+		parent, waitFor := m.claimParent(child)
+		if parent != nil {
+			return m.runNested(ctx, child, parent)
+		}
+
+		// Running elsewhere; wait for it to finish
+		for m.isActive(ParentTaskID) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(m.parentPollInterval):
+			}
+		}
+
+		run := m.GetRun(waitFor)
+		switch {
+		case run == nil:
+			// Lost track of it; try again
+		case run.Status == "completed":
+			return &python.Result{Data: run.Result, ExecutionID: run.ExecutionID}, nil
+		default:
+			return nil, fmt.Errorf("parent training %s %s: %s", run.RunID, run.Status, run.Error)
+		}
+	}
+}
+
+// claimParent returns the parent training job for a child pipeline to run
+// itself, taken out of the queue or newly created, with its lease held.
+// If the parent is running elsewhere it returns nil and that run's ID.
+func (m *Manager) claimParent(child *activeTask) (*activeTask, string) {
+	m.dispatchMu.Lock()
+	defer m.dispatchMu.Unlock()
+
+	job, err := m.queue.Remove(ParentTaskID)
+	if err != nil {
+		log.Printf("Failed to take %s from the training queue: %v", ParentTaskID, err)
+	}
+	if job != nil {
+		m.updateQueueMetric()
+	} else if m.isActive(ParentTaskID) {
+		return nil, m.activeRunID(ParentTaskID)
+	} else {
+		now := time.Now()
+		job = &Job{
+			TaskID:   ParentTaskID,
+			RunID:    newRunID(ParentTaskID, now),
+			Kind:     JobTrainParent,
+			Priority: child.job.Priority,
+			QueuedAt: now,
+		}
+	}
+	job.Steps = ParentPipeline()
+	if steps, err := m.validatePipeline(job.Steps); err == nil {
+		job.Steps = steps
+	}
+
+	lock, ok := m.acquire(*job)
+	if !ok {
+		return nil, m.activeRunID(ParentTaskID)
+	}
+	parent := m.track(*job, time.Now(), lock, false)
+	parent.nested = true
+	return parent, ""
+}
+
+// runNested runs a parent training job claimed by a child pipeline as a
+// task of its own, so its status, history and cancellation work as if it
+// had been started directly
+func (m *Manager) runNested(ctx context.Context, child, parent *activeTask) (*python.Result, error) {
+	log.Printf("Task %s: training the parent model as run %s", child.job.TaskID, parent.job.RunID)
+
+	// Cancelling the child pipeline cancels the parent run with it
+	stop := context.AfterFunc(ctx, func() {
+		m.mu.Lock()
+		tracked := m.active[ParentTaskID] == parent
+		m.mu.Unlock()
+		if tracked {
+			m.cancelTask(parent, "pipeline "+child.job.TaskID)
+		}
+	})
+	defer stop()
+
+	m.begin(parent)
+	return m.execute(parent)
+}
+
+// activeRunID returns the ID of a task's current run, or "" if unknown
+func (m *Manager) activeRunID(taskID string) string {
+	m.mu.Lock()
+	task := m.active[taskID]
+	m.mu.Unlock()
+	if task != nil {
+		return task.job.RunID
+	}
+	if status := m.GetStatus(taskID); status != nil {
+		return status.RunID
+	}
+	return ""
+}
+
+// latestRun returns a task's most recent run, or nil
+func (m *Manager) latestRun(taskID string) *Run {
+	runs, _, err := m.runs.List(RunFilter{TaskID: taskID, Limit: 1})
+	if err != nil || len(runs) == 0 {
+		return nil
+	}
+	return runs[0]
+}
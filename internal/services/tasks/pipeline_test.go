@@ -0,0 +1,246 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
+)
+
+// scriptedRunner is a python.RunnerInterface that counts calls. Parent
+// training waits for parentGate when it is set; prediction fails while
+// predictErr is set.
+type scriptedRunner struct {
+	mu         sync.Mutex
+	calls      map[string]int
+	parentGate chan struct{}
+	predictErr error
+}
+
+func newScriptedRunner() *scriptedRunner {
+	return &scriptedRunner{calls: make(map[string]int)}
+}
+
+func (r *scriptedRunner) count(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[name]++
+}
+
+func (r *scriptedRunner) called(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls[name]
+}
+
+func (r *scriptedRunner) TrainParent(ctx context.Context) (*python.Result, error) {
+	r.count("train-parent")
+	if r.parentGate != nil {
+		select {
+		case <-r.parentGate:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return &python.Result{Data: map[string]interface{}{"mse": 0.5}}, nil
+}
+func (r *scriptedRunner) TrainChild(_ context.Context, ticker string) (*python.Result, error) {
+	r.count("train-child:" + ticker)
+	return &python.Result{Data: map[string]interface{}{"mse": 0.25}}, nil
+}
+func (r *scriptedRunner) PredictChild(_ context.Context, ticker string) (*python.Result, error) {
+	r.count("predict-child:" + ticker)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.predictErr != nil {
+		return nil, r.predictErr
+	}
+	return &python.Result{Data: map[string]interface{}{"ticker": ticker}}, nil
+}
+func (r *scriptedRunner) PredictParent(context.Context) (*python.Result, error) { return nil, nil }
+func (r *scriptedRunner) Analyze(context.Context, string, string) (*python.Result, error) {
+	return nil, nil
+}
+func (r *scriptedRunner) MonitorParent(context.Context) (*python.Result, error) { return nil, nil }
+func (r *scriptedRunner) MonitorTicker(context.Context, string) (*python.Result, error) {
+	return nil, nil
+}
+
+// waitRun waits for a task's latest run to finish
+func waitRun(t *testing.T, m *Manager, taskID string) *Run {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if run := m.latestRun(taskID); run != nil {
+			switch run.Status {
+			case "completed", "failed", "cancelled", "interrupted":
+				return run
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("run of %s did not finish", taskID)
+	return nil
+}
+
+func TestValidatePipeline(t *testing.T) {
+	m := testManager(t, newScriptedRunner(), 1)
+
+	ordered, err := m.validatePipeline([]Step{
+		{Name: "predict", Action: ActionPredictChild, DependsOn: []string{"train"}},
+		{Name: "train", Action: ActionTrainChild},
+	})
+	if err != nil {
+		t.Fatalf("validatePipeline(valid) err = %v", err)
+	}
+	if ordered[0].Name != "train" || ordered[1].Name != "predict" || ordered[0].Status != StepPending {
+		t.Errorf("validatePipeline(valid) = %+v, want pending train before predict", ordered)
+	}
+
+	for name, steps := range map[string][]Step{
+		"empty":          nil,
+		"unknown action": {{Name: "a", Action: "deploy"}},
+		"unknown dep":    {{Name: "a", Action: ActionTrainChild, DependsOn: []string{"b"}}},
+		"duplicate":      {{Name: "a", Action: ActionTrainChild}, {Name: "a", Action: ActionPredictChild}},
+		"cycle": {
+			{Name: "a", Action: ActionTrainChild, DependsOn: []string{"b"}},
+			{Name: "b", Action: ActionPredictChild, DependsOn: []string{"a"}},
+		},
+	} {
+		if _, err := m.validatePipeline(steps); err == nil {
+			t.Errorf("validatePipeline(%s) err = nil, want error", name)
+		}
+	}
+
+	if _, err := m.StartTrainChild("aapl", PriorityNormal, []Step{{Name: "a", Action: "deploy"}}); !errors.Is(err, ErrInvalidPipeline) {
+		t.Errorf("StartTrainChild(invalid pipeline) err = %v, want ErrInvalidPipeline", err)
+	}
+}
+
+func TestValidatePipeline_WarmCacheNeedsPrediction(t *testing.T) {
+	m := testManager(t, newScriptedRunner(), 1)
+	m.RegisterAction(ActionWarmCache, func(context.Context, string, map[string]*python.Result) (*python.Result, error) {
+		return &python.Result{}, nil
+	})
+
+	if _, err := m.validatePipeline(ChildPipeline(false, ActionPredictChild, ActionWarmCache)); err != nil {
+		t.Errorf("validatePipeline(predict then warm) err = %v", err)
+	}
+	for name, followUps := range map[string][]string{
+		"after training": {ActionWarmCache},
+		"after monitor":  {ActionMonitorTicker, ActionWarmCache},
+	} {
+		if _, err := m.validatePipeline(ChildPipeline(false, followUps...)); err == nil {
+			t.Errorf("validatePipeline(warm-cache %s) err = nil, want error", name)
+		}
+	}
+	if _, err := m.validatePipeline([]Step{{Name: "warm", Action: ActionWarmCache}}); err == nil {
+		t.Error("validatePipeline(warm-cache without dependency) err = nil, want error")
+	}
+}
+
+func TestValidateFollowUps(t *testing.T) {
+	if err := ValidateFollowUps([]string{ActionPredictChild, ActionWarmCache, ActionMonitorTicker}); err != nil {
+		t.Errorf("ValidateFollowUps(allowed) err = %v", err)
+	}
+	for _, action := range []string{ActionTrainParent, ActionTrainChild, "deploy"} {
+		if err := ValidateFollowUps([]string{action}); !errors.Is(err, ErrInvalidPipeline) {
+			t.Errorf("ValidateFollowUps(%s) err = %v, want ErrInvalidPipeline", action, err)
+		}
+	}
+}
+
+func TestPipeline_RetriesThenResumesFromFailedStep(t *testing.T) {
+	runner := newScriptedRunner()
+	runner.predictErr = errors.New("no data")
+	m := testManager(t, runner, 1)
+	m.stepRetryDelay = time.Millisecond
+
+	var warmed []string
+	m.RegisterAction(ActionWarmCache, func(_ context.Context, ticker string, inputs map[string]*python.Result) (*python.Result, error) {
+		warmed = append(warmed, ticker)
+		if inputs[ActionPredictChild] == nil {
+			t.Error("warm-cache ran without the prediction")
+		}
+		return &python.Result{}, nil
+	})
+
+	if _, err := m.StartTrainChild("aapl", PriorityNormal, ChildPipeline(false, ActionPredictChild, ActionWarmCache)); err != nil {
+		t.Fatalf("StartTrainChild err = %v", err)
+	}
+	failed := waitRun(t, m, "aapl")
+	if failed.Status != "failed" || !strings.Contains(failed.Error, "predict-child") {
+		t.Fatalf("run = %s (%s), want failed in predict-child", failed.Status, failed.Error)
+	}
+	want := map[string]string{ActionTrainChild: StepCompleted, ActionPredictChild: StepFailed, ActionWarmCache: StepSkipped}
+	for _, step := range failed.Pipeline {
+		if step.Status != want[step.Name] {
+			t.Errorf("step %s = %s, want %s", step.Name, step.Status, want[step.Name])
+		}
+	}
+	if n := runner.called("predict-child:AAPL"); n != 2 {
+		t.Errorf("predict-child ran %d times, want 2 (one retry)", n)
+	}
+
+	runner.mu.Lock()
+	runner.predictErr = nil
+	runner.mu.Unlock()
+
+	if _, err := m.Resume(failed.RunID); err != nil {
+		t.Fatalf("Resume err = %v", err)
+	}
+	resumed := waitRun(t, m, "aapl")
+	if resumed.Status != "completed" || resumed.ResumedFrom != failed.RunID {
+		t.Fatalf("resumed run = %s from %q, want completed from %s", resumed.Status, resumed.ResumedFrom, failed.RunID)
+	}
+	if n := runner.called("train-child:aapl"); n != 1 {
+		t.Errorf("train-child ran %d times, want 1: a resumed run keeps completed steps", n)
+	}
+	if len(warmed) != 1 || warmed[0] != "aapl" {
+		t.Errorf("warm-cache ran for %v, want [aapl]", warmed)
+	}
+	if resumed.Metrics["mse"] != 0.25 {
+		t.Errorf("resumed run metrics = %v, want the child training's", resumed.Metrics)
+	}
+
+	if _, err := m.Resume(resumed.RunID); !errors.Is(err, ErrNotResumable) {
+		t.Errorf("Resume(completed run) err = %v, want ErrNotResumable", err)
+	}
+}
+
+func TestPipeline_ChildrenShareOneParentTraining(t *testing.T) {
+	runner := newScriptedRunner()
+	runner.parentGate = make(chan struct{})
+	m := testManager(t, runner, 2)
+	m.parentPollInterval = 5 * time.Millisecond
+
+	m.StartTrainChild("aapl", PriorityNormal, ChildPipeline(true))
+	m.StartTrainChild("msft", PriorityNormal, ChildPipeline(true))
+
+	// Both pipelines reach their train-parent step; one trains the parent
+	// as its own task, the other waits for it
+	deadline := time.Now().Add(time.Second)
+	for !m.runsLocally(ParentTaskID, "") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if outcome, _ := m.StartTrainParent(PriorityNormal); outcome != OutcomeActive {
+		t.Errorf("StartTrainParent during a pipeline's parent step = %v, want OutcomeActive", outcome)
+	}
+	close(runner.parentGate)
+
+	for _, ticker := range []string{"aapl", "msft"} {
+		if run := waitRun(t, m, ticker); run.Status != "completed" {
+			t.Errorf("run of %s = %s (%s), want completed", ticker, run.Status, run.Error)
+		}
+	}
+	if n := runner.called("train-parent"); n != 1 {
+		t.Errorf("parent trained %d times, want once", n)
+	}
+	if run := m.latestRun(ParentTaskID); run == nil || run.Status != "completed" {
+		t.Errorf("parent run = %+v, want a completed run of its own", run)
+	}
+}
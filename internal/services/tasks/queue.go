@@ -0,0 +1,237 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
+)
+
+// Priority orders queued training jobs; higher priorities start first
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+// ParsePriority maps "low", "normal" or "high" to a Priority. An empty
+// string is normal priority.
+func ParsePriority(s string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low":
+		return PriorityLow, nil
+	case "", "normal":
+		return PriorityNormal, nil
+	case "high":
+		return PriorityHigh, nil
+	}
+	return PriorityNormal, fmt.Errorf("unknown priority %q (want low, normal or high)", s)
+}
+
+// String returns the priority's name as accepted by ParsePriority
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityHigh:
+		return "high"
+	}
+	return "normal"
+}
+
+// Job kinds
+const (
+	JobTrainParent = "train-parent"
+	JobTrainChild  = "train-child"
+)
+
+// Job is a training request and the pipeline of steps it runs
+type Job struct {
+	TaskID      string    `json:"task_id"`
+	RunID       string    `json:"run_id"`
+	Kind        string    `json:"kind"`
+	Ticker      string    `json:"ticker,omitempty"`
+	Priority    Priority  `json:"priority"`
+	QueuedAt    time.Time `json:"queued_at"`
+	Steps       []Step    `json:"steps,omitempty"`
+	ResumedFrom string    `json:"resumed_from,omitempty"` // run whose completed steps it keeps
+	Attempt     int       `json:"attempt,omitempty"`      // 1 for the first attempt; 0 means 1
+	Failures    []Failure `json:"failures,omitempty"`     // earlier failed attempts
+}
+
+// attempt returns which attempt at the task the job is, counting from 1
+func (j Job) attempt() int {
+	return max(j.Attempt, 1)
+}
+
+// score orders jobs by priority, then FIFO by enqueue time. Millisecond
+// timestamps stay below 1e13 for centuries, so priority bands never overlap.
+func (j Job) score() float64 {
+	return float64(PriorityHigh-j.Priority)*1e13 + float64(j.QueuedAt.UnixMilli())
+}
+
+// queue holds jobs that could not start because every worker was busy
+type queue interface {
+	Push(job Job) error
+	Pop() (*Job, error)                 // nil when empty
+	Remove(taskID string) (*Job, error) // nil when not queued
+	Position(taskID string) int         // 1-based; 0 when not queued
+	Len() int
+}
+
+// newQueue returns a Redis-backed queue, or an in-memory one without Redis
+func newQueue(redis *redisclient.Client) queue {
+	if redis == nil {
+		return &memoryQueue{}
+	}
+	return &redisQueue{redis: redis}
+}
+
+// memoryQueue is a queue local to this process
+type memoryQueue struct {
+	mu   sync.Mutex
+	jobs []Job
+}
+
+func (q *memoryQueue) Push(job Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, queued := range q.jobs {
+		if queued.TaskID == job.TaskID {
+			return nil
+		}
+	}
+	q.jobs = append(q.jobs, job)
+	sort.SliceStable(q.jobs, func(a, b int) bool {
+		return q.jobs[a].score() < q.jobs[b].score()
+	})
+	return nil
+}
+
+func (q *memoryQueue) Pop() (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.jobs) == 0 {
+		return nil, nil
+	}
+	job := q.jobs[0]
+	q.jobs = q.jobs[1:]
+	return &job, nil
+}
+
+func (q *memoryQueue) Remove(taskID string) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, job := range q.jobs {
+		if job.TaskID == taskID {
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			return &job, nil
+		}
+	}
+	return nil, nil
+}
+
+func (q *memoryQueue) Position(taskID string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, job := range q.jobs {
+		if job.TaskID == taskID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (q *memoryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
+
+// redisQueue keeps task IDs in a sorted set scored by Job.score, with each
+// job's payload under its own key. It survives restarts and is shared by
+// every instance using the same Redis.
+type redisQueue struct {
+	redis *redisclient.Client
+}
+
+func (q *redisQueue) Push(job Job) error {
+	ctx := context.Background()
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if err := q.redis.Set(ctx, redisclient.TaskQueueJobKey(job.TaskID), string(data), 0); err != nil {
+		return err
+	}
+	return q.redis.ZAdd(ctx, redisclient.TaskQueueKey, job.score(), job.TaskID)
+}
+
+func (q *redisQueue) Pop() (*Job, error) {
+	ctx := context.Background()
+
+	for {
+		taskID, ok, err := q.redis.ZPopMin(ctx, redisclient.TaskQueueKey)
+		if err != nil || !ok {
+			return nil, err
+		}
+
+		key := redisclient.TaskQueueJobKey(taskID)
+		val, err := q.redis.Get(ctx, key)
+		q.redis.Del(ctx, key)
+		if err != nil {
+			continue // payload lost; nothing to run
+		}
+
+		var job Job
+		if err := json.Unmarshal([]byte(val), &job); err != nil {
+			continue
+		}
+		return &job, nil
+	}
+}
+
+func (q *redisQueue) Remove(taskID string) (*Job, error) {
+	ctx := context.Background()
+
+	removed, err := q.redis.ZRem(ctx, redisclient.TaskQueueKey, taskID)
+	if err != nil || !removed {
+		return nil, err
+	}
+
+	key := redisclient.TaskQueueJobKey(taskID)
+	job := Job{TaskID: taskID}
+	if val, err := q.redis.Get(ctx, key); err == nil {
+		json.Unmarshal([]byte(val), &job)
+	}
+	q.redis.Del(ctx, key)
+	return &job, nil
+}
+
+func (q *redisQueue) Position(taskID string) int {
+	rank, err := q.redis.ZRank(context.Background(), redisclient.TaskQueueKey, taskID)
+	if err != nil {
+		return 0
+	}
+	return int(rank) + 1
+}
+
+func (q *redisQueue) Len() int {
+	n, err := q.redis.ZCard(context.Background(), redisclient.TaskQueueKey)
+	if err != nil {
+		return 0
+	}
+	return int(n)
+}
@@ -0,0 +1,62 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Priority
+		wantErr bool
+	}{
+		{"", PriorityNormal, false},
+		{"low", PriorityLow, false},
+		{"Normal", PriorityNormal, false},
+		{" HIGH ", PriorityHigh, false},
+		{"urgent", PriorityNormal, true},
+	}
+	for _, tc := range tests {
+		got, err := ParsePriority(tc.in)
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("ParsePriority(%q) = %v, %v; want %v, error %v", tc.in, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestMemoryQueue_PriorityThenFIFO(t *testing.T) {
+	q := &memoryQueue{}
+	now := time.Now()
+
+	q.Push(Job{TaskID: "a", Priority: PriorityNormal, QueuedAt: now})
+	q.Push(Job{TaskID: "b", Priority: PriorityLow, QueuedAt: now})
+	q.Push(Job{TaskID: "c", Priority: PriorityNormal, QueuedAt: now})
+	q.Push(Job{TaskID: "d", Priority: PriorityHigh, QueuedAt: now.Add(time.Second)})
+	q.Push(Job{TaskID: "a", Priority: PriorityHigh, QueuedAt: now}) // already queued
+
+	if got := q.Position("c"); got != 3 {
+		t.Errorf("Position(c) = %d, want 3", got)
+	}
+	if removed, _ := q.Remove("c"); removed == nil || removed.TaskID != "c" {
+		t.Errorf("Remove(c) = %+v, want job c", removed)
+	}
+
+	var order []string
+	for {
+		job, _ := q.Pop()
+		if job == nil {
+			break
+		}
+		order = append(order, job.TaskID)
+	}
+	if got, want := len(order), 3; got != want {
+		t.Fatalf("popped %v, want 3 jobs", order)
+	}
+	for i, want := range []string{"d", "a", "b"} {
+		if order[i] != want {
+			t.Errorf("pop order = %v, want [d a b]", order)
+			break
+		}
+	}
+}
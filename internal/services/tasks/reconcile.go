@@ -0,0 +1,206 @@
+package tasks
+
+import (
+	"context"
+	"log"
+	"time"
+
+	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
+)
+
+// heartbeatInterval is how often an instance refreshes its liveness key
+const heartbeatInterval = 10 * time.Second
+
+// heartbeatTTL is how long an instance is considered alive after its last
+// heartbeat
+const heartbeatTTL = 3 * heartbeatInterval
+
+// interruptedTTL keeps an interrupted status around long enough for a
+// human to notice it
+const interruptedTTL = 24 * time.Hour
+
+// Orphan actions, set with ORPHAN_ACTION
+const (
+	OrphanLeave   = "leave"
+	OrphanRequeue = "requeue"
+)
+
+// heartbeat marks this instance as alive. Tasks owned by an instance
+// without a heartbeat are orphans.
+func (m *Manager) heartbeat() {
+	if m.redis == nil {
+		return
+	}
+	if err := m.redis.Set(context.Background(), redisclient.InstanceKey(m.instanceID), time.Now().UTC().Format(time.RFC3339), heartbeatTTL); err != nil {
+		log.Printf("Failed to write heartbeat for instance %s: %v", m.instanceID, err)
+	}
+}
+
+// alive reports whether an instance has a current heartbeat. Without
+// Redis no other instance shares this one's task statuses.
+func (m *Manager) alive(instanceID string) bool {
+	if instanceID == "" {
+		return false // written before tasks had owners
+	}
+	if m.redis == nil {
+		return false
+	}
+	_, err := m.redis.Get(context.Background(), redisclient.InstanceKey(instanceID))
+	return err == nil
+}
+
+// isOrphan reports whether a running task has lost its process. A task
+// owned by this instance is orphaned unless it runs here (local); it may
+// be left over from before a restart. Another instance's task is orphaned
+// once nobody holds its lease and its owner has stopped heartbeating.
+func isOrphan(status *TaskStatus, self, lockOwner string, local bool, alive func(string) bool) bool {
+	if status == nil || status.Status != "running" {
+		return false
+	}
+	if status.Owner == self {
+		return !local
+	}
+	if lockOwner != "" {
+		return false
+	}
+	return !alive(status.Owner)
+}
+
+// reconcile marks running tasks whose process is gone as "interrupted",
+// then requeues them or leaves them for a human according to the
+// configured orphan action
+func (m *Manager) reconcile() {
+	taskIDs, err := m.statuses.List()
+	if err != nil {
+		log.Printf("Failed to list tasks for reconciliation: %v", err)
+		return
+	}
+
+	for _, taskID := range taskIDs {
+		status := m.loadStatus(taskID)
+		if status == nil {
+			continue
+		}
+		if !isOrphan(status, m.instanceID, m.lockOwner(taskID), m.runsLocally(taskID, status.RunID), m.alive) {
+			continue
+		}
+
+		// The run may have finished while we looked; only interrupt it if
+		// it is still the same run, still marked running
+		if latest := m.loadStatus(taskID); latest == nil || latest.Status != "running" || latest.RunID != status.RunID {
+			continue
+		}
+		m.interrupt(taskID, status)
+	}
+}
+
+// interrupt records an orphaned task as interrupted and applies the orphan
+// action
+func (m *Manager) interrupt(taskID string, status *TaskStatus) {
+	now := time.Now()
+	owner := status.Owner
+	if owner == "" {
+		owner = "unknown"
+	}
+	reason := "instance " + owner + " stopped while the task was running"
+
+	m.saveStatus(taskID, TaskStatus{
+		Status:        "interrupted",
+		RunID:         status.RunID,
+		Owner:         status.Owner,
+		StartTime:     status.StartTime,
+		InterruptedAt: timestamp(now),
+		Error:         reason,
+		Progress:      status.Progress,
+	}, interruptedTTL)
+
+	job := m.jobFor(taskID, status.RunID)
+	if status.RunID != "" {
+		m.updateRun(job, func(run *Run) {
+			run.Status = "interrupted"
+			run.Error = reason
+			run.FinishedAt = timestamp(now)
+			if started, err := time.Parse(time.RFC3339, run.StartedAt); err == nil {
+				run.DurationSeconds = now.Sub(started).Seconds()
+			}
+		})
+	}
+
+	// A run orphaned by this instance's previous life may still hold its
+	// lease until the TTL lapses; nothing will renew it
+	if m.redis != nil && status.Owner != "" && status.RunID != "" {
+		stale := status.Owner + "/" + status.RunID
+		if err := m.redis.ReleaseLock(context.Background(), redisclient.TaskLockKey(taskID), stale); err != nil {
+			log.Printf("Failed to clear lease on %s: %v", taskID, err)
+		}
+	}
+
+	if m.metrics != nil {
+		m.metrics.TrainingStatus.WithLabelValues(taskID).Set(0)
+	}
+	log.Printf("Training task %s interrupted: %s", taskID, reason)
+
+	if m.orphanAction != OrphanRequeue {
+		return
+	}
+	outcome, err := m.submit(Job{
+		TaskID:      job.TaskID,
+		Kind:        job.Kind,
+		Ticker:      job.Ticker,
+		Priority:    job.Priority,
+		Steps:       resumable(job.Steps),
+		ResumedFrom: job.RunID,
+	})
+	switch {
+	case err != nil:
+		log.Printf("Failed to requeue interrupted task %s: %v", taskID, err)
+	case outcome == OutcomeActive:
+		log.Printf("Interrupted training task %s not requeued: it is already running or queued", taskID)
+	default:
+		log.Printf("Interrupted training task %s requeued", taskID)
+	}
+}
+
+// jobFor rebuilds the job behind a run, pipeline included, from its run
+// record when one is kept and otherwise from the task ID
+func (m *Manager) jobFor(taskID, runID string) Job {
+	if runID != "" {
+		if run := m.GetRun(runID); run != nil {
+			priority, _ := ParsePriority(run.Priority)
+			created, _ := time.Parse(time.RFC3339, run.CreatedAt)
+			return Job{TaskID: run.TaskID, RunID: run.RunID, Kind: run.Kind, Ticker: run.Ticker, Priority: priority, QueuedAt: created, Steps: run.Pipeline}
+		}
+	}
+
+	job := Job{TaskID: taskID, RunID: runID, Kind: JobTrainChild, Ticker: taskID, Priority: PriorityNormal, QueuedAt: time.Now()}
+	if taskID == ParentTaskID {
+		job.Kind, job.Ticker = JobTrainParent, ""
+	}
+	job.Steps = defaultSteps(job.Kind)
+	return job
+}
+
+// livenessLoop heartbeats and, unless the interval is 0, periodically
+// reconciles tasks orphaned by other instances
+func (m *Manager) livenessLoop() {
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	var reconcile <-chan time.Time
+	if m.reconcileInterval > 0 {
+		ticker := time.NewTicker(m.reconcileInterval)
+		defer ticker.Stop()
+		reconcile = ticker.C
+	}
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-heartbeat.C:
+			m.heartbeat()
+		case <-reconcile:
+			m.reconcile()
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package tasks
+
+import "testing"
+
+func TestIsOrphan(t *testing.T) {
+	alive := func(owner string) bool { return owner == "pod-b" }
+
+	tests := []struct {
+		name      string
+		status    *TaskStatus
+		lockOwner string
+		local     bool
+		want      bool
+	}{
+		{"no status", nil, "", false, false},
+		{"completed", &TaskStatus{Status: "completed", Owner: "pod-dead"}, "", false, false},
+		{"own task running here", &TaskStatus{Status: "running", Owner: "pod-a"}, "pod-a/r1", true, false},
+		{"own task from before a restart", &TaskStatus{Status: "running", Owner: "pod-a"}, "pod-a/r1", false, true},
+		{"live replica", &TaskStatus{Status: "running", Owner: "pod-b"}, "", false, false},
+		{"leased by dead replica", &TaskStatus{Status: "running", Owner: "pod-dead"}, "pod-dead/r1", false, false},
+		{"dead replica", &TaskStatus{Status: "running", Owner: "pod-dead"}, "", false, true},
+		{"no owner", &TaskStatus{Status: "running"}, "", false, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isOrphan(tc.status, "pod-a", tc.lockOwner, tc.local, alive); got != tc.want {
+				t.Errorf("isOrphan = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestJobFor_RebuildsFromRunRecord(t *testing.T) {
+	m := testManager(t, newBlockingRunner(), 1)
+	m.runs.Save(&Run{RunID: "aapl-1", TaskID: "aapl", Kind: JobTrainChild, Ticker: "aapl", Priority: "high", CreatedAt: "2026-01-01T00:00:00Z"})
+
+	if job := m.jobFor("aapl", "aapl-1"); job.Kind != JobTrainChild || job.Ticker != "aapl" || job.Priority != PriorityHigh {
+		t.Errorf("jobFor(aapl-1) = %+v, want high-priority child training", job)
+	}
+	if job := m.jobFor("parent_training", "gone"); job.Kind != JobTrainParent || job.Ticker != "" {
+		t.Errorf("jobFor(parent_training) = %+v, want parent training", job)
+	}
+}
@@ -0,0 +1,512 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
+	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
+)
+
+// maxRetryBackoff caps the wait before a failed task's next attempt
+const maxRetryBackoff = time.Hour
+
+// Dead letter errors
+var (
+	ErrDeadLetterNotFound = errors.New("dead-lettered task not found")
+)
+
+// Failure records one failed attempt of a task
+type Failure struct {
+	Attempt     int    `json:"attempt"`
+	RunID       string `json:"run_id"`
+	Error       string `json:"error"`
+	Code        string `json:"code,omitempty"` // ml_cli error code, if typed
+	FailedAt    string `json:"failed_at"`
+	ExecutionID string `json:"execution_id,omitempty"`
+}
+
+// DeadLetter is a task that failed on every attempt, kept with its error
+// history until it is re-run or discarded
+type DeadLetter struct {
+	TaskID   string    `json:"task_id"`
+	Kind     string    `json:"kind"`
+	Ticker   string    `json:"ticker,omitempty"`
+	Priority string    `json:"priority"`
+	Attempts int       `json:"attempts"`
+	DeadAt   string    `json:"dead_at"`
+	Reason   string    `json:"reason"` // attempts exhausted, or a permanent error
+	Failures []Failure `json:"failures"`
+	Pipeline []Step    `json:"pipeline,omitempty"`
+}
+
+// job rebuilds the job to run a dead-lettered task again from its first
+// incomplete step
+func (d *DeadLetter) job() Job {
+	priority, _ := ParsePriority(d.Priority)
+	job := Job{TaskID: d.TaskID, Kind: d.Kind, Ticker: d.Ticker, Priority: priority}
+	if len(d.Failures) > 0 {
+		job.ResumedFrom = d.Failures[len(d.Failures)-1].RunID
+	}
+	if len(d.Pipeline) > 0 {
+		job.Steps = resumable(d.Pipeline)
+	}
+	return job
+}
+
+// permanent reports whether retrying a failure cannot help, e.g. an unknown
+// ticker
+func permanent(err error) bool {
+	return errors.Is(err, python.ErrInvalidTicker) || errors.Is(err, python.ErrResourceLimit)
+}
+
+// retryDelay returns the wait before the attempt after the given one: the
+// base backoff, doubled for each earlier attempt and capped at an hour
+func retryDelay(base time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+// retryStore holds failed jobs until their next attempt is due
+type retryStore interface {
+	Add(job Job, due time.Time) error
+	Due(now time.Time) ([]Job, error)   // removes and returns the jobs due by now
+	Remove(taskID string) (*Job, error) // nil when none is pending
+	Has(taskID string) bool
+}
+
+// newRetryStore returns a Redis-backed store, or an in-memory one without Redis
+func newRetryStore(redis *redisclient.Client) retryStore {
+	if redis == nil {
+		return &memoryRetryStore{jobs: make(map[string]pendingRetry)}
+	}
+	return &redisRetryStore{redis: redis}
+}
+
+// pendingRetry is a job waiting in a memoryRetryStore
+type pendingRetry struct {
+	job Job
+	due time.Time
+}
+
+// memoryRetryStore keeps pending retries in this process only
+type memoryRetryStore struct {
+	mu   sync.Mutex
+	jobs map[string]pendingRetry
+}
+
+func (s *memoryRetryStore) Add(job Job, due time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.TaskID] = pendingRetry{job: job, due: due}
+	return nil
+}
+
+func (s *memoryRetryStore) Due(now time.Time) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []pendingRetry
+	for taskID, pending := range s.jobs {
+		if !pending.due.After(now) {
+			due = append(due, pending)
+			delete(s.jobs, taskID)
+		}
+	}
+	sort.Slice(due, func(a, b int) bool { return due[a].due.Before(due[b].due) })
+
+	jobs := make([]Job, len(due))
+	for i, pending := range due {
+		jobs[i] = pending.job
+	}
+	return jobs, nil
+}
+
+func (s *memoryRetryStore) Remove(taskID string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, ok := s.jobs[taskID]
+	if !ok {
+		return nil, nil
+	}
+	delete(s.jobs, taskID)
+	return &pending.job, nil
+}
+
+func (s *memoryRetryStore) Has(taskID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.jobs[taskID]
+	return ok
+}
+
+// redisRetryStore keeps task IDs in a sorted set scored by when their next
+// attempt is due, with each job's payload under its own key. Whichever
+// instance removes a task ID from the set runs its next attempt.
+type redisRetryStore struct {
+	redis *redisclient.Client
+}
+
+func (s *redisRetryStore) Add(job Job, due time.Time) error {
+	ctx := context.Background()
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if err := s.redis.Set(ctx, redisclient.TaskRetryJobKey(job.TaskID), string(data), 0); err != nil {
+		return err
+	}
+	return s.redis.ZAdd(ctx, redisclient.TaskRetryKey, float64(due.UnixMilli()), job.TaskID)
+}
+
+func (s *redisRetryStore) Due(now time.Time) ([]Job, error) {
+	ctx := context.Background()
+
+	taskIDs, err := s.redis.ZRangeByScore(ctx, redisclient.TaskRetryKey, math.Inf(-1), float64(now.UnixMilli()))
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []Job
+	for _, taskID := range taskIDs {
+		job, err := s.Remove(taskID)
+		if err != nil {
+			return jobs, err
+		}
+		if job != nil {
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs, nil
+}
+
+func (s *redisRetryStore) Remove(taskID string) (*Job, error) {
+	ctx := context.Background()
+
+	removed, err := s.redis.ZRem(ctx, redisclient.TaskRetryKey, taskID)
+	if err != nil || !removed {
+		return nil, err // another instance took it
+	}
+
+	key := redisclient.TaskRetryJobKey(taskID)
+	val, err := s.redis.Get(ctx, key)
+	s.redis.Del(ctx, key)
+	if err != nil {
+		return nil, nil // payload lost; nothing to run
+	}
+
+	var job Job
+	if err := json.Unmarshal([]byte(val), &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *redisRetryStore) Has(taskID string) bool {
+	_, err := s.redis.ZRank(context.Background(), redisclient.TaskRetryKey, taskID)
+	return err == nil
+}
+
+// deadLetterStore keeps dead-lettered tasks, one per task ID
+type deadLetterStore interface {
+	Save(entry *DeadLetter) error
+	Get(taskID string) (*DeadLetter, error) // nil when unknown
+	List() ([]*DeadLetter, error)           // newest first
+	Delete(taskID string) (bool, error)
+}
+
+// newDeadLetterStore returns a Redis-backed store, or an in-memory one
+// without Redis
+func newDeadLetterStore(redis *redisclient.Client) deadLetterStore {
+	if redis == nil {
+		return &memoryDeadLetterStore{entries: make(map[string]*DeadLetter)}
+	}
+	return &redisDeadLetterStore{redis: redis}
+}
+
+// sortDeadLetters orders entries newest first
+func sortDeadLetters(entries []*DeadLetter) {
+	sort.SliceStable(entries, func(a, b int) bool { return entries[a].DeadAt > entries[b].DeadAt })
+}
+
+// memoryDeadLetterStore keeps dead letters in this process only
+type memoryDeadLetterStore struct {
+	mu      sync.Mutex
+	entries map[string]*DeadLetter
+}
+
+func (s *memoryDeadLetterStore) Save(entry *DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *entry
+	s.entries[entry.TaskID] = &copied
+	return nil
+}
+
+func (s *memoryDeadLetterStore) Get(taskID string) (*DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[taskID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *entry
+	return &copied, nil
+}
+
+func (s *memoryDeadLetterStore) List() ([]*DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]*DeadLetter, 0, len(s.entries))
+	for _, entry := range s.entries {
+		copied := *entry
+		entries = append(entries, &copied)
+	}
+	sortDeadLetters(entries)
+	return entries, nil
+}
+
+func (s *memoryDeadLetterStore) Delete(taskID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.entries[taskID]
+	delete(s.entries, taskID)
+	return ok, nil
+}
+
+// redisDeadLetterStore keeps each dead letter as JSON under its own key
+// until it is re-run or discarded
+type redisDeadLetterStore struct {
+	redis *redisclient.Client
+}
+
+func (s *redisDeadLetterStore) Save(entry *DeadLetter) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.redis.Set(context.Background(), redisclient.TaskDeadLetterKey(entry.TaskID), string(data), 0)
+}
+
+func (s *redisDeadLetterStore) Get(taskID string) (*DeadLetter, error) {
+	val, err := s.redis.Get(context.Background(), redisclient.TaskDeadLetterKey(taskID))
+	if err != nil {
+		return nil, nil
+	}
+	var entry DeadLetter
+	if err := json.Unmarshal([]byte(val), &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (s *redisDeadLetterStore) List() ([]*DeadLetter, error) {
+	keys, err := s.redis.Keys(context.Background(), redisclient.TaskDeadLetterKey("*"))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*DeadLetter, 0, len(keys))
+	for _, key := range keys {
+		entry, err := s.Get(strings.TrimPrefix(key, redisclient.TaskDeadLetterKey("")))
+		if err != nil || entry == nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sortDeadLetters(entries)
+	return entries, nil
+}
+
+func (s *redisDeadLetterStore) Delete(taskID string) (bool, error) {
+	key := redisclient.TaskDeadLetterKey(taskID)
+	if _, err := s.redis.Get(context.Background(), key); err != nil {
+		return false, nil
+	}
+	return true, s.redis.Del(context.Background(), key)
+}
+
+// failure records a failed attempt of a job
+func failure(job Job, err error, now time.Time) Failure {
+	f := Failure{
+		Attempt:     job.attempt(),
+		RunID:       job.RunID,
+		Error:       err.Error(),
+		FailedAt:    timestamp(now),
+		ExecutionID: executionID(nil, err),
+	}
+	var cliErr *python.Error
+	if errors.As(err, &cliErr) {
+		f.Code = cliErr.Code
+	}
+	return f
+}
+
+// retryOrBury schedules a failed job's next attempt with exponential
+// backoff or, once its attempts are used up or the failure is permanent,
+// moves it to the dead-letter list. It returns the status to publish.
+func (m *Manager) retryOrBury(job Job, steps []Step, err error, now time.Time) TaskStatus {
+	job.Failures = append(append([]Failure(nil), job.Failures...), failure(job, err, now))
+	status := TaskStatus{
+		Status:      "failed",
+		RunID:       job.RunID,
+		Error:       err.Error(),
+		FailedAt:    timestamp(now),
+		ExecutionID: executionID(nil, err),
+		Pipeline:    stepsView(steps),
+		Attempt:     job.attempt(),
+		MaxAttempts: m.maxAttempts,
+	}
+
+	if job.attempt() < m.maxAttempts && !permanent(err) {
+		delay := retryDelay(m.retryBackoff, job.attempt())
+		next := Job{
+			TaskID:      job.TaskID,
+			Kind:        job.Kind,
+			Ticker:      job.Ticker,
+			Priority:    job.Priority,
+			Steps:       resumable(steps),
+			ResumedFrom: job.RunID,
+			Attempt:     job.attempt() + 1,
+			Failures:    job.Failures,
+		}
+		if err := m.retries.Add(next, now.Add(delay)); err != nil {
+			log.Printf("Failed to schedule retry of %s: %v", job.TaskID, err)
+		} else {
+			status.Status = "retrying"
+			status.NextAttemptAt = timestamp(now.Add(delay))
+			if m.metrics != nil {
+				m.metrics.TrainingRetries.WithLabelValues(job.TaskID).Inc()
+			}
+			log.Printf("Training task %s failed (attempt %d of %d), retrying in %v: %v", job.TaskID, job.attempt(), m.maxAttempts, delay, err)
+			return status
+		}
+	}
+
+	reason := fmt.Sprintf("failed %d of %d attempts", job.attempt(), m.maxAttempts)
+	if permanent(err) {
+		reason = "permanent error: " + err.Error()
+	}
+	entry := &DeadLetter{
+		TaskID:   job.TaskID,
+		Kind:     job.Kind,
+		Ticker:   job.Ticker,
+		Priority: job.Priority.String(),
+		Attempts: job.attempt(),
+		DeadAt:   timestamp(now),
+		Reason:   reason,
+		Failures: job.Failures,
+		Pipeline: steps,
+	}
+	if err := m.dead.Save(entry); err != nil {
+		log.Printf("Failed to dead-letter %s: %v", job.TaskID, err)
+		return status
+	}
+	status.DeadLettered = true
+	m.updateDeadLetterMetric()
+	log.Printf("Training task %s dead-lettered: %s", job.TaskID, reason)
+	return status
+}
+
+// promoteRetries submits failed jobs whose next attempt is due
+func (m *Manager) promoteRetries() {
+	jobs, err := m.retries.Due(time.Now())
+	if err != nil {
+		log.Printf("Failed to read pending retries: %v", err)
+	}
+	for _, job := range jobs {
+		outcome, err := m.submit(job)
+		switch {
+		case err != nil:
+			log.Printf("Failed to retry training task %s: %v", job.TaskID, err)
+		case outcome == OutcomeActive:
+			log.Printf("Retry of training task %s dropped: it is already running or queued", job.TaskID)
+		default:
+			log.Printf("Training task %s: attempt %d of %d submitted", job.TaskID, job.attempt(), m.maxAttempts)
+		}
+	}
+}
+
+// cancelRetry drops a failed task's pending retry
+func (m *Manager) cancelRetry(taskID, cancelledBy string) bool {
+	job, err := m.retries.Remove(taskID)
+	if err != nil {
+		log.Printf("Failed to cancel retry of %s: %v", taskID, err)
+	}
+	if job == nil {
+		return false
+	}
+
+	status := TaskStatus{
+		Status:      "cancelled",
+		RunID:       job.ResumedFrom,
+		CancelledAt: timestamp(time.Now()),
+		CancelledBy: cancelledBy,
+		Attempt:     job.attempt() - 1,
+		MaxAttempts: m.maxAttempts,
+	}
+	m.saveStatus(taskID, status, time.Hour)
+	log.Printf("Retry of training task %s cancelled by %s", taskID, cancelledBy)
+	m.emit(EventCancelled, *job, status)
+	return true
+}
+
+// ListDeadLetters returns the tasks that failed on every attempt, newest
+// first
+func (m *Manager) ListDeadLetters() ([]*DeadLetter, error) {
+	return m.dead.List()
+}
+
+// RetryDeadLetter runs a dead-lettered task again, with a fresh set of
+// attempts, from the steps that did not complete. The task leaves the
+// dead-letter list once it is submitted.
+func (m *Manager) RetryDeadLetter(taskID string) (Outcome, error) {
+	entry, err := m.dead.Get(taskID)
+	if err != nil {
+		return OutcomeStarted, err
+	}
+	if entry == nil {
+		return OutcomeStarted, ErrDeadLetterNotFound
+	}
+
+	outcome, err := m.submit(entry.job())
+	if err != nil || outcome == OutcomeActive {
+		return outcome, err
+	}
+	if _, err := m.dead.Delete(taskID); err != nil {
+		log.Printf("Failed to remove %s from the dead-letter list: %v", taskID, err)
+	}
+	m.updateDeadLetterMetric()
+	return outcome, nil
+}
+
+// DeleteDeadLetter discards a dead-lettered task. It returns false if there
+// was none.
+func (m *Manager) DeleteDeadLetter(taskID string) (bool, error) {
+	deleted, err := m.dead.Delete(taskID)
+	m.updateDeadLetterMetric()
+	return deleted, err
+}
+
+// updateDeadLetterMetric publishes the size of the dead-letter list
+func (m *Manager) updateDeadLetterMetric() {
+	if m.metrics == nil {
+		return
+	}
+	if entries, err := m.dead.List(); err == nil {
+		m.metrics.TrainingDeadLetters.Set(float64(len(entries)))
+	}
+}
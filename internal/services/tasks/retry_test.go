@@ -0,0 +1,149 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
+)
+
+// failingRunner is a scriptedRunner whose child training fails with err
+// until it is cleared
+type failingRunner struct {
+	*scriptedRunner
+	mu  sync.Mutex
+	err error
+}
+
+func (r *failingRunner) TrainChild(ctx context.Context, ticker string) (*python.Result, error) {
+	r.mu.Lock()
+	err := r.err
+	r.mu.Unlock()
+	if err != nil {
+		r.count("train-child:" + ticker)
+		return nil, err
+	}
+	return r.scriptedRunner.TrainChild(ctx, ticker)
+}
+
+// retryManager returns a manager that retries at once, up to 3 attempts
+func retryManager(t *testing.T, runner python.RunnerInterface) *Manager {
+	t.Helper()
+	m := testManager(t, runner, 1)
+	m.maxAttempts = 3
+	m.retryBackoff = time.Millisecond
+	return m
+}
+
+// runRetries submits due retries until the task is no longer retrying
+func runRetries(t *testing.T, m *Manager, taskID string) *Run {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		run := waitRun(t, m, taskID)
+		if !m.retries.Has(taskID) {
+			return run
+		}
+		time.Sleep(2 * time.Millisecond)
+		m.promoteRetries()
+	}
+	t.Fatalf("%s still retrying", taskID)
+	return nil
+}
+
+func TestRetry_DeadLettersAfterMaxAttempts(t *testing.T) {
+	runner := &failingRunner{scriptedRunner: newScriptedRunner(), err: errors.New("mlflow hiccup")}
+	m := retryManager(t, runner)
+
+	m.StartTrainChild("aapl", PriorityHigh, nil)
+	run := runRetries(t, m, "aapl")
+
+	if n := runner.called("train-child:aapl"); n != 3 {
+		t.Errorf("child trained %d times, want 3", n)
+	}
+	if run.Attempt != 3 || run.ResumedFrom == "" {
+		t.Errorf("last run = attempt %d resumed from %q, want attempt 3 resumed from the second", run.Attempt, run.ResumedFrom)
+	}
+
+	entries, _ := m.ListDeadLetters()
+	if len(entries) != 1 || entries[0].TaskID != "aapl" || entries[0].Attempts != 3 || len(entries[0].Failures) != 3 {
+		t.Fatalf("dead letters = %+v, want aapl after 3 attempts", entries)
+	}
+	if f := entries[0].Failures[2]; f.Attempt != 3 || f.Error != "step train-child: mlflow hiccup" || f.RunID != run.RunID {
+		t.Errorf("last failure = %+v, want attempt 3 of run %s", f, run.RunID)
+	}
+	if entries[0].Priority != "high" {
+		t.Errorf("dead letter priority = %s, want high", entries[0].Priority)
+	}
+
+	// Re-running it succeeds and empties the list
+	runner.mu.Lock()
+	runner.err = nil
+	runner.mu.Unlock()
+	if outcome, err := m.RetryDeadLetter("aapl"); err != nil || outcome != OutcomeStarted {
+		t.Fatalf("RetryDeadLetter(aapl) = %v, %v; want started", outcome, err)
+	}
+	if run := waitRun(t, m, "aapl"); run.Status != "completed" || run.Attempt != 1 {
+		t.Errorf("re-run = %s attempt %d, want completed on attempt 1", run.Status, run.Attempt)
+	}
+	if entries, _ := m.ListDeadLetters(); len(entries) != 0 {
+		t.Errorf("dead letters after re-run = %d, want 0", len(entries))
+	}
+	if _, err := m.RetryDeadLetter("aapl"); !errors.Is(err, ErrDeadLetterNotFound) {
+		t.Errorf("RetryDeadLetter(gone) = %v, want ErrDeadLetterNotFound", err)
+	}
+}
+
+func TestRetry_PermanentErrorSkipsRetries(t *testing.T) {
+	runner := &failingRunner{scriptedRunner: newScriptedRunner(), err: &python.Error{Code: python.CodeInvalidTicker, Message: "no such ticker"}}
+	m := retryManager(t, runner)
+
+	m.StartTrainChild("zzzz", PriorityNormal, nil)
+	runRetries(t, m, "zzzz")
+
+	if n := runner.called("train-child:zzzz"); n != 1 {
+		t.Errorf("child trained %d times, want once", n)
+	}
+	entries, _ := m.ListDeadLetters()
+	if len(entries) != 1 || entries[0].Failures[0].Code != python.CodeInvalidTicker {
+		t.Errorf("dead letters = %+v, want zzzz with its error code", entries)
+	}
+}
+
+func TestRetry_PendingRetryBlocksDuplicatesAndCancels(t *testing.T) {
+	runner := &failingRunner{scriptedRunner: newScriptedRunner(), err: errors.New("timeout")}
+	m := testManager(t, runner, 1)
+	m.maxAttempts = 2
+	m.retryBackoff = time.Hour
+
+	m.StartTrainChild("aapl", PriorityNormal, nil)
+	waitRun(t, m, "aapl")
+	if !m.retries.Has("aapl") {
+		t.Fatal("no retry pending after the first failure")
+	}
+	if outcome, _ := m.StartTrainChild("aapl", PriorityNormal, nil); outcome != OutcomeActive {
+		t.Errorf("StartTrainChild while retrying = %v, want OutcomeActive", outcome)
+	}
+	if !m.Cancel("aapl", "tester") || m.retries.Has("aapl") {
+		t.Error("Cancel did not drop the pending retry")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for _, tc := range []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{20, time.Hour},
+	} {
+		if got := retryDelay(time.Minute, tc.attempt); got != tc.want {
+			t.Errorf("retryDelay(1m, %d) = %v, want %v", tc.attempt, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
+)
+
+// statusStore keeps the latest status of each task
+type statusStore interface {
+	Save(taskID string, status TaskStatus, ttl time.Duration) error // ttl 0 keeps it until overwritten
+	Get(taskID string) (*TaskStatus, error)                         // nil when unknown or expired
+	List() ([]string, error)                                        // IDs of tasks with a status
+}
+
+// newStatusStore returns a Redis-backed store. Without Redis, statuses are
+// kept as files under dir, so they survive a restart, or in memory when dir
+// is empty or unusable.
+func newStatusStore(redis *redisclient.Client, dir string) statusStore {
+	if redis != nil {
+		return &redisStatusStore{redis: redis}
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			log.Printf("Failed to create task state directory %s, keeping task statuses in memory: %v", dir, err)
+		} else {
+			return &fileStatusStore{dir: dir}
+		}
+	}
+	return &memoryStatusStore{statuses: make(map[string]savedStatus)}
+}
+
+// savedStatus is a status with the time it expires, zero for never
+type savedStatus struct {
+	Status    TaskStatus `json:"status"`
+	ExpiresAt time.Time  `json:"expires_at,omitempty"`
+}
+
+// newSavedStatus stamps a status with its expiry
+func newSavedStatus(status TaskStatus, ttl time.Duration, now time.Time) savedStatus {
+	saved := savedStatus{Status: status}
+	if ttl > 0 {
+		saved.ExpiresAt = now.Add(ttl)
+	}
+	return saved
+}
+
+// expired reports whether the status has outlived its TTL
+func (s savedStatus) expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
+}
+
+// memoryStatusStore keeps statuses in this process only
+type memoryStatusStore struct {
+	mu       sync.Mutex
+	statuses map[string]savedStatus
+}
+
+func (s *memoryStatusStore) Save(taskID string, status TaskStatus, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, saved := range s.statuses {
+		if saved.expired(now) {
+			delete(s.statuses, id)
+		}
+	}
+	s.statuses[taskID] = newSavedStatus(status, ttl, now)
+	return nil
+}
+
+func (s *memoryStatusStore) Get(taskID string) (*TaskStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved, ok := s.statuses[taskID]
+	if !ok || saved.expired(time.Now()) {
+		return nil, nil
+	}
+	status := saved.Status
+	return &status, nil
+}
+
+func (s *memoryStatusStore) List() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	ids := make([]string, 0, len(s.statuses))
+	for id, saved := range s.statuses {
+		if !saved.expired(now) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// fileStatusStore keeps each status as a JSON file named after its task.
+// Files are replaced atomically, so a crash never leaves half a status.
+type fileStatusStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// statusFileExt ends the name of every status file
+const statusFileExt = ".json"
+
+// path returns the file holding a task's status; task IDs are escaped
+// since tickers may contain characters such as '^'
+func (s *fileStatusStore) path(taskID string) string {
+	return filepath.Join(s.dir, url.PathEscape(taskID)+statusFileExt)
+}
+
+func (s *fileStatusStore) Save(taskID string, status TaskStatus, ttl time.Duration) error {
+	data, err := json.Marshal(newSavedStatus(status, ttl, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, ".status-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path(taskID)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+func (s *fileStatusStore) Get(taskID string) (*TaskStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved, err := s.read(s.path(taskID))
+	if err != nil || saved == nil {
+		return nil, err
+	}
+	return &saved.Status, nil
+}
+
+func (s *fileStatusStore) List() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, statusFileExt) {
+			continue
+		}
+		id, err := url.PathUnescape(strings.TrimSuffix(name, statusFileExt))
+		if err != nil {
+			continue
+		}
+		if saved, err := s.read(filepath.Join(s.dir, name)); err == nil && saved != nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// read loads a status file, deleting it once expired. It returns nil if the
+// file does not exist or has expired.
+func (s *fileStatusStore) read(path string) (*savedStatus, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var saved savedStatus
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	if saved.expired(time.Now()) {
+		os.Remove(path)
+		return nil, nil
+	}
+	return &saved, nil
+}
+
+// redisStatusStore keeps each status under task_status:<task_id>, expiring
+// with its TTL
+type redisStatusStore struct {
+	redis *redisclient.Client
+}
+
+func (s *redisStatusStore) Save(taskID string, status TaskStatus, ttl time.Duration) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	return s.redis.Set(context.Background(), redisclient.TaskKey(taskID), string(data), ttl)
+}
+
+func (s *redisStatusStore) Get(taskID string) (*TaskStatus, error) {
+	val, err := s.redis.Get(context.Background(), redisclient.TaskKey(taskID))
+	if err != nil {
+		return nil, nil
+	}
+
+	var status TaskStatus
+	if err := json.Unmarshal([]byte(val), &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (s *redisStatusStore) List() ([]string, error) {
+	keys, err := s.redis.Keys(context.Background(), redisclient.TaskKey("*"))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, strings.TrimPrefix(key, redisclient.TaskKey("")))
+	}
+	return ids, nil
+}
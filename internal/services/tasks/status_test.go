@@ -0,0 +1,115 @@
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+)
+
+func TestStatusStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) statusStore{
+		"memory": func(t *testing.T) statusStore { return newStatusStore(nil, "") },
+		"file":   func(t *testing.T) statusStore { return newStatusStore(nil, t.TempDir()) },
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+
+			if status, err := s.Get("aapl"); err != nil || status != nil {
+				t.Fatalf("Get(aapl) before save = %+v, %v; want nil", status, err)
+			}
+			if err := s.Save("aapl", TaskStatus{Status: "running", RunID: "aapl-1"}, time.Hour); err != nil {
+				t.Fatalf("Save(aapl): %v", err)
+			}
+			if err := s.Save("^gspc", TaskStatus{Status: "completed"}, 0); err != nil {
+				t.Fatalf("Save(^gspc): %v", err)
+			}
+			if err := s.Save("gone", TaskStatus{Status: "failed"}, time.Millisecond); err != nil {
+				t.Fatalf("Save(gone): %v", err)
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			if status, _ := s.Get("aapl"); status == nil || status.Status != "running" || status.RunID != "aapl-1" {
+				t.Errorf("Get(aapl) = %+v, want running aapl-1", status)
+			}
+			if status, _ := s.Get("^gspc"); status == nil || status.Status != "completed" {
+				t.Errorf("Get(^gspc) = %+v, want completed", status)
+			}
+			if status, _ := s.Get("gone"); status != nil {
+				t.Errorf("Get(gone) = %+v, want nil after its TTL", status)
+			}
+
+			ids, err := s.List()
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			sort.Strings(ids)
+			if len(ids) != 2 || ids[0] != "^gspc" || ids[1] != "aapl" {
+				t.Errorf("List = %v, want [^gspc aapl]", ids)
+			}
+		})
+	}
+}
+
+func TestFileStatusStore_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	newStatusStore(nil, dir).Save("aapl", TaskStatus{Status: "completed"}, time.Hour)
+
+	if status, _ := newStatusStore(nil, dir).Get("aapl"); status == nil || status.Status != "completed" {
+		t.Errorf("Get(aapl) from a new store = %+v, want completed", status)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "aapl.json")); err != nil {
+		t.Errorf("status file: %v", err)
+	}
+}
+
+func TestStatus_WithoutRedis(t *testing.T) {
+	runner := newBlockingRunner()
+	m := testManager(t, runner, 1)
+
+	m.StartTrainChild("aapl", PriorityNormal, nil)
+	<-runner.started
+
+	if status := m.GetStatus("aapl"); status == nil || status.Status != "running" {
+		t.Errorf("GetStatus(aapl) = %+v, want running", status)
+	}
+	if !m.IsRunning("aapl") {
+		t.Error("IsRunning(aapl) = false, want true")
+	}
+	if outcome, _ := m.StartTrainChild("aapl", PriorityNormal, nil); outcome != OutcomeActive {
+		t.Errorf("StartTrainChild(aapl) again = %v, want OutcomeActive", outcome)
+	}
+
+	m.Cancel("aapl", "tester")
+	if status := m.GetStatus("aapl"); status == nil || status.Status != "cancelled" {
+		t.Errorf("GetStatus(aapl) after cancel = %+v, want cancelled", status)
+	}
+}
+
+func TestReconcile_FileStatusesAfterRestart(t *testing.T) {
+	cfg := config.Load()
+	cfg.MaxWorkers = 1
+	cfg.InstanceID = "pod-a"
+	cfg.TaskStateDir = t.TempDir()
+
+	// Left running by this instance's previous process
+	before := NewManager(cfg, newBlockingRunner(), nil, nil)
+	before.saveStatus("aapl", TaskStatus{Status: "running", RunID: "aapl-1", Owner: "pod-a"}, time.Hour)
+
+	m := NewManager(cfg, newBlockingRunner(), nil, nil)
+	if !m.IsRunning("aapl") {
+		t.Fatal("IsRunning(aapl) after restart = false, want the saved running status")
+	}
+	m.reconcile()
+
+	if status := m.GetStatus("aapl"); status == nil || status.Status != "interrupted" {
+		t.Errorf("GetStatus(aapl) after reconcile = %+v, want interrupted", status)
+	}
+	if m.IsRunning("aapl") {
+		t.Error("IsRunning(aapl) after reconcile = true, want false")
+	}
+}
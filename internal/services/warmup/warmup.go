@@ -0,0 +1,328 @@
+// Package warmup fills the prediction cache ahead of requests, so the first
+// /predict-child for a ticker after a deploy, reset or retrain is a hit.
+package warmup
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/metrics"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/cache"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
+)
+
+// What started a warm-up run
+const (
+	TriggerStartup  = "startup"
+	TriggerTraining = "training"
+	TriggerManual   = "manual"
+)
+
+// Ticker states within a run
+const (
+	StatePending = "pending"
+	StateWarming = "warming"
+	StateWarmed  = "warmed"  // predicted and cached
+	StateCached  = "cached"  // already cached by the current model
+	StateSkipped = "skipped" // no model to predict with
+	StateFailed  = "failed"
+)
+
+// maxRuns is how many finished runs are kept for the progress endpoint
+const maxRuns = 20
+
+// Run is one warm-up pass over a set of tickers
+type Run struct {
+	ID         string           `json:"id"`
+	Trigger    string           `json:"trigger"`
+	Status     string           `json:"status"` // running, completed, cancelled
+	StartedAt  string           `json:"started_at"`
+	FinishedAt string           `json:"finished_at,omitempty"`
+	Total      int              `json:"total"`
+	Counts     map[string]int   `json:"counts"` // tickers per state
+	Tickers    []TickerProgress `json:"tickers"`
+}
+
+// TickerProgress is where one ticker of a run stands
+type TickerProgress struct {
+	Ticker     string  `json:"ticker"`
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"duration_ms,omitempty"`
+}
+
+// Warmer runs warm-ups with at most a fixed number of predictions at a time
+// across all runs. Progress is kept in memory on the instance running them.
+type Warmer struct {
+	cfg     *config.Config
+	cache   *cache.Cache
+	runner  python.RunnerInterface
+	metrics *metrics.Metrics
+
+	tickers []string      // configured targets; empty means every model on disk
+	slots   chan struct{} // one per concurrent prediction
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	runs []*Run // oldest first
+}
+
+// New creates a warmer for the configured tickers
+func New(cfg *config.Config, c *cache.Cache, runner python.RunnerInterface, m *metrics.Metrics) *Warmer {
+	ctx, cancel := context.WithCancel(context.Background())
+	tickers := make([]string, 0, len(cfg.CacheWarmTickers))
+	for _, ticker := range cfg.CacheWarmTickers {
+		tickers = append(tickers, strings.ToUpper(ticker))
+	}
+	return &Warmer{
+		cfg:     cfg,
+		cache:   c,
+		runner:  runner,
+		metrics: m,
+		tickers: tickers,
+		slots:   make(chan struct{}, max(cfg.CacheWarmConcurrency, 1)),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Start warms the configured tickers if CACHE_WARM_ON_STARTUP is set
+func (w *Warmer) Start() {
+	if w.cfg.CacheWarmOnStartup {
+		w.Warm(TriggerStartup, nil)
+	}
+}
+
+// Close cancels runs in progress and waits for them to stop
+func (w *Warmer) Close() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+// OnTaskEvent warms the ticker of every completed child training run, whose
+// new model no longer serves the predictions cached before it. Register it
+// with the task manager's OnEvent.
+func (w *Warmer) OnTaskEvent(event tasks.Event) {
+	if !w.cfg.CacheWarmOnTrain || event.Type != tasks.EventCompleted || event.Ticker == "" {
+		return
+	}
+	w.Warm(TriggerTraining, []string{event.Ticker})
+}
+
+// Warm starts a run in the background over tickers, or over the configured
+// targets when tickers is empty, and returns a snapshot of it
+func (w *Warmer) Warm(trigger string, tickers []string) *Run {
+	if len(tickers) == 0 {
+		tickers = w.Targets()
+	}
+
+	now := time.Now()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	run := &Run{
+		ID:        "warm-" + now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix),
+		Trigger:   trigger,
+		Status:    "running",
+		StartedAt: now.Format(time.RFC3339),
+		Counts:    map[string]int{},
+	}
+	seen := make(map[string]bool, len(tickers))
+	for _, ticker := range tickers {
+		ticker = strings.ToUpper(strings.TrimSpace(ticker))
+		if ticker == "" || seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+		run.Tickers = append(run.Tickers, TickerProgress{Ticker: ticker, Status: StatePending})
+	}
+	run.Total = len(run.Tickers)
+	run.Counts[StatePending] = run.Total
+
+	w.mu.Lock()
+	w.runs = append(w.runs, run)
+	snapshot := run.copy()
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.run(run)
+	return snapshot
+}
+
+// Targets returns the tickers a warm-up covers by default: the configured
+// ones, or every ticker with a child model on disk
+func (w *Warmer) Targets() []string {
+	if len(w.tickers) > 0 {
+		return append([]string(nil), w.tickers...)
+	}
+
+	entries, err := os.ReadDir(w.cfg.OutputsDir)
+	if err != nil {
+		return nil
+	}
+	var tickers []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		ticker := strings.ToUpper(entry.Name())
+		if _, err := os.Stat(filepath.Join(w.cfg.OutputsDir, entry.Name(), ticker+"_child_model.pt")); err == nil {
+			tickers = append(tickers, ticker)
+		}
+	}
+	sort.Strings(tickers)
+	return tickers
+}
+
+// Runs returns the runs still kept, newest first
+func (w *Warmer) Runs() []*Run {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	runs := make([]*Run, 0, len(w.runs))
+	for i := len(w.runs) - 1; i >= 0; i-- {
+		runs = append(runs, w.runs[i].copy())
+	}
+	return runs
+}
+
+// Get returns a run, or nil if it is unknown or no longer kept
+func (w *Warmer) Get(id string) *Run {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, run := range w.runs {
+		if run.ID == id {
+			return run.copy()
+		}
+	}
+	return nil
+}
+
+func (w *Warmer) run(run *Run) {
+	defer w.wg.Done()
+
+	var wg sync.WaitGroup
+	for i := range run.Tickers {
+		select {
+		case w.slots <- struct{}{}:
+		case <-w.ctx.Done():
+		}
+		if w.ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-w.slots }()
+			w.warm(run, i)
+		}(i)
+	}
+	wg.Wait()
+
+	w.mu.Lock()
+	run.Status = "completed"
+	if w.ctx.Err() != nil {
+		run.Status = "cancelled"
+	}
+	run.FinishedAt = time.Now().Format(time.RFC3339)
+	w.prune()
+	counts := run.Counts
+	w.mu.Unlock()
+
+	log.Printf("Cache warm-up %s (%s) %s: %d warmed, %d cached, %d skipped, %d failed of %d",
+		run.ID, run.Trigger, run.Status, counts[StateWarmed], counts[StateCached], counts[StateSkipped], counts[StateFailed], run.Total)
+}
+
+// warm fills the cache for the i-th ticker of a run
+func (w *Warmer) warm(run *Run, i int) {
+	ticker := run.Tickers[i].Ticker
+	w.update(run, i, StateWarming, nil, 0)
+	start := time.Now()
+
+	predict := func(ctx context.Context) (map[string]interface{}, error) {
+		result, err := w.runner.PredictChild(ctx, ticker)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	}
+	// No stale window: anything expired is predicted again
+	entry, err := w.cache.Fill(w.ctx, ticker, "", predict)
+
+	state := StateWarmed
+	switch {
+	case errors.Is(err, python.ErrModelMissing):
+		state, err = StateSkipped, nil
+	case err != nil:
+		state = StateFailed
+		log.Printf("Cache warm-up of %s failed: %v", ticker, err)
+	case entry.Hit:
+		state = StateCached
+	}
+	w.update(run, i, state, err, time.Since(start))
+
+	if w.metrics != nil {
+		w.metrics.CacheWarmed.WithLabelValues(state).Inc()
+	}
+}
+
+// update moves the i-th ticker of a run to a new state
+func (w *Warmer) update(run *Run, i int, state string, err error, took time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	progress := &run.Tickers[i]
+	run.Counts[progress.Status]--
+	if run.Counts[progress.Status] == 0 {
+		delete(run.Counts, progress.Status)
+	}
+	run.Counts[state]++
+	progress.Status = state
+	if err != nil {
+		progress.Error = err.Error()
+	}
+	if took > 0 {
+		progress.DurationMs = float64(took.Microseconds()) / 1000
+	}
+}
+
+// prune drops the oldest finished runs beyond maxRuns. Callers hold w.mu.
+func (w *Warmer) prune() {
+	finished := 0
+	for _, run := range w.runs {
+		if run.Status != "running" {
+			finished++
+		}
+	}
+	kept := w.runs[:0]
+	for _, run := range w.runs {
+		if run.Status != "running" && finished > maxRuns {
+			finished--
+			continue
+		}
+		kept = append(kept, run)
+	}
+	w.runs = kept
+}
+
+// copy returns a snapshot of a run. Callers hold w.mu.
+func (r *Run) copy() *Run {
+	c := *r
+	c.Counts = make(map[string]int, len(r.Counts))
+	for state, n := range r.Counts {
+		c.Counts[state] = n
+	}
+	c.Tickers = append([]TickerProgress(nil), r.Tickers...)
+	return &c
+}
@@ -0,0 +1,152 @@
+package warmup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/cache"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/python"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
+)
+
+// fakeRunner predicts every ticker with a model on disk, tracking how many
+// predictions run at once
+type fakeRunner struct {
+	python.RunnerInterface
+	outputsDir string
+
+	mu      sync.Mutex
+	running int
+	peak    int
+	calls   int
+}
+
+func (r *fakeRunner) PredictChild(_ context.Context, ticker string) (*python.Result, error) {
+	r.mu.Lock()
+	r.calls++
+	r.running++
+	r.peak = max(r.peak, r.running)
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.running--
+		r.mu.Unlock()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if ticker == "FAIL" {
+		return nil, errors.New("data unavailable")
+	}
+	if _, err := os.Stat(filepath.Join(r.outputsDir, ticker, ticker+"_child_model.pt")); err != nil {
+		return nil, python.ErrModelMissing
+	}
+	return &python.Result{Data: map[string]interface{}{"ticker": ticker}}, nil
+}
+
+func newTestWarmer(t *testing.T, models ...string) (*Warmer, *fakeRunner, *config.Config) {
+	t.Helper()
+	cfg := config.Load()
+	cfg.OutputsDir = t.TempDir()
+	cfg.CacheLocalMaxEntries, cfg.CacheLocalTTL = 100, 30
+	cfg.CacheWarmTickers = nil
+	cfg.CacheWarmConcurrency = 2
+	for _, ticker := range models {
+		os.MkdirAll(filepath.Join(cfg.OutputsDir, ticker), 0755)
+		os.WriteFile(filepath.Join(cfg.OutputsDir, ticker, ticker+"_child_model.pt"), []byte(ticker), 0644)
+	}
+
+	runner := &fakeRunner{outputsDir: cfg.OutputsDir}
+	w := New(cfg, cache.NewCache(cfg, nil, nil, nil, time.Hour), runner, nil)
+	t.Cleanup(w.Close)
+	return w, runner, cfg
+}
+
+// wait returns a run once it has finished
+func wait(t *testing.T, w *Warmer, id string) *Run {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		run := w.Get(id)
+		if run != nil && run.Status != "running" {
+			return run
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("warm-up %s did not finish: %+v", id, run)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTargets(t *testing.T) {
+	w, _, cfg := newTestWarmer(t, "MSFT", "AAPL")
+	os.MkdirAll(filepath.Join(cfg.OutputsDir, "TSLA", "drift"), 0755) // no model
+
+	if got := w.Targets(); !reflect.DeepEqual(got, []string{"AAPL", "MSFT"}) {
+		t.Errorf("Targets() = %v, want the tickers with a child model", got)
+	}
+
+	w.tickers = []string{"NVDA"}
+	if got := w.Targets(); !reflect.DeepEqual(got, []string{"NVDA"}) {
+		t.Errorf("Targets() = %v, want the configured tickers", got)
+	}
+}
+
+func TestWarm(t *testing.T) {
+	w, runner, _ := newTestWarmer(t, "AAPL", "MSFT", "NVDA", "AMZN")
+
+	run := w.Warm(TriggerManual, []string{"aapl", "MSFT", "NVDA", "AMZN", "GOOG", "FAIL", "AAPL"})
+	if run.Total != 6 || run.Counts[StatePending] != 6 {
+		t.Fatalf("Warm() = %+v, want 6 distinct pending tickers", run)
+	}
+
+	run = wait(t, w, run.ID)
+	want := map[string]int{StateWarmed: 4, StateSkipped: 1, StateFailed: 1}
+	if run.Status != "completed" || !reflect.DeepEqual(run.Counts, want) {
+		t.Errorf("run = %s %v, want completed with %v", run.Status, run.Counts, want)
+	}
+	if runner.peak > 2 {
+		t.Errorf("%d predictions ran at once, want at most 2", runner.peak)
+	}
+	for _, ticker := range run.Tickers {
+		if ticker.Ticker == "FAIL" && ticker.Error == "" {
+			t.Error("failed ticker has no error")
+		}
+	}
+
+	// A second pass finds the predictions cached
+	calls := runner.calls
+	run = wait(t, w, w.Warm(TriggerManual, []string{"AAPL"}).ID)
+	if run.Counts[StateCached] != 1 || runner.calls != calls {
+		t.Errorf("second warm-up = %v after %d more predictions, want a cache hit", run.Counts, runner.calls-calls)
+	}
+
+	if runs := w.Runs(); len(runs) != 2 || runs[0].ID != run.ID {
+		t.Errorf("Runs() = %d runs, want 2, newest first", len(runs))
+	}
+}
+
+func TestOnTaskEvent(t *testing.T) {
+	w, _, _ := newTestWarmer(t, "AAPL")
+
+	w.OnTaskEvent(tasks.Event{Type: tasks.EventFailed, Ticker: "AAPL"})
+	w.OnTaskEvent(tasks.Event{Type: tasks.EventCompleted}) // parent training
+	if runs := w.Runs(); len(runs) != 0 {
+		t.Fatalf("Runs() = %d, want none for failed or parent training", len(runs))
+	}
+
+	w.OnTaskEvent(tasks.Event{Type: tasks.EventCompleted, Ticker: "AAPL"})
+	runs := w.Runs()
+	if len(runs) != 1 || runs[0].Trigger != TriggerTraining {
+		t.Fatalf("Runs() = %+v, want one training warm-up", runs)
+	}
+	if run := wait(t, w, runs[0].ID); run.Counts[StateWarmed] != 1 {
+		t.Errorf("training warm-up = %v, want AAPL warmed", run.Counts)
+	}
+}
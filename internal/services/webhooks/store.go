@@ -0,0 +1,179 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
+)
+
+// deliveriesMax bounds the delivery log of each subscription
+const deliveriesMax = 100
+
+// store keeps subscriptions and their delivery logs
+type store interface {
+	Save(sub *Subscription) error
+	Get(id string) (*Subscription, error) // nil when unknown
+	List() ([]*Subscription, error)       // ordered by ID
+	Delete(id string) (bool, error)       // also drops the delivery log
+
+	AddDelivery(id string, delivery Delivery) error
+	Deliveries(id string, limit int) ([]Delivery, error) // newest first
+}
+
+// newStore returns a Redis-backed store, or an in-memory one without Redis
+func newStore(redis *redisclient.Client) store {
+	if redis == nil {
+		return &memoryStore{subs: make(map[string]*Subscription), deliveries: make(map[string][]Delivery)}
+	}
+	return &redisStore{redis: redis}
+}
+
+// memoryStore keeps subscriptions in this process only
+type memoryStore struct {
+	mu         sync.Mutex
+	subs       map[string]*Subscription
+	deliveries map[string][]Delivery // newest first
+}
+
+func (s *memoryStore) Save(sub *Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs[sub.ID] = sub.copy()
+	return nil
+}
+
+func (s *memoryStore) Get(id string) (*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	return sub.copy(), nil
+}
+
+func (s *memoryStore) List() ([]*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs := make([]*Subscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, sub.copy())
+	}
+	sort.Slice(subs, func(a, b int) bool { return subs[a].ID < subs[b].ID })
+	return subs, nil
+}
+
+func (s *memoryStore) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.subs[id]
+	delete(s.subs, id)
+	delete(s.deliveries, id)
+	return ok, nil
+}
+
+func (s *memoryStore) AddDelivery(id string, delivery Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := append([]Delivery{delivery}, s.deliveries[id]...)
+	if len(entries) > deliveriesMax {
+		entries = entries[:deliveriesMax]
+	}
+	s.deliveries[id] = entries
+	return nil
+}
+
+func (s *memoryStore) Deliveries(id string, limit int) ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := s.deliveries[id]
+	if limit > 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+	return append([]Delivery{}, entries...), nil
+}
+
+// redisStore keeps each subscription as JSON under its own key and its
+// delivery log in a capped list, shared by every instance using the same
+// Redis
+type redisStore struct {
+	redis *redisclient.Client
+}
+
+func (s *redisStore) Save(sub *Subscription) error {
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+	return s.redis.Set(context.Background(), redisclient.WebhookKey(sub.ID), string(data), 0)
+}
+
+func (s *redisStore) Get(id string) (*Subscription, error) {
+	val, err := s.redis.Get(context.Background(), redisclient.WebhookKey(id))
+	if err != nil {
+		return nil, nil
+	}
+	var sub Subscription
+	if err := json.Unmarshal([]byte(val), &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (s *redisStore) List() ([]*Subscription, error) {
+	keys, err := s.redis.Keys(context.Background(), redisclient.WebhookKey("*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+
+	subs := make([]*Subscription, 0, len(keys))
+	for _, key := range keys {
+		sub, err := s.Get(strings.TrimPrefix(key, redisclient.WebhookKey("")))
+		if err != nil || sub == nil {
+			continue
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func (s *redisStore) Delete(id string) (bool, error) {
+	key := redisclient.WebhookKey(id)
+	if _, err := s.redis.Get(context.Background(), key); err != nil {
+		return false, nil
+	}
+	return true, s.redis.Del(context.Background(), key, redisclient.WebhookDeliveriesKey(id))
+}
+
+func (s *redisStore) AddDelivery(id string, delivery Delivery) error {
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	return s.redis.LPush(context.Background(), redisclient.WebhookDeliveriesKey(id), string(data), deliveriesMax)
+}
+
+func (s *redisStore) Deliveries(id string, limit int) ([]Delivery, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = int64(limit) - 1
+	}
+	vals, err := s.redis.LRange(context.Background(), redisclient.WebhookDeliveriesKey(id), 0, stop)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]Delivery, 0, len(vals))
+	for _, val := range vals {
+		var delivery Delivery
+		if err := json.Unmarshal([]byte(val), &delivery); err == nil {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
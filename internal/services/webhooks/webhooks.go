@@ -0,0 +1,526 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/metrics"
+	redisclient "github.com/shrithkshahapure/stock-agent-ops/internal/services/redis"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
+)
+
+// Event types a subscription can receive
+const (
+	EventTaskStarted   = tasks.EventStarted
+	EventTaskCompleted = tasks.EventCompleted
+	EventTaskFailed    = tasks.EventFailed
+	EventTaskCancelled = tasks.EventCancelled
+	EventAutoHeal      = "training.auto_heal" // /predict-child found no model and started training
+	EventPing          = "ping"               // sent by Test; always delivered
+)
+
+// eventTypes lists the events a subscription may filter on
+var eventTypes = []string{EventTaskStarted, EventTaskCompleted, EventTaskFailed, EventTaskCancelled, EventAutoHeal}
+
+// Request headers of a delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// maxRetryBackoff caps the wait between delivery attempts
+const maxRetryBackoff = 10 * time.Minute
+
+// maxConcurrentDeliveries bounds the requests in flight at once
+const maxConcurrentDeliveries = 8
+
+// Subscription errors
+var (
+	ErrNotFound = errors.New("webhook not found")
+	ErrInvalid  = errors.New("invalid webhook")
+)
+
+// Subscription sends the events it selects to a URL. The secret signs
+// every payload; it is only shown when the subscription is created or the
+// secret is changed.
+type Subscription struct {
+	ID          string   `json:"id"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events,omitempty"`  // empty for every event
+	Tickers     []string `json:"tickers,omitempty"` // empty for every ticker and the parent
+	Description string   `json:"description,omitempty"`
+	Secret      string   `json:"secret,omitempty"`
+	Enabled     bool     `json:"enabled"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+// copy copies a subscription and its filters
+func (s *Subscription) copy() *Subscription {
+	copied := *s
+	copied.Events = append([]string(nil), s.Events...)
+	copied.Tickers = append([]string(nil), s.Tickers...)
+	return &copied
+}
+
+// redacted returns a copy without the secret
+func (s *Subscription) redacted() *Subscription {
+	copied := s.copy()
+	copied.Secret = ""
+	return copied
+}
+
+// wants reports whether the subscription receives an event
+func (s *Subscription) wants(event Event) bool {
+	if !s.Enabled {
+		return false
+	}
+	if event.Type == EventPing {
+		return true
+	}
+	return matches(s.Events, event.Type) && matches(s.Tickers, event.Ticker)
+}
+
+// matches reports whether value is in filter; an empty filter matches all
+func matches(filter []string, value string) bool {
+	return len(filter) == 0 || contains(filter, value)
+}
+
+// Spec is what a client sets on a subscription
+type Spec struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Tickers     []string `json:"tickers"`
+	Description string   `json:"description"`
+	Secret      string   `json:"secret"`  // generated on create when empty; kept on update when empty
+	Enabled     *bool    `json:"enabled"` // default true
+}
+
+// Event is the JSON payload of a delivery
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"event"`
+	CreatedAt string      `json:"created_at"`
+	TaskID    string      `json:"task_id,omitempty"`
+	RunID     string      `json:"run_id,omitempty"`
+	Ticker    string      `json:"ticker,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// Delivery is one attempt at delivering an event, as kept in a
+// subscription's delivery log
+type Delivery struct {
+	DeliveryID    string  `json:"delivery_id"` // shared by the attempts at one event
+	EventID       string  `json:"event_id"`
+	Event         string  `json:"event"`
+	Attempt       int     `json:"attempt"`
+	Status        string  `json:"status"` // delivered, retrying, failed
+	StatusCode    int     `json:"status_code,omitempty"`
+	Error         string  `json:"error,omitempty"`
+	DurationMs    float64 `json:"duration_ms"`
+	At            string  `json:"at"`
+	NextAttemptAt string  `json:"next_attempt_at,omitempty"`
+}
+
+// PublisherInterface publishes events to the subscriptions that want them
+type PublisherInterface interface {
+	Publish(event Event)
+}
+
+// Dispatcher delivers events to webhook subscriptions. Subscriptions are
+// kept in Redis, or in memory without it; pending retries live in this
+// process and are dropped when it stops.
+type Dispatcher struct {
+	store       store
+	client      *http.Client
+	metrics     *metrics.Metrics
+	maxAttempts int
+	backoff     time.Duration
+	sem         chan struct{}
+	now         func() time.Time
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// New creates a dispatcher
+func New(cfg *config.Config, redis *redisclient.Client, m *metrics.Metrics) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		store:       newStore(redis),
+		client:      &http.Client{Timeout: time.Duration(cfg.WebhookTimeout) * time.Second},
+		metrics:     m,
+		maxAttempts: max(cfg.WebhookMaxAttempts, 1),
+		backoff:     time.Duration(cfg.WebhookRetryBackoff) * time.Second,
+		sem:         make(chan struct{}, maxConcurrentDeliveries),
+		now:         time.Now,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Close abandons pending retries and waits for requests in flight
+func (d *Dispatcher) Close() {
+	d.closeOnce.Do(func() {
+		d.cancel()
+		d.wg.Wait()
+	})
+}
+
+// List returns every subscription, without secrets, ordered by ID
+func (d *Dispatcher) List() ([]*Subscription, error) {
+	subs, err := d.store.List()
+	if err != nil {
+		return nil, err
+	}
+	for i, sub := range subs {
+		subs[i] = sub.redacted()
+	}
+	return subs, nil
+}
+
+// Get returns a subscription without its secret, or nil if it is unknown
+func (d *Dispatcher) Get(id string) (*Subscription, error) {
+	sub, err := d.store.Get(id)
+	if err != nil || sub == nil {
+		return nil, err
+	}
+	return sub.redacted(), nil
+}
+
+// Create adds a subscription and returns it with its secret
+func (d *Dispatcher) Create(spec Spec) (*Subscription, error) {
+	now := d.now()
+	sub := &Subscription{ID: "wh-" + randomHex(6), CreatedAt: timestamp(now)}
+	if spec.Secret == "" {
+		spec.Secret = randomHex(32)
+	}
+	if err := apply(sub, spec, now); err != nil {
+		return nil, err
+	}
+	if err := d.store.Save(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Update replaces a subscription's definition. The secret is only
+// returned when the update changes it.
+func (d *Dispatcher) Update(id string, spec Spec) (*Subscription, error) {
+	sub, err := d.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	if err := apply(sub, spec, d.now()); err != nil {
+		return nil, err
+	}
+	if err := d.store.Save(sub); err != nil {
+		return nil, err
+	}
+	if spec.Secret == "" {
+		return sub.redacted(), nil
+	}
+	return sub, nil
+}
+
+// Delete removes a subscription and its delivery log. It returns false if
+// there was none.
+func (d *Dispatcher) Delete(id string) (bool, error) {
+	return d.store.Delete(id)
+}
+
+// Deliveries returns a subscription's latest delivery attempts, newest
+// first
+func (d *Dispatcher) Deliveries(id string, limit int) ([]Delivery, error) {
+	sub, err := d.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return d.store.Deliveries(id, limit)
+}
+
+// Test sends a ping event to a subscription, enabled or not, and returns
+// the delivery ID to look for in its log
+func (d *Dispatcher) Test(id string) (string, error) {
+	sub, err := d.store.Get(id)
+	if err != nil {
+		return "", err
+	}
+	if sub == nil {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	event := d.newEvent(Event{Type: EventPing, Data: map[string]string{"webhook_id": id}})
+	deliveryID := "dl-" + randomHex(8)
+	d.goDeliver(sub.ID, deliveryID, event)
+	return deliveryID, nil
+}
+
+// Publish sends an event to every enabled subscription that wants it.
+// It returns at once; deliveries and their retries run in the background.
+func (d *Dispatcher) Publish(event Event) {
+	if d.ctx.Err() != nil {
+		return
+	}
+	event = d.newEvent(event)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		subs, err := d.store.List()
+		if err != nil {
+			log.Printf("Failed to list webhooks for %s: %v", event.Type, err)
+			return
+		}
+		for _, sub := range subs {
+			if sub.wants(event) {
+				d.goDeliver(sub.ID, "dl-"+randomHex(8), event)
+			}
+		}
+	}()
+}
+
+// PublishTask publishes a task lifecycle event; register it with the task
+// manager's OnEvent
+func (d *Dispatcher) PublishTask(event tasks.Event) {
+	d.Publish(Event{
+		Type:      event.Type,
+		CreatedAt: timestamp(event.Time),
+		TaskID:    event.TaskID,
+		RunID:     event.RunID,
+		Ticker:    event.Ticker,
+		Data:      event.Status,
+	})
+}
+
+// newEvent fills in an event's ID and creation time
+func (d *Dispatcher) newEvent(event Event) Event {
+	if event.ID == "" {
+		event.ID = "evt-" + randomHex(8)
+	}
+	if event.CreatedAt == "" {
+		event.CreatedAt = timestamp(d.now())
+	}
+	return event
+}
+
+// goDeliver delivers an event to one subscription in the background
+func (d *Dispatcher) goDeliver(subID, deliveryID string, event Event) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.deliver(subID, deliveryID, event)
+	}()
+}
+
+// deliver posts an event to a subscription until it is accepted or the
+// attempts run out, waiting longer after each failure. Every attempt is
+// logged. It gives up early if the subscription is deleted or disabled.
+func (d *Dispatcher) deliver(subID, deliveryID string, event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode webhook event %s: %v", event.ID, err)
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		sub, err := d.store.Get(subID)
+		if err != nil || sub == nil || (!sub.Enabled && event.Type != EventPing) {
+			return
+		}
+
+		start := d.now()
+		code, err := d.post(sub, deliveryID, event.Type, body)
+		delivery := Delivery{
+			DeliveryID: deliveryID,
+			EventID:    event.ID,
+			Event:      event.Type,
+			Attempt:    attempt,
+			StatusCode: code,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+			At:         timestamp(start),
+		}
+
+		var wait time.Duration
+		switch {
+		case err == nil:
+			delivery.Status = "delivered"
+		case attempt < d.maxAttempts && retryable(code):
+			wait = retryDelay(d.backoff, attempt)
+			delivery.Status = "retrying"
+			delivery.Error = err.Error()
+			delivery.NextAttemptAt = timestamp(d.now().Add(wait))
+		default:
+			delivery.Status = "failed"
+			delivery.Error = err.Error()
+		}
+
+		if err := d.store.AddDelivery(subID, delivery); err != nil {
+			log.Printf("Failed to log delivery to webhook %s: %v", subID, err)
+		}
+		if d.metrics != nil {
+			d.metrics.WebhookDeliveries.WithLabelValues(event.Type, delivery.Status).Inc()
+		}
+		if delivery.Status != "retrying" {
+			if delivery.Status == "failed" {
+				log.Printf("Webhook %s: %s delivery %s failed after %d attempts: %s", subID, event.Type, deliveryID, attempt, delivery.Error)
+			}
+			return
+		}
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// post sends one signed request and returns the response status code.
+// Any status other than 2xx is an error.
+func (d *Dispatcher) post(sub *Subscription, deliveryID, eventType string, body []byte) (int, error) {
+	select {
+	case d.sem <- struct{}{}:
+		defer func() { <-d.sem }()
+	case <-d.ctx.Done():
+		return 0, d.ctx.Err()
+	}
+
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	ts := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "stock-agent-ops-webhooks")
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, ts, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header of a payload: "sha256=" and the hex
+// HMAC-SHA256, keyed by the secret, of the timestamp, a dot and the body
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryable reports whether a failed attempt may succeed later: the
+// endpoint was unreachable, overloaded or failing, rather than rejecting
+// the request
+func retryable(code int) bool {
+	return code == 0 || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+}
+
+// retryDelay returns the wait after a failed attempt: base, doubled for
+// each further attempt, up to maxRetryBackoff
+func retryDelay(base time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+// apply validates spec and sets it on sub
+func apply(sub *Subscription, spec Spec, now time.Time) error {
+	target, err := url.Parse(strings.TrimSpace(spec.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url %q must be an absolute http or https URL", ErrInvalid, spec.URL)
+	}
+
+	var events []string
+	for _, event := range spec.Events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if !contains(eventTypes, event) {
+			return fmt.Errorf("%w: unknown event %q (want one of %s)", ErrInvalid, event, strings.Join(eventTypes, ", "))
+		}
+		if !contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	var tickers []string
+	for _, ticker := range spec.Tickers {
+		if ticker = strings.ToUpper(strings.TrimSpace(ticker)); ticker != "" && !contains(tickers, ticker) {
+			tickers = append(tickers, ticker)
+		}
+	}
+
+	sub.URL = target.String()
+	sub.Events = events
+	sub.Tickers = tickers
+	sub.Description = strings.TrimSpace(spec.Description)
+	if spec.Secret != "" {
+		sub.Secret = spec.Secret
+	}
+	sub.Enabled = spec.Enabled == nil || *spec.Enabled
+	sub.UpdatedAt = timestamp(now)
+	return nil
+}
+
+// contains reports whether value is in list
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// randomHex returns n random bytes, hex-encoded
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// timestamp formats times in subscriptions and deliveries
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,199 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shrithkshahapure/stock-agent-ops/internal/config"
+	"github.com/shrithkshahapure/stock-agent-ops/internal/services/tasks"
+)
+
+// receiver is an endpoint that answers with the queued status codes, then
+// 200, and records the requests it got
+type receiver struct {
+	mu       sync.Mutex
+	codes    []int
+	requests []*http.Request
+	bodies   [][]byte
+	got      chan struct{}
+}
+
+func newReceiver(t *testing.T, codes ...int) (*receiver, *httptest.Server) {
+	t.Helper()
+	r := &receiver{codes: codes, got: make(chan struct{}, 100)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		code := http.StatusOK
+		if len(r.codes) > 0 {
+			code, r.codes = r.codes[0], r.codes[1:]
+		}
+		r.mu.Unlock()
+		w.WriteHeader(code)
+		r.got <- struct{}{}
+	}))
+	t.Cleanup(server.Close)
+	return r, server
+}
+
+// wait waits for n more requests
+func (r *receiver) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.got:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("endpoint got %d of %d requests", i, n)
+		}
+	}
+}
+
+func testDispatcher(t *testing.T) *Dispatcher {
+	t.Helper()
+	d := New(config.Load(), nil, nil)
+	d.backoff = time.Millisecond
+	t.Cleanup(d.Close)
+	return d
+}
+
+// deliveries waits until a subscription's log has n attempts and returns it
+func deliveries(t *testing.T, d *Dispatcher, id string, n int) []Delivery {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		log, err := d.Deliveries(id, 0)
+		if err != nil {
+			t.Fatalf("Deliveries(%s): %v", id, err)
+		}
+		if len(log) >= n || time.Now().After(deadline) {
+			return log
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCreate_Validates(t *testing.T) {
+	d := testDispatcher(t)
+
+	for name, spec := range map[string]Spec{
+		"no url":        {},
+		"relative url":  {URL: "/hook"},
+		"ftp url":       {URL: "ftp://example.com/hook"},
+		"unknown event": {URL: "https://example.com/hook", Events: []string{"task.exploded"}},
+	} {
+		if _, err := d.Create(spec); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Create(%s) err = %v, want ErrInvalid", name, err)
+		}
+	}
+
+	sub, err := d.Create(Spec{URL: "https://example.com/hook", Events: []string{"TASK.COMPLETED", "task.completed"}, Tickers: []string{"aapl"}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if sub.Secret == "" || !sub.Enabled || len(sub.Events) != 1 || sub.Tickers[0] != "AAPL" {
+		t.Errorf("Create = %+v, want an enabled subscription with a secret, one event and ticker AAPL", sub)
+	}
+	if got, _ := d.Get(sub.ID); got == nil || got.Secret != "" {
+		t.Errorf("Get(%s) = %+v, want the subscription without its secret", sub.ID, got)
+	}
+}
+
+func TestDeliver_SignsAndRetries(t *testing.T) {
+	d := testDispatcher(t)
+	r, server := newReceiver(t, http.StatusServiceUnavailable)
+	sub, _ := d.Create(Spec{URL: server.URL, Secret: "s3cret"})
+
+	d.PublishTask(tasks.Event{Type: tasks.EventCompleted, TaskID: "aapl", RunID: "aapl-1", Ticker: "AAPL", Time: time.Now(),
+		Status: tasks.TaskStatus{Status: "completed", RunID: "aapl-1"}})
+	r.wait(t, 2)
+
+	log := deliveries(t, d, sub.ID, 2)
+	if len(log) != 2 || log[0].Status != "delivered" || log[0].Attempt != 2 || log[1].Status != "retrying" || log[1].StatusCode != 503 {
+		t.Fatalf("delivery log = %+v, want retrying (503) then delivered", log)
+	}
+	if log[0].DeliveryID != log[1].DeliveryID {
+		t.Errorf("attempts have delivery IDs %s and %s, want the same", log[1].DeliveryID, log[0].DeliveryID)
+	}
+
+	r.mu.Lock()
+	req, body := r.requests[1], r.bodies[1]
+	r.mu.Unlock()
+	ts, _ := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if got, want := req.Header.Get(HeaderSignature), Sign("s3cret", ts, body); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	if req.Header.Get(HeaderEvent) != EventTaskCompleted {
+		t.Errorf("%s = %q, want %s", HeaderEvent, req.Header.Get(HeaderEvent), EventTaskCompleted)
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if event.Type != EventTaskCompleted || event.TaskID != "aapl" || event.RunID != "aapl-1" || event.ID == "" {
+		t.Errorf("payload = %+v, want task.completed for aapl-1", event)
+	}
+}
+
+func TestDeliver_GivesUp(t *testing.T) {
+	d := testDispatcher(t)
+	d.maxAttempts = 2
+	r, server := newReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusBadRequest)
+	failing, _ := d.Create(Spec{URL: server.URL})
+
+	d.Publish(Event{Type: EventAutoHeal, Ticker: "NVDA"})
+	r.wait(t, 2)
+	if log := deliveries(t, d, failing.ID, 2); len(log) != 2 || log[0].Status != "failed" || log[0].Attempt != 2 {
+		t.Errorf("delivery log = %+v, want failed after 2 attempts", log)
+	}
+
+	// A rejected request is not retried
+	d.Publish(Event{Type: EventAutoHeal, Ticker: "NVDA"})
+	r.wait(t, 1)
+	if log := deliveries(t, d, failing.ID, 3); len(log) != 3 || log[0].Status != "failed" || log[0].Attempt != 1 || log[0].StatusCode != 400 {
+		t.Errorf("delivery log = %+v, want failed at once on 400", log)
+	}
+}
+
+func TestPublish_Filters(t *testing.T) {
+	d := testDispatcher(t)
+	r, server := newReceiver(t)
+	disabled := false
+	d.Create(Spec{URL: server.URL, Events: []string{EventTaskCompleted}, Tickers: []string{"AAPL"}})
+	d.Create(Spec{URL: server.URL, Enabled: &disabled})
+
+	d.Publish(Event{Type: EventTaskStarted, Ticker: "AAPL"})
+	d.Publish(Event{Type: EventTaskCompleted, Ticker: "MSFT"})
+	d.Publish(Event{Type: EventTaskCompleted, Ticker: "AAPL", TaskID: "aapl"})
+	r.wait(t, 1)
+
+	select {
+	case <-r.got:
+		t.Error("endpoint got an event its subscriptions do not select")
+	case <-time.After(100 * time.Millisecond):
+	}
+	var event Event
+	r.mu.Lock()
+	json.Unmarshal(r.bodies[0], &event)
+	r.mu.Unlock()
+	if event.Type != EventTaskCompleted || event.Ticker != "AAPL" {
+		t.Errorf("delivered %+v, want task.completed for AAPL", event)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{1: 5 * time.Second, 2: 10 * time.Second, 3: 20 * time.Second, 20: maxRetryBackoff} {
+		if got := retryDelay(5*time.Second, attempt); got != want {
+			t.Errorf("retryDelay(5s, %d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
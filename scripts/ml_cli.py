@@ -13,12 +13,38 @@ Usage:
     python scripts/ml_cli.py analyze --ticker AAPL [--thread-id ID]
     python scripts/ml_cli.py monitor-parent
     python scripts/ml_cli.py monitor-ticker --ticker AAPL
+    python scripts/ml_cli.py serve
+
+Long-running commands may print progress events before the final result,
+one JSON object per line, marked with "event": "progress":
+
+    {"event": "progress", "phase": "train", "epoch": 3, "total_epochs": 20, "loss": 0.01}
+
+The serve command keeps the interpreter (and its torch/pandas imports) warm
+and answers line-delimited JSON requests on stdin:
+
+    {"id": 1, "argv": ["predict-child", "--ticker", "AAPL"]}
+
+A request may carry "env", the whole environment the command runs with,
+when its execution policy restricts which variables it sees.
+
+with one JSON response line per request on stdout, preceded by any progress
+events for that request:
+
+    {"id": 1, "event": "progress", "phase": "ingest"}
+    {"id": 1, "data": {...}}
+    {"id": 1, "error": "...", "code": "model_missing", "details": "..."}
+
+Errors always carry a machine-readable code: model_missing, data_unavailable,
+invalid_ticker, timeout or internal.
 """
 
 import sys
 import os
+import re
 import json
 import argparse
+import contextlib
 import traceback
 
 # Add the project root to the path
@@ -31,15 +57,74 @@ def output_json(data):
     print(json.dumps(data, default=str))
 
 
-def output_error(message, details=None):
+def emit_progress(event):
+    """Progress sink for CLI mode: one JSON line per event, flushed at once."""
+    print(json.dumps(event, default=str), flush=True)
+
+
+# Machine-readable error codes; the Go runner maps these to typed errors
+MODEL_MISSING = "model_missing"
+DATA_UNAVAILABLE = "data_unavailable"
+INVALID_TICKER = "invalid_ticker"
+TIMEOUT = "timeout"
+INTERNAL = "internal"
+
+# Index tickers (^GSPC), share classes (BRK.B, BF-B) and futures (ES=F)
+TICKER_PATTERN = re.compile(r"^\^?[A-Z0-9][A-Z0-9.\-=]{0,14}$")
+
+# Set by serve(): errors are raised back to the request loop instead of
+# terminating the worker process.
+_serving = False
+
+
+class CommandError(Exception):
+    """A command failure reported to the caller of a serve request."""
+
+    def __init__(self, message, details=None, code=INTERNAL):
+        super().__init__(message)
+        self.message = message
+        self.details = details
+        self.code = code
+
+
+def error_code(exc):
+    """Classify an exception (and the exceptions it wraps) into an error code."""
+    from src.exception import ModelMissingError, DataUnavailableError, InvalidTickerError
+
+    seen = set()
+    while exc is not None and id(exc) not in seen:
+        seen.add(id(exc))
+        # Only the model checks raise ModelMissingError; any other missing
+        # file (data, config) is not a model to train
+        if isinstance(exc, ModelMissingError):
+            return MODEL_MISSING
+        if isinstance(exc, DataUnavailableError):
+            return DATA_UNAVAILABLE
+        if isinstance(exc, InvalidTickerError):
+            return INVALID_TICKER
+        if isinstance(exc, TimeoutError):
+            return TIMEOUT
+        exc = exc.__cause__ or exc.__context__
+    return INTERNAL
+
+
+def output_error(message, details=None, code=INTERNAL):
     """Print error JSON to stdout and exit with code 1."""
-    error_data = {"error": message}
+    if _serving:
+        raise CommandError(message, details, code)
+    error_data = {"error": message, "code": code}
     if details:
         error_data["details"] = details
     output_json(error_data)
     sys.exit(1)
 
 
+def validate_ticker(ticker):
+    """Reject malformed ticker symbols before any pipeline work starts."""
+    if not TICKER_PATTERN.match((ticker or "").strip().upper()):
+        output_error(f"Invalid ticker: {ticker!r}", code=INVALID_TICKER)
+
+
 def train_parent():
     """Train the parent model (S&P 500)."""
     try:
@@ -47,7 +132,7 @@ def train_parent():
         result = _train_parent()
         return {"status": "completed", "result": result}
     except Exception as e:
-        output_error(f"Training failed: {e}", traceback.format_exc())
+        output_error(f"Training failed: {e}", traceback.format_exc(), error_code(e))
 
 
 def train_child(ticker):
@@ -57,7 +142,7 @@ def train_child(ticker):
         result = _train_child(ticker.upper())
         return {"status": "completed", "ticker": ticker.upper(), "result": result}
     except Exception as e:
-        output_error(f"Training failed for {ticker}: {e}", traceback.format_exc())
+        output_error(f"Training failed for {ticker}: {e}", traceback.format_exc(), error_code(e))
 
 
 def predict_parent():
@@ -66,10 +151,11 @@ def predict_parent():
         from src.pipelines.inference_pipeline import predict_parent as _predict_parent
         result = _predict_parent()
         return result
-    except FileNotFoundError as e:
-        output_error(f"Missing model: {e}")
     except Exception as e:
-        output_error(f"Prediction failed: {e}", traceback.format_exc())
+        code = error_code(e)
+        if code == MODEL_MISSING:
+            output_error(f"Missing model: {e}", code=code)
+        output_error(f"Prediction failed: {e}", traceback.format_exc(), code)
 
 
 def predict_child(ticker):
@@ -78,14 +164,11 @@ def predict_child(ticker):
         from src.pipelines.inference_pipeline import predict_child as _predict_child
         result = _predict_child(ticker.upper())
         return result
-    except FileNotFoundError as e:
-        output_error(f"Missing model for {ticker}: {e}")
     except Exception as e:
-        # Check if it's a model missing error
-        error_str = str(e).lower()
-        if "missing" in error_str or "not found" in error_str:
-            output_error(f"Missing model for {ticker}: {e}")
-        output_error(f"Prediction failed for {ticker}: {e}", traceback.format_exc())
+        code = error_code(e)
+        if code == MODEL_MISSING:
+            output_error(f"Missing model for {ticker}: {e}", code=code)
+        output_error(f"Prediction failed for {ticker}: {e}", traceback.format_exc(), code)
 
 
 def analyze(ticker, thread_id=None):
@@ -95,7 +178,7 @@ def analyze(ticker, thread_id=None):
         result = analyze_stock(ticker.upper(), thread_id=thread_id)
         return result
     except Exception as e:
-        output_error(f"Analysis failed for {ticker}: {e}", traceback.format_exc())
+        output_error(f"Analysis failed for {ticker}: {e}", traceback.format_exc(), error_code(e))
 
 
 def monitor_parent():
@@ -129,7 +212,7 @@ def monitor_parent():
             "agent_eval": eval_res
         }
     except Exception as e:
-        output_error(f"Monitoring failed: {e}", traceback.format_exc())
+        output_error(f"Monitoring failed: {e}", traceback.format_exc(), error_code(e))
 
 
 def monitor_ticker(ticker):
@@ -167,10 +250,15 @@ def monitor_ticker(ticker):
             "agent_eval": eval_res
         }
     except Exception as e:
-        output_error(f"Monitoring failed for {ticker}: {e}", traceback.format_exc())
+        output_error(f"Monitoring failed for {ticker}: {e}", traceback.format_exc(), error_code(e))
 
 
-def main():
+def ping():
+    """Health check used by the Go worker pool."""
+    return {"status": "ok", "pid": os.getpid()}
+
+
+def build_parser():
     parser = argparse.ArgumentParser(
         description="ML CLI Wrapper for Stock Agent Ops",
         formatter_class=argparse.RawDescriptionHelpFormatter,
@@ -204,33 +292,129 @@ def main():
     mt = subparsers.add_parser("monitor-ticker", help="Run monitoring on a specific ticker")
     mt.add_argument("--ticker", required=True, help="Stock ticker symbol (e.g., AAPL)")
 
+    # ping
+    subparsers.add_parser("ping", help="Health check (used by serve workers)")
+
+    # serve
+    subparsers.add_parser("serve", help="Answer JSON requests on stdin until EOF")
+
+    return parser
+
+
+def dispatch(args):
+    """Run the command selected by parsed arguments and return its result."""
+    if getattr(args, "ticker", None) is not None:
+        validate_ticker(args.ticker)
+
+    if args.command == "train-parent":
+        return train_parent()
+    elif args.command == "train-child":
+        return train_child(args.ticker)
+    elif args.command == "predict-parent":
+        return predict_parent()
+    elif args.command == "predict-child":
+        return predict_child(args.ticker)
+    elif args.command == "analyze":
+        return analyze(args.ticker, args.thread_id)
+    elif args.command == "monitor-parent":
+        return monitor_parent()
+    elif args.command == "monitor-ticker":
+        return monitor_ticker(args.ticker)
+    elif args.command == "ping":
+        return ping()
+    output_error(f"Unknown command: {args.command}")
+
+
+@contextlib.contextmanager
+def request_environment(env):
+    """Run a serve request with env as os.environ, if given, then restore it."""
+    if env is None:
+        yield
+        return
+    saved = dict(os.environ)
+    os.environ.clear()
+    os.environ.update(env)
+    try:
+        yield
+    finally:
+        os.environ.clear()
+        os.environ.update(saved)
+
+
+def serve():
+    """Serve line-delimited JSON requests on stdin until EOF.
+
+    The protocol stream is the original stdout; anything the pipelines print
+    is redirected to stderr so it cannot corrupt a response line.
+    """
+    global _serving
+    _serving = True
+
+    from src import progress
+
+    protocol = sys.stdout
+    sys.stdout = sys.stderr
+    parser = build_parser()
+
+    def write(message):
+        protocol.write(json.dumps(message, default=str) + "\n")
+        protocol.flush()
+
+    for line in sys.stdin:
+        line = line.strip()
+        if not line:
+            continue
+
+        response = {}
+        try:
+            request = json.loads(line)
+            response["id"] = request.get("id")
+            argv = request.get("argv") or []
+            if argv and argv[0] == "serve":
+                raise CommandError("serve cannot be nested")
+            try:
+                args = parser.parse_args(argv)
+            except SystemExit:
+                raise CommandError(f"Invalid arguments: {' '.join(argv)}")
+            request_id = response["id"]
+            progress.set_sink(lambda event: write({"id": request_id, **event}))
+            with request_environment(request.get("env")):
+                response["data"] = dispatch(args)
+        except CommandError as e:
+            response["error"] = e.message
+            response["code"] = e.code
+            if e.details:
+                response["details"] = e.details
+        except Exception as e:
+            response["error"] = f"Unexpected error: {e}"
+            response["code"] = error_code(e)
+            response["details"] = traceback.format_exc()
+        finally:
+            progress.set_sink(None)
+
+        write(response)
+
+
+def main():
+    parser = build_parser()
     args = parser.parse_args()
 
+    if args.command == "serve":
+        serve()
+        sys.exit(0)
+
     try:
-        if args.command == "train-parent":
-            result = train_parent()
-        elif args.command == "train-child":
-            result = train_child(args.ticker)
-        elif args.command == "predict-parent":
-            result = predict_parent()
-        elif args.command == "predict-child":
-            result = predict_child(args.ticker)
-        elif args.command == "analyze":
-            result = analyze(args.ticker, args.thread_id)
-        elif args.command == "monitor-parent":
-            result = monitor_parent()
-        elif args.command == "monitor-ticker":
-            result = monitor_ticker(args.ticker)
-        else:
-            output_error(f"Unknown command: {args.command}")
+        from src import progress
+        progress.set_sink(emit_progress)
 
+        result = dispatch(args)
         output_json(result)
         sys.exit(0)
 
     except SystemExit:
         raise
     except Exception as e:
-        output_error(f"Unexpected error: {e}", traceback.format_exc())
+        output_error(f"Unexpected error: {e}", traceback.format_exc(), error_code(e))
 
 
 if __name__ == "__main__":
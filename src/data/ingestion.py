@@ -5,7 +5,7 @@ import yfinance as yf
 from typing import Optional
 from dotenv import load_dotenv
 from src.config import Config
-from src.exception import PipelineError
+from src.exception import DataUnavailableError, InvalidTickerError, PipelineError
 import subprocess
 from datetime import datetime
 
@@ -31,7 +31,8 @@ def fetch_ohlcv(ticker: str, start: str = Config().start_date, end: Optional[str
     try:
         df = yf.download(ticker, start=start, end=end, interval="1d", auto_adjust=True, progress=False)
         if df.empty:
-            raise PipelineError(f"No data downloaded for {ticker}")
+            # yfinance reports an unknown symbol as an empty download
+            raise InvalidTickerError(f"Unknown ticker {ticker}: no data downloaded")
         
         # Flatten MultiIndex columns if present (yfinance > 0.2.40 behavior)
         if isinstance(df.columns, pd.MultiIndex):
@@ -45,11 +46,11 @@ def fetch_ohlcv(ticker: str, start: str = Config().start_date, end: Optional[str
         
         # Validate data
         if len(df) < config.context_len + config.pred_len:
-            raise PipelineError(f"Insufficient data for {ticker}: {len(df)} rows, need at least {config.context_len + config.pred_len}")
+            raise DataUnavailableError(f"Insufficient data for {ticker}: {len(df)} rows, need at least {config.context_len + config.pred_len}")
         if df[config.features].isnull().any().any():
-            raise PipelineError(f"NaN values found in features for {ticker}")
+            raise DataUnavailableError(f"NaN values found in features for {ticker}")
         if not df[config.features].apply(lambda x: pd.api.types.is_numeric_dtype(x)).all():
-            raise PipelineError(f"Non-numeric values found in features for {ticker}")
+            raise DataUnavailableError(f"Non-numeric values found in features for {ticker}")
         print(f"Fetched {len(df)} rows for {ticker}", file=sys.stderr)
 
         # =========================================================
@@ -139,5 +140,8 @@ def fetch_ohlcv(ticker: str, start: str = Config().start_date, end: Optional[str
             print(f"⚠️ Feast ingestion failed: {e}", file=sys.stderr)
 
         return df
-    except Exception as e:
-        raise PipelineError(f"Failed to fetch data for {ticker}: {e}")
\ No newline at end of file
+    except PipelineError:
+        raise
+    except OSError as e:
+        # Network and I/O failures may pass; anything else is a bug
+        raise DataUnavailableError(f"Failed to fetch data for {ticker}: {e}") from e
\ No newline at end of file
@@ -1,3 +1,18 @@
 class PipelineError(Exception):
     """Custom exception for pipeline errors."""
-    pass
\ No newline at end of file
+    pass
+
+
+class ModelMissingError(PipelineError):
+    """A trained model or scaler required by the pipeline is not on disk."""
+    pass
+
+
+class DataUnavailableError(PipelineError):
+    """Market data could not be fetched or is unusable."""
+    pass
+
+
+class InvalidTickerError(PipelineError):
+    """The requested ticker symbol is malformed or unknown."""
+    pass
@@ -3,6 +3,7 @@ import torch.nn as nn
 from torch.utils.data import DataLoader
 import mlflow
 from src.config import Config
+from src import progress
 from logger.logger import get_logger
 
 logger = get_logger()
@@ -40,6 +41,7 @@ def fit_model(model: nn.Module, train_loader: DataLoader, val_loader: DataLoader
                 val_loss += criterion(pred, Y).item()
         avg_val_loss = val_loss / len(val_loader)
         logger.info(f"Epoch {ep}/{epochs} - Val Loss: {avg_val_loss:.5f}")
+        progress.report("train", epoch=ep, total_epochs=epochs, loss=avg_train_loss, val_mse=avg_val_loss)
 
         # Log metrics to MLflow
         try:
@@ -13,7 +13,7 @@ from src.config import Config
 from src.data.ingestion import fetch_ohlcv
 from src.inference import predict_one_step_and_week
 from logger.logger import get_logger
-from src.exception import PipelineError
+from src.exception import PipelineError, ModelMissingError
 
 logger = get_logger()
 cfg = Config()
@@ -56,9 +56,9 @@ def _load_local_model(ticker: str, model_type: str):
             scaler_path = os.path.join(base_dir, f"{ticker}_child_scaler.pkl")
 
         if not os.path.exists(pt_path):
-            raise FileNotFoundError(f"Missing PyTorch file for {ticker}: {pt_path}")
+            raise ModelMissingError(f"Missing PyTorch file for {ticker}: {pt_path}")
         if not os.path.exists(scaler_path):
-            raise FileNotFoundError(f"Missing scaler file for {ticker}: {scaler_path}")
+            raise ModelMissingError(f"Missing scaler file for {ticker}: {scaler_path}")
 
         # Load PyTorch Model
         model = LSTMModel().to(cfg.device)
@@ -69,8 +69,10 @@ def _load_local_model(ticker: str, model_type: str):
         logger.info(f"✅ Loaded {model_type} model for {ticker}")
         return model, scaler
 
+    except ModelMissingError:
+        raise
     except Exception as e:
-        raise PipelineError(f"Local model load failed for {ticker}: {e}")
+        raise PipelineError(f"Local model load failed for {ticker}: {e}") from e
 
 
 
@@ -126,7 +128,7 @@ def predict_parent():
 
     except Exception as e:
         logger.error(f"Parent prediction failed: {e}")
-        raise PipelineError(f"Parent prediction failed: {e}")
+        raise PipelineError(f"Parent prediction failed: {e}") from e
 
 
 def predict_child(ticker: str):
@@ -178,4 +180,4 @@ def predict_child(ticker: str):
 
     except Exception as e:
         logger.error(f"Child prediction failed: {e}")
-        raise PipelineError(f"Child prediction failed: {e}")
+        raise PipelineError(f"Child prediction failed: {e}") from e
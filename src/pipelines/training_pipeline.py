@@ -12,7 +12,8 @@ from src.model.definition import LSTMModel
 from src.model.training import fit_model
 from src.model.evaluation import evaluate_model_temp
 from logger.logger import get_logger
-from src.exception import PipelineError
+from src.exception import PipelineError, ModelMissingError
+from src import progress
 
 # MLflow is optional - training works without it
 try:
@@ -102,6 +103,7 @@ def train_parent() -> Dict:
             logger.warning(f"MLflow run start skipped: {e}")
 
     try:
+        progress.report("ingest", ticker=ticker)
         df = fetch_ohlcv(ticker, start)
         scaler = StandardScaler().fit(df[cfg.features])
         dataset = StockDataset(df, scaler)
@@ -122,6 +124,7 @@ def train_parent() -> Dict:
 
         # ✅ Evaluate
         model.eval()
+        progress.report("evaluate", ticker=ticker)
         metrics = evaluate_model_temp(model, df, scaler, out_dir, ticker)
 
         # Log to MLflow (optional)
@@ -134,7 +137,7 @@ def train_parent() -> Dict:
         return {"ticker": ticker, "run_id": run_id, "metrics": metrics}
     except Exception as e:
         logger.error(f"Parent training failed: {e}")
-        raise PipelineError(f"Parent training failed: {e}")
+        raise PipelineError(f"Parent training failed: {e}") from e
     finally:
         if MLFLOW_AVAILABLE:
             try:
@@ -176,6 +179,7 @@ def train_child(ticker: str) -> Dict:
             logger.warning(f"MLflow run start skipped: {e}")
 
     try:
+        progress.report("ingest", ticker=ticker)
         df = fetch_ohlcv(ticker, start)
         scaler = StandardScaler().fit(df[cfg.features])
         dataset = StockDataset(df, scaler)
@@ -189,7 +193,7 @@ def train_child(ticker: str) -> Dict:
 
         parent_model_path = os.path.join(parent_dir, f"{cfg.parent_ticker}_parent_model.pt")
         if not os.path.exists(parent_model_path):
-            raise FileNotFoundError(f"Parent model missing at {parent_model_path}")
+            raise ModelMissingError(f"Parent model missing at {parent_model_path}")
 
         parent_model = LSTMModel().to(cfg.device)
         parent_model.load_state_dict(torch.load(parent_model_path, map_location=cfg.device))
@@ -223,6 +227,7 @@ def train_child(ticker: str) -> Dict:
 
         # ✅ Evaluate
         model.eval()
+        progress.report("evaluate", ticker=ticker)
         metrics = evaluate_model_temp(model, df, scaler, child_dir, ticker)
 
         # Log to MLflow (optional)
@@ -235,7 +240,7 @@ def train_child(ticker: str) -> Dict:
         return {"ticker": ticker, "run_id": run_id, "metrics": metrics}
     except Exception as e:
         logger.error(f"Child training failed: {e}")
-        raise PipelineError(f"Child training failed: {e}")
+        raise PipelineError(f"Child training failed: {e}") from e
     finally:
         if MLFLOW_AVAILABLE:
             try:
@@ -0,0 +1,28 @@
+"""
+Progress events for long-running CLI commands.
+
+Pipelines call report() at phase boundaries and after each epoch. The CLI
+installs a sink that forwards events to the Go server as JSON lines; without
+a sink (notebooks, unit tests) reporting is a no-op.
+"""
+
+_sink = None
+
+
+def set_sink(sink):
+    """Install a callable that receives each progress event dict (or None)."""
+    global _sink
+    _sink = sink
+
+
+def report(phase, **fields):
+    """Report that a pipeline has reached `phase` (ingest, train, evaluate)."""
+    if _sink is None:
+        return
+    event = {"event": "progress", "phase": phase}
+    event.update({k: v for k, v in fields.items() if v is not None})
+    try:
+        _sink(event)
+    except Exception:
+        # Progress is best effort and must never fail a training run
+        pass
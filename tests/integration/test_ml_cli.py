@@ -161,3 +161,52 @@ class TestCLIErrorHandling:
             assert exc_info.value.code == 1
             data = json.loads(buf.getvalue())
             assert "error" in data
+
+    def test_model_missing_carries_error_code(self):
+        """Missing-model failures are tagged with code=model_missing."""
+        from src.exception import ModelMissingError, PipelineError
+
+        wrapped = PipelineError("Child prediction failed")
+        wrapped.__cause__ = ModelMissingError("Missing PyTorch file for AAPL")
+
+        with patch(
+            "src.pipelines.inference_pipeline.predict_child",
+            side_effect=wrapped,
+        ):
+            import importlib
+            import scripts.ml_cli as cli_mod
+            importlib.reload(cli_mod)
+
+            import io, contextlib
+            buf = io.StringIO()
+            with pytest.raises(SystemExit):
+                with contextlib.redirect_stdout(buf):
+                    cli_mod.predict_child("AAPL")
+
+            data = json.loads(buf.getvalue())
+            assert data["code"] == "model_missing"
+
+    def test_other_missing_file_is_not_model_missing(self):
+        """A missing data or config file is an internal error, not model_missing."""
+        with patch(
+            "src.pipelines.inference_pipeline.predict_child",
+            side_effect=FileNotFoundError("data/aapl.csv"),
+        ):
+            import importlib
+            import scripts.ml_cli as cli_mod
+            importlib.reload(cli_mod)
+
+            import io, contextlib
+            buf = io.StringIO()
+            with pytest.raises(SystemExit):
+                with contextlib.redirect_stdout(buf):
+                    cli_mod.predict_child("AAPL")
+
+            data = json.loads(buf.getvalue())
+            assert data["code"] == "internal"
+
+    def test_invalid_ticker_exits_with_code(self):
+        proc = run_cli("predict-child", "--ticker", "NOT A TICKER")
+        assert proc.returncode == 1
+        data = json.loads(proc.stdout)
+        assert data["code"] == "invalid_ticker"
@@ -0,0 +1,34 @@
+"""Unit tests for src.data.ingestion.fetch_ohlcv error classification."""
+from unittest.mock import patch
+
+import pandas as pd
+import pytest
+
+from src.exception import DataUnavailableError, InvalidTickerError
+
+
+def test_empty_download_is_invalid_ticker():
+    """yfinance returns an empty frame for a symbol that does not exist."""
+    from src.data.ingestion import fetch_ohlcv
+
+    with patch("src.data.ingestion.yf.download", return_value=pd.DataFrame()):
+        with pytest.raises(InvalidTickerError):
+            fetch_ohlcv("ZZZZZ")
+
+
+def test_network_error_is_data_unavailable():
+    """Connection failures may pass, so they are reported as retryable."""
+    from src.data.ingestion import fetch_ohlcv
+
+    with patch("src.data.ingestion.yf.download", side_effect=ConnectionError("reset by peer")):
+        with pytest.raises(DataUnavailableError):
+            fetch_ohlcv("AAPL")
+
+
+def test_programming_error_is_not_wrapped():
+    """Bugs surface as themselves rather than as unavailable data."""
+    from src.data.ingestion import fetch_ohlcv
+
+    with patch("src.data.ingestion.yf.download", side_effect=KeyError("Close")):
+        with pytest.raises(KeyError):
+            fetch_ohlcv("AAPL")